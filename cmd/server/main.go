@@ -7,11 +7,14 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"small-rpg-adhd-monolith/internal/bot"
 	"small-rpg-adhd-monolith/internal/core"
+	"small-rpg-adhd-monolith/internal/notify"
 	"small-rpg-adhd-monolith/internal/store"
 	"small-rpg-adhd-monolith/internal/web"
 )
@@ -71,6 +74,9 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Start scheduler (scheduled grants, purge of deleted groups)
+	go service.StartScheduler(ctx)
+
 	// Initialize and start Telegram bot if token is provided
 	var telegramBot *bot.Bot
 	if botToken != "" {
@@ -84,17 +90,26 @@ func main() {
 			log.Println("Telegram bot initialized successfully")
 			// Start bot in a goroutine
 			go telegramBot.Start()
-
-			// Start notification worker
-			log.Println("Starting notification worker...")
-			go service.StartNotificationWorker(ctx, telegramBot)
-			log.Println("Notification worker started successfully")
+			service.RegisterNotificationChannel(service.NewTelegramChannel(telegramBot))
 		}
 	} else {
 		log.Println("TELEGRAM_BOT_TOKEN not set, Telegram bot will not be started")
 		log.Println("Set TELEGRAM_BOT_TOKEN environment variable to enable Telegram integration")
 	}
 
+	// Register the other notification channels
+	registerNotificationChannels(service)
+	log.Printf("Notification channels: %s", strings.Join(service.NotificationChannels(), ", "))
+
+	// Start notification worker; wishlist goals are only announced on Telegram
+	log.Println("Starting notification worker...")
+	var wishlistNotifier core.BotNotifier
+	if telegramBot != nil {
+		wishlistNotifier = telegramBot
+	}
+	go service.StartNotificationWorker(ctx, wishlistNotifier)
+	log.Println("Notification worker started successfully")
+
 	// Print startup information
 	fmt.Println("\n✓ All components initialized successfully!")
 	fmt.Println("✓ Database connection established")
@@ -161,3 +176,51 @@ func main() {
 	log.Println("✓ Database connection closed")
 	log.Println("Shutdown complete")
 }
+
+// registerNotificationChannels sets up email, webhook and Web Push delivery
+// from the environment. Webhooks need no configuration; the others are
+// skipped with a log line when their settings are missing or invalid.
+func registerNotificationChannels(service *core.Service) {
+	publicURL := os.Getenv("PUBLIC_URL")
+	if publicURL == "" {
+		publicURL = "http://localhost:8080"
+	}
+
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+		email, err := notify.NewEmailChannel(notify.EmailConfig{
+			Host:      host,
+			Port:      port,
+			Username:  os.Getenv("SMTP_USERNAME"),
+			Password:  os.Getenv("SMTP_PASSWORD"),
+			From:      os.Getenv("SMTP_FROM"),
+			PublicURL: publicURL,
+		})
+		if err != nil {
+			log.Printf("Warning: Email notifications disabled: %v", err)
+		} else {
+			service.RegisterNotificationChannel(email)
+		}
+	}
+
+	allowPrivate := os.Getenv("WEBHOOK_ALLOW_PRIVATE") == "true"
+	service.RegisterNotificationChannel(notify.NewWebhookChannel(os.Getenv("WEBHOOK_SECRET"), publicURL, allowPrivate))
+
+	if privateKey := os.Getenv("VAPID_PRIVATE_KEY"); privateKey != "" {
+		push, err := notify.NewWebPushChannel(notify.WebPushConfig{
+			PrivateKey:   privateKey,
+			Subject:      os.Getenv("VAPID_SUBJECT"),
+			AllowPrivate: allowPrivate,
+			Expired: func(endpoint string) {
+				if err := service.UnsubscribeWebPush(endpoint); err != nil {
+					log.Printf("Failed to remove expired push subscription: %v", err)
+				}
+			},
+		})
+		if err != nil {
+			log.Printf("Warning: Web Push notifications disabled: %v", err)
+		} else {
+			service.RegisterNotificationChannel(push)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// Command vapidkeys prints a new VAPID key pair for Web Push notifications
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"small-rpg-adhd-monolith/internal/notify"
+)
+
+func main() {
+	publicKey, privateKey, err := notify.GenerateVAPIDKeys()
+	if err != nil {
+		log.Fatalf("Failed to generate VAPID keys: %v", err)
+	}
+	fmt.Println("# Public key (browsers get it from the server, no need to set it)")
+	fmt.Println("# " + publicKey)
+	fmt.Println("VAPID_PRIVATE_KEY=" + privateKey)
+}
@@ -4,11 +4,14 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"small-rpg-adhd-monolith/internal/core"
@@ -25,6 +28,10 @@ type Bot struct {
 	sessionSecret string
 	token         string
 	translator    *i18n.Translator
+
+	// replies tracks users who were asked to type a time, by Telegram ID
+	replies   map[int64]pendingReply
+	repliesMu sync.Mutex
 }
 
 func normalizeLang(lang string) string {
@@ -105,6 +112,7 @@ func NewBot(token string, service *core.Service, sessionSecret string, translato
 		sessionSecret: sessionSecret,
 		token:         token,
 		translator:    translator,
+		replies:       make(map[int64]pendingReply),
 	}
 
 	bot.setupHandlers()
@@ -130,8 +138,17 @@ func (b *Bot) setupHandlers() {
 	b.bot.Handle("/help", b.handleHelp)
 	b.bot.Handle("/balance", b.handleBalance)
 	b.bot.Handle("/tasks", b.handleTasks)
+	b.bot.Handle("/wishlist", b.handleWishlist)
 	b.bot.Handle("/notifications", b.handleNotifications)
 	b.bot.Handle("/switch_language", b.handleSwitchLanguage)
+	b.bot.Handle("/timezone", b.handleTimezone)
+	b.bot.Handle("/when", b.handleWhen)
+	b.bot.Handle("/quiet", b.handleQuiet)
+	b.bot.Handle("/digest", b.handleDigest)
+	b.bot.Handle("/report", b.handleReport)
+	b.bot.Handle("/leaderboard", b.handleLeaderboard)
+	b.bot.Handle(tele.OnQuery, b.handleInlineSearch)
+	b.bot.Handle(tele.OnText, b.handleTimeReply)
 
 	// Callback handlers
 	b.bot.Handle(tele.OnCallback, b.handleCallback)
@@ -220,6 +237,33 @@ func (b *Bot) handleSwitchLanguage(c tele.Context) error {
 	return c.Send(b.t(lang, "bot.switch.prompt"), markup)
 }
 
+// handleTimezone shows or sets the user's timezone: /timezone Europe/Berlin
+func (b *Bot) handleTimezone(c tele.Context) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Send(b.t(b.lang(c, nil), "bot.web.unknown"))
+	}
+	lang := b.lang(c, user)
+
+	name := strings.TrimSpace(c.Message().Payload)
+	if name == "" {
+		current := user.Timezone
+		if current == "" {
+			current = b.t(lang, "bot.timezone.unset")
+		}
+		now := time.Now().In(user.Location()).Format("15:04")
+		return c.Send(fmt.Sprintf(b.t(lang, "bot.timezone.current"), current, now))
+	}
+
+	timezone, err := b.service.SetUserTimezone(user.ID, name)
+	if err != nil {
+		return c.Send(fmt.Sprintf(b.t(lang, "bot.timezone.invalid"), name))
+	}
+	user.Timezone = timezone
+	now := time.Now().In(user.Location()).Format("15:04")
+	return c.Send(fmt.Sprintf(b.t(lang, "bot.timezone.set"), timezone, now))
+}
+
 // handleBalance handles the /balance command
 func (b *Bot) handleBalance(c tele.Context) error {
 	telegramID := c.Sender().ID
@@ -257,6 +301,20 @@ func (b *Bot) handleBalance(c tele.Context) error {
 		totalCoins += balance
 		msg.WriteString(fmt.Sprintf(b.t(lang, "bot.balance.line"), group.Name, balance))
 		msg.WriteString("\n")
+
+		// Group-defined currencies are listed under the cheese line
+		balances, err := b.service.GetBalances(user.ID, group.ID)
+		if err != nil {
+			log.Printf("Error getting currency balances for group %d: %v", group.ID, err)
+			continue
+		}
+		for _, cb := range balances {
+			if cb.Currency.ID == core.DefaultCurrencyID || cb.Amount == 0 {
+				continue
+			}
+			msg.WriteString(fmt.Sprintf(b.t(lang, "bot.balance.currency"), cb.Currency.Emoji, cb.Amount, cb.Currency.Label(lang)))
+			msg.WriteString("\n")
+		}
 	}
 
 	msg.WriteString(fmt.Sprintf("\n"+b.t(lang, "bot.balance.total"), totalCoins))
@@ -270,6 +328,83 @@ func (b *Bot) handleBalance(c tele.Context) error {
 	return c.Send(msg.String())
 }
 
+// handleWishlist handles the /wishlist command
+func (b *Bot) handleWishlist(c tele.Context) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Send(b.t("en", "bot.web.unknown"))
+	}
+	lang := b.lang(c, user)
+
+	progress, err := b.service.GetWishlistProgress(user.ID)
+	if err != nil {
+		log.Printf("Error getting wishlist: %v", err)
+		return c.Send(b.t(lang, "bot.error.groups"))
+	}
+
+	if len(progress) == 0 {
+		return c.Send(b.t(lang, "bot.wishlist.empty"))
+	}
+
+	var msg strings.Builder
+	msg.WriteString(b.t(lang, "bot.wishlist.header"))
+
+	var rows [][]tele.InlineButton
+	for _, p := range progress {
+		msg.WriteString("\n\n")
+		msg.WriteString(fmt.Sprintf(b.t(lang, "bot.wishlist.line"),
+			p.ShopItem.Title, p.Group.Name, progressBar(p.Percent), p.Percent, p.Available, p.ShopItem.Cost))
+		if p.Entry.Reserved > 0 {
+			msg.WriteString("\n" + fmt.Sprintf(b.t(lang, "bot.wishlist.reserved"), p.Entry.Reserved))
+		}
+		if p.Affordable {
+			msg.WriteString("\n" + b.t(lang, "bot.wishlist.ready"))
+			rows = append(rows, []tele.InlineButton{{
+				Text: fmt.Sprintf("🛒 %s", p.ShopItem.Title),
+				Data: fmt.Sprintf("wishbuy:%d", p.ShopItem.ID),
+			}})
+		}
+	}
+
+	return c.Send(msg.String(), &tele.ReplyMarkup{InlineKeyboard: rows})
+}
+
+// progressBar renders a ten-step text progress bar
+func progressBar(percent int) string {
+	filled := percent / 10
+	if filled > 10 {
+		filled = 10
+	}
+	if filled < 0 {
+		filled = 0
+	}
+	return strings.Repeat("▓", filled) + strings.Repeat("░", 10-filled)
+}
+
+// handleWishlistPurchase buys a wishlist item straight from the bot
+func (b *Bot) handleWishlistPurchase(c tele.Context, itemID int64) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ User not found"})
+	}
+	lang := b.lang(c, user)
+
+	item, err := b.service.GetShopItemByID(itemID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Item not found"})
+	}
+
+	if _, err := b.service.BuyItem(user.ID, itemID); err != nil {
+		log.Printf("Error buying wishlist item: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("❌ Error: %v", err)})
+	}
+
+	b.NotifyPurchase(item.GroupID, user.ID, item.Title, item.Cost)
+
+	c.Edit(fmt.Sprintf(b.t(lang, "bot.wishlist.bought"), item.Title))
+	return c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf(b.t(lang, "bot.wishlist.bought"), item.Title)})
+}
+
 // handleTasks handles the /tasks command
 func (b *Bot) handleTasks(c tele.Context) error {
 	telegramID := c.Sender().ID
@@ -339,6 +474,34 @@ func (b *Bot) handleCallback(c tele.Context) error {
 		return b.handleTaskCompletion(c, id)
 	case "back_tasks":
 		return b.handleTasks(c)
+	case "wishbuy":
+		return b.handleWishlistPurchase(c, id)
+	case "digestdone":
+		return b.handleDigestCompletion(c, id)
+	case "snoozelist":
+		return b.handleSnoozeList(c, id)
+	case "hide":
+		return b.handleHideOptions(c, id)
+	case "hideuntil":
+		if len(parts) < 3 {
+			return c.Respond(&tele.CallbackResponse{Text: "❌ Invalid snooze time"})
+		}
+		hours, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: "❌ Invalid snooze time"})
+		}
+		return b.handleHideTask(c, id, hours)
+	case "hidecustom":
+		return b.handleAskTime(c, pendingReply{kind: replyHide, id: id})
+	case "hideat":
+		if len(parts) < 3 {
+			return c.Respond(&tele.CallbackResponse{Text: "❌ Invalid snooze time"})
+		}
+		unix, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: "❌ Invalid snooze time"})
+		}
+		return b.handleHideAt(c, id, time.Unix(unix, 0))
 	case "notif":
 		return b.handleNotificationToggle(c, parts[1])
 	default:
@@ -355,7 +518,7 @@ func (b *Bot) handleNotificationCallback(c tele.Context, data string) error {
 		return c.Respond(&tele.CallbackResponse{Text: "❌ Invalid notification action"})
 	}
 
-	action := parts[1] // done, snooze, later, reschedule
+	action := parts[1] // done, snooze, later, stop, reschedule
 	notifIDStr := parts[2]
 	notifID, err := strconv.ParseInt(notifIDStr, 10, 64)
 	if err != nil {
@@ -371,6 +534,21 @@ func (b *Bot) handleNotificationCallback(c tele.Context, data string) error {
 		return b.handleNotifySnooze(c, notifID)
 	case "later":
 		return b.handleNotifyLater(c, notifID)
+	case "stop":
+		return b.handleNotifyStop(c, notifID)
+	case "morning":
+		return b.handleNotifyMorning(c, notifID)
+	case "custom":
+		return b.handleAskTime(c, pendingReply{kind: replyRemind, id: notifID})
+	case "at":
+		if len(parts) < 4 {
+			return c.Respond(&tele.CallbackResponse{Text: "❌ Invalid reschedule parameters"})
+		}
+		unix, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: "❌ Invalid time"})
+		}
+		return b.handleRemindAt(c, notifID, time.Unix(unix, 0))
 	case "reschedule":
 		// For reschedule, we need the minutes parameter
 		if len(parts) < 4 {
@@ -442,6 +620,44 @@ func (b *Bot) handleNotifyDone(c tele.Context, notificationID int64) error {
 	})
 }
 
+// handleNotifyStop handles the "Stop reminding" button of a nag - stops the
+// escalating reminders of the task until its next deadline
+func (b *Bot) handleNotifyStop(c tele.Context, notificationID int64) error {
+	telegramID := c.Sender().ID
+
+	// Get user
+	user, err := b.service.GetUserByTelegramID(telegramID)
+	if err != nil {
+		log.Printf("Error getting user for notification stop: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ User not found"})
+	}
+
+	// Get notification from DB
+	notification, err := b.service.GetNotificationByID(notificationID)
+	if err != nil {
+		log.Printf("Error getting notification %d: %v", notificationID, err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Notification not found"})
+	}
+
+	task, err := b.service.GetTaskByID(notification.TaskID)
+	if err != nil {
+		log.Printf("Error getting task %d: %v", notification.TaskID, err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Task not found"})
+	}
+
+	if err := b.service.DismissNag(user.ID, task.ID); err != nil {
+		log.Printf("Error dismissing nag of task %d: %v", task.ID, err)
+		return c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("❌ %v", err)})
+	}
+
+	err = c.Edit(fmt.Sprintf("🔕 Stopped reminding\n\nTask: %s", task.Title))
+	if err != nil {
+		log.Printf("Error editing message after stopping reminders: %v", err)
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "🔕 Stopped reminding"})
+}
+
 // handleNotifySnooze handles the "Snooze" button - reschedules notification for default snooze duration
 func (b *Bot) handleNotifySnooze(c tele.Context, notificationID int64) error {
 	telegramID := c.Sender().ID
@@ -479,7 +695,7 @@ func (b *Bot) handleNotifySnooze(c tele.Context, notificationID int64) error {
 	}
 
 	// Create snooze notification helper
-	if err := b.createSnoozeNotification(task.ID, user.ID, settings.SnoozeDefaultMinutes); err != nil {
+	if err := b.createSnoozeNotification(task.ID, user.ID, time.Now().Add(time.Duration(settings.SnoozeDefaultMinutes)*time.Minute)); err != nil {
 		log.Printf("Error creating snooze notification: %v", err)
 		return c.Respond(&tele.CallbackResponse{Text: "❌ Failed to snooze notification"})
 	}
@@ -530,7 +746,6 @@ func (b *Bot) handleNotifyLater(c tele.Context, notificationID int64) error {
 		{"1 hour", 60},
 		{"2 hours", 120},
 		{"4 hours", 240},
-		{"Tomorrow (24h)", 1440},
 	}
 
 	// Create inline keyboard with duration options
@@ -542,6 +757,14 @@ func (b *Bot) handleNotifyLater(c tele.Context, notificationID int64) error {
 		}
 		rows = append(rows, []tele.InlineButton{btn})
 	}
+	rows = append(rows, []tele.InlineButton{{
+		Text: fmt.Sprintf("🌅 Tomorrow morning (%02d:00)", core.MorningHour),
+		Data: fmt.Sprintf("notify_morning_%d", notificationID),
+	}})
+	rows = append(rows, []tele.InlineButton{{
+		Text: "✍️ Other time…",
+		Data: fmt.Sprintf("notify_custom_%d", notificationID),
+	}})
 
 	markup := &tele.ReplyMarkup{InlineKeyboard: rows}
 
@@ -591,7 +814,7 @@ func (b *Bot) handleNotifyReschedule(c tele.Context, notificationID int64, minut
 	}
 
 	// Create snooze notification with specified duration
-	if err := b.createSnoozeNotification(task.ID, user.ID, minutes); err != nil {
+	if err := b.createSnoozeNotification(task.ID, user.ID, time.Now().Add(time.Duration(minutes)*time.Minute)); err != nil {
 		log.Printf("Error creating snooze notification: %v", err)
 		return c.Respond(&tele.CallbackResponse{Text: "❌ Failed to schedule reminder"})
 	}
@@ -619,11 +842,52 @@ func (b *Bot) handleNotifyReschedule(c tele.Context, notificationID int64, minut
 	})
 }
 
+// handleNotifyMorning moves a reminder to the next morning in the user's timezone
+func (b *Bot) handleNotifyMorning(c tele.Context, notificationID int64) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		log.Printf("Error getting user for morning reminder: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ User not found"})
+	}
+
+	notification, err := b.service.GetNotificationByID(notificationID)
+	if err != nil {
+		log.Printf("Error getting notification %d: %v", notificationID, err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Notification not found"})
+	}
+
+	task, err := b.service.GetTaskByID(notification.TaskID)
+	if err != nil {
+		log.Printf("Error getting task %d: %v", notification.TaskID, err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Task not found"})
+	}
+
+	morning := core.NextMorning(time.Now(), user.Location())
+	if err := b.createSnoozeNotification(task.ID, user.ID, morning); err != nil {
+		log.Printf("Error creating morning notification: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Failed to schedule reminder"})
+	}
+
+	message := fmt.Sprintf(
+		"🔔 Reminder scheduled!\n\n"+
+			"Task: %s\n\n"+
+			"I'll remind you on %s ⏰",
+		task.Title,
+		morning.Format("Mon, 02 Jan at 15:04 MST"),
+	)
+	if user.Timezone == "" {
+		message += "\n\n🌍 Set your timezone with /timezone so mornings are your mornings."
+	}
+	if err := c.Edit(message); err != nil {
+		log.Printf("Error editing message after morning reschedule: %v", err)
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "🌅 See you in the morning"})
+}
+
 // createSnoozeNotification creates a snooze notification for a task
 // This is a helper method used by both snooze and reschedule handlers
-func (b *Bot) createSnoozeNotification(taskID, userID int64, delayMinutes int) error {
-	scheduledAt := time.Now().Add(time.Duration(delayMinutes) * time.Minute)
-
+func (b *Bot) createSnoozeNotification(taskID, userID int64, scheduledAt time.Time) error {
 	notification := &core.TaskNotification{
 		TaskID:           taskID,
 		UserID:           userID,
@@ -725,43 +989,158 @@ func (b *Bot) handleGroupSelection(c tele.Context, groupID int64) error {
 		))
 	}
 
+	if err := b.service.ApplyTaskSnoozes(user.ID, tasks); err != nil {
+		log.Printf("Error getting snoozes: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Couldn't fetch tasks"})
+	}
+	now := time.Now()
+	tasks, hidden := core.SplitAvailableTasks(tasks, now)
+	core.SortTasksByDueDate(tasks)
+	if len(tasks) == 0 {
+		return c.Edit(fmt.Sprintf(
+			"💤 Nothing to do in %s right now!\n\n"+
+				"%d task(s) are hidden until later. Enjoy the break 🌿",
+			group.Name,
+			len(hidden),
+		), &tele.ReplyMarkup{InlineKeyboard: [][]tele.InlineButton{{{Text: "⬅️ Back to Groups", Data: "back_tasks:0"}}}})
+	}
+
 	// Create inline keyboard with task buttons
 	var rows [][]tele.InlineButton
+	overdue := 0
 	for _, task := range tasks {
 		rewardEmoji := "🪙"
 		if task.RewardValue >= 10 {
 			rewardEmoji = "💰"
 		}
+		label := fmt.Sprintf("%s (+%d)", task.Title, task.RewardValue)
+		if task.IsOverdue(now) {
+			rewardEmoji = "⏰"
+			overdue++
+		} else if task.IsDueSoon(now) {
+			rewardEmoji = "⏳"
+			label += " · " + task.DueIn(now)
+		}
 
 		btn := tele.InlineButton{
-			Text: fmt.Sprintf("%s %s (+%d)", rewardEmoji, task.Title, task.RewardValue),
+			Text: fmt.Sprintf("%s %s", rewardEmoji, label),
 			Data: fmt.Sprintf("task:%d", task.ID),
 		}
 		rows = append(rows, []tele.InlineButton{btn})
 	}
 
-	// Add back button
+	// Add snooze and back buttons
+	snoozeBtn := tele.InlineButton{
+		Text: "💤 Snooze a task",
+		Data: fmt.Sprintf("snoozelist:%d", groupID),
+	}
 	backBtn := tele.InlineButton{
 		Text: "⬅️ Back to Groups",
 		Data: "back_tasks:0",
 	}
-	rows = append(rows, []tele.InlineButton{backBtn})
+	rows = append(rows, []tele.InlineButton{snoozeBtn, backBtn})
 
 	markup := &tele.ReplyMarkup{InlineKeyboard: rows}
 
 	// Get current balance for this group
 	balance, _ := b.service.GetBalance(user.ID, groupID)
 
-	return c.Edit(
-		fmt.Sprintf(
-			"📋 Tasks in %s\n"+
-				"💰 Current balance: %d coins\n\n"+
-				"Click a task to complete it and earn coins! 🚀",
-			group.Name,
-			balance,
-		),
-		markup,
+	message := fmt.Sprintf(
+		"📋 Tasks in %s\n"+
+			"💰 Current balance: %d coins\n\n"+
+			"Click a task to complete it and earn coins! 🚀",
+		group.Name,
+		balance,
 	)
+	if overdue > 0 {
+		message += fmt.Sprintf("\n\n⏰ %d overdue — finish them before penalties kick in!", overdue)
+	}
+	if len(hidden) > 0 {
+		message += fmt.Sprintf("\n💤 %d hidden until later", len(hidden))
+	}
+
+	return c.Edit(message, markup)
+}
+
+// handleSnoozeList lets the user pick which task of a group to hide for a while
+func (b *Bot) handleSnoozeList(c tele.Context, groupID int64) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ User not found"})
+	}
+	tasks, err := b.service.GetTasksByGroupID(groupID)
+	if err != nil {
+		log.Printf("Error getting tasks: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Couldn't fetch tasks"})
+	}
+	if err := b.service.ApplyTaskSnoozes(user.ID, tasks); err != nil {
+		log.Printf("Error getting snoozes: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Couldn't fetch tasks"})
+	}
+	tasks, _ = core.SplitAvailableTasks(tasks, time.Now())
+
+	var rows [][]tele.InlineButton
+	for _, task := range tasks {
+		rows = append(rows, []tele.InlineButton{{
+			Text: "💤 " + task.Title,
+			Data: fmt.Sprintf("hide:%d", task.ID),
+		}})
+	}
+	rows = append(rows, []tele.InlineButton{{Text: "⬅️ Back", Data: fmt.Sprintf("group:%d", groupID)}})
+
+	return c.Edit("💤 Which task should wait?\n\nIt disappears from your list and stays quiet until the time you pick. Its deadline doesn't move.", &tele.ReplyMarkup{InlineKeyboard: rows})
+}
+
+// handleHideOptions shows how long a task can be hidden for
+func (b *Bot) handleHideOptions(c tele.Context, taskID int64) error {
+	task, err := b.service.GetTaskByID(taskID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Task not found"})
+	}
+	// A missing user falls back to server time
+	user, _ := b.service.GetUserByTelegramID(c.Sender().ID)
+
+	var rows [][]tele.InlineButton
+	for _, hours := range core.SnoozePresetHours {
+		rows = append(rows, []tele.InlineButton{{
+			Text: formatDuration(hours * 60),
+			Data: fmt.Sprintf("hideuntil:%d:%d", task.ID, hours),
+		}})
+	}
+	rows = append(rows, []tele.InlineButton{{Text: "✍️ Other time…", Data: fmt.Sprintf("hidecustom:%d", task.ID)}})
+	rows = append(rows, []tele.InlineButton{{Text: "⬅️ Back", Data: fmt.Sprintf("group:%d", task.GroupID)}})
+
+	message := fmt.Sprintf("💤 Hide \"%s\" for how long?", task.Title)
+	if task.DueAt != nil {
+		message += fmt.Sprintf("\n\n⏰ Due %s — it can't be hidden past that.", task.DueAt.In(user.Location()).Format("Mon, 02 Jan at 15:04"))
+	}
+	return c.Edit(message, &tele.ReplyMarkup{InlineKeyboard: rows})
+}
+
+// handleHideTask snoozes a task for the chosen number of hours
+func (b *Bot) handleHideTask(c tele.Context, taskID int64, hours int) error {
+	return b.handleHideAt(c, taskID, time.Now().Add(time.Duration(hours)*time.Hour))
+}
+
+// handleHideAt snoozes a task until the given time
+func (b *Bot) handleHideAt(c tele.Context, taskID int64, until time.Time) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ User not found"})
+	}
+	b.clearReply(c.Sender().ID)
+
+	task, err := b.service.SnoozeTask(user.ID, taskID, until)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("❌ %v", err)})
+	}
+
+	c.Edit(fmt.Sprintf(
+		"💤 %s is hidden until %s.\n\nI'll bring it back then — no need to think about it until later 🌿",
+		task.Title,
+		task.HiddenUntil().In(user.Location()).Format("Mon, 02 Jan at 15:04"),
+	))
+	return c.Respond(&tele.CallbackResponse{Text: "💤 Snoozed"})
 }
 
 // handleTaskCompletion handles task completion
@@ -944,9 +1323,33 @@ func (b *Bot) SendNotification(chatID int64, message string, buttons map[string]
 		rows = append(rows, row)
 	}
 
+	// Any other buttons go on their own rows, sorted for a stable layout
+	var extra []string
+	for btnText := range buttons {
+		known := false
+		for _, ordered := range buttonOrder {
+			if btnText == ordered {
+				known = true
+				break
+			}
+		}
+		if !known {
+			extra = append(extra, btnText)
+		}
+	}
+	sort.Strings(extra)
+	for _, btnText := range extra {
+		rows = append(rows, []tele.InlineButton{{Text: btnText, Data: buttons[btnText]}})
+	}
+
 	markup := &tele.ReplyMarkup{InlineKeyboard: rows}
 
 	// Send message to user
 	_, err := b.bot.Send(&tele.User{ID: chatID}, message, markup)
+	// The user blocked the bot or is gone; the worker stops retrying
+	if errors.Is(err, tele.ErrBlockedByUser) || errors.Is(err, tele.ErrUserIsDeactivated) ||
+		errors.Is(err, tele.ErrNotStartedByUser) || errors.Is(err, tele.ErrChatNotFound) {
+		return fmt.Errorf("%w: %v", core.ErrRecipientBlocked, err)
+	}
 	return err
 }
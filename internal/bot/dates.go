@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// Kinds of questions answered by typing a time
+const (
+	replyRemind = "remind" // id is a notification
+	replyHide   = "hide"   // id is a task
+)
+
+// pendingReply remembers what the next text message of a user answers
+type pendingReply struct {
+	kind string
+	id   int64
+}
+
+func (b *Bot) expectReply(telegramID int64, reply pendingReply) {
+	b.repliesMu.Lock()
+	defer b.repliesMu.Unlock()
+	b.replies[telegramID] = reply
+}
+
+func (b *Bot) pendingReplyOf(telegramID int64) (pendingReply, bool) {
+	b.repliesMu.Lock()
+	defer b.repliesMu.Unlock()
+	reply, ok := b.replies[telegramID]
+	return reply, ok
+}
+
+func (b *Bot) clearReply(telegramID int64) {
+	b.repliesMu.Lock()
+	defer b.repliesMu.Unlock()
+	delete(b.replies, telegramID)
+}
+
+// formatUserTime shows a time in the user's timezone
+func formatUserTime(t time.Time, user *core.User) string {
+	return t.In(user.Location()).Format("Mon, 02 Jan at 15:04 MST")
+}
+
+// handleWhen shows how a typed time is understood: /when tomorrow 9am
+func (b *Bot) handleWhen(c tele.Context) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Send(b.t(b.lang(c, nil), "bot.web.unknown"))
+	}
+	lang := b.lang(c, user)
+
+	phrase := strings.TrimSpace(c.Message().Payload)
+	if phrase == "" {
+		return c.Send(b.t(lang, "bot.when.usage"))
+	}
+
+	at, err := core.ParseNaturalTime(phrase, time.Now(), user.Location())
+	if err != nil {
+		return c.Send(fmt.Sprintf(b.t(lang, "bot.when.invalid"), phrase))
+	}
+	return c.Send(fmt.Sprintf(b.t(lang, "bot.when.result"), phrase, formatUserTime(at, user)))
+}
+
+// handleAskTime asks the user to type when a reminder or hidden task should come back
+func (b *Bot) handleAskTime(c tele.Context, reply pendingReply) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ User not found"})
+	}
+	lang := b.lang(c, user)
+
+	b.expectReply(c.Sender().ID, reply)
+	if err := c.Edit(b.t(lang, "bot.when.ask")); err != nil {
+		log.Printf("Error editing message to ask for a time: %v", err)
+	}
+	return c.Respond(&tele.CallbackResponse{Text: "✍️"})
+}
+
+// handleTimeReply reads a typed time and asks to confirm how it was understood.
+// Text that answers no question is ignored.
+func (b *Bot) handleTimeReply(c tele.Context) error {
+	reply, ok := b.pendingReplyOf(c.Sender().ID)
+	if !ok {
+		return nil
+	}
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Send(b.t(b.lang(c, nil), "bot.web.unknown"))
+	}
+	lang := b.lang(c, user)
+
+	now := time.Now()
+	at, err := core.ParseNaturalTime(c.Text(), now, user.Location())
+	if err != nil {
+		return c.Send(fmt.Sprintf(b.t(lang, "bot.when.invalid"), c.Text()))
+	}
+	if !at.After(now) {
+		return c.Send(fmt.Sprintf(b.t(lang, "bot.when.past"), formatUserTime(at, user)))
+	}
+
+	var data string
+	switch reply.kind {
+	case replyRemind:
+		data = fmt.Sprintf("notify_at_%d_%d", reply.id, at.Unix())
+	case replyHide:
+		data = fmt.Sprintf("hideat:%d:%d", reply.id, at.Unix())
+	default:
+		b.clearReply(c.Sender().ID)
+		return nil
+	}
+
+	markup := &tele.ReplyMarkup{InlineKeyboard: [][]tele.InlineButton{
+		{{Text: b.t(lang, "bot.when.confirm.button"), Data: data}},
+	}}
+	return c.Send(fmt.Sprintf(b.t(lang, "bot.when.confirm"), formatUserTime(at, user)), markup)
+}
+
+// handleRemindAt schedules a reminder at a time the user typed and confirmed
+func (b *Bot) handleRemindAt(c tele.Context, notificationID int64, at time.Time) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		log.Printf("Error getting user for custom reminder: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ User not found"})
+	}
+	b.clearReply(c.Sender().ID)
+
+	notification, err := b.service.GetNotificationByID(notificationID)
+	if err != nil {
+		log.Printf("Error getting notification %d: %v", notificationID, err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Notification not found"})
+	}
+
+	task, err := b.service.GetTaskByID(notification.TaskID)
+	if err != nil {
+		log.Printf("Error getting task %d: %v", notification.TaskID, err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Task not found"})
+	}
+
+	if err := b.createSnoozeNotification(task.ID, user.ID, at); err != nil {
+		log.Printf("Error creating custom reminder: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Failed to schedule reminder"})
+	}
+
+	message := fmt.Sprintf(
+		"🔔 Reminder scheduled!\n\n"+
+			"Task: %s\n\n"+
+			"I'll remind you on %s ⏰",
+		task.Title,
+		formatUserTime(at, user),
+	)
+	if err := c.Edit(message); err != nil {
+		log.Printf("Error editing message after custom reschedule: %v", err)
+	}
+
+	return c.Respond(&tele.CallbackResponse{Text: "🔔 Reminder set"})
+}
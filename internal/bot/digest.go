@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"small-rpg-adhd-monolith/internal/core"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleDigest shows or changes the daily digests:
+// /digest 08:00 21:00, /digest morning 7:30, /digest evening off, /digest off
+func (b *Bot) handleDigest(c tele.Context) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Send(b.t(b.lang(c, nil), "bot.web.unknown"))
+	}
+	lang := b.lang(c, user)
+
+	settings, err := b.service.GetNotificationSettings(user.ID)
+	if err != nil {
+		return c.Send(b.t(lang, "bot.error.notifications"))
+	}
+
+	args := strings.Fields(strings.ToLower(c.Message().Payload))
+	if len(args) == 0 {
+		return c.Send(b.digestStatus(lang, settings))
+	}
+
+	morning, evening := settings.MorningDigest, settings.EveningDigest
+	parse := func(spec string) (*int, bool) {
+		at, err := core.ParseDigestTime(spec)
+		return at, err == nil
+	}
+
+	var ok bool
+	switch {
+	case len(args) == 2 && (args[0] == "morning" || args[0] == "утро"):
+		morning, ok = parse(args[1])
+	case len(args) == 2 && (args[0] == "evening" || args[0] == "вечер"):
+		evening, ok = parse(args[1])
+	case len(args) == 2:
+		if morning, ok = parse(args[0]); ok {
+			evening, ok = parse(args[1])
+		}
+	case len(args) == 1 && (args[0] == "off" || args[0] == "выкл"):
+		morning, evening, ok = nil, nil, true
+	}
+	if !ok {
+		return c.Send(fmt.Sprintf(b.t(lang, "bot.digest.invalid"), strings.Join(args, " ")))
+	}
+
+	if err := b.service.UpdateDigestTimes(user.ID, morning, evening); err != nil {
+		return c.Send(b.t(lang, "bot.digest.error"))
+	}
+	settings.MorningDigest, settings.EveningDigest = morning, evening
+	return c.Send(b.t(lang, "bot.digest.saved") + "\n\n" + b.digestStatus(lang, settings))
+}
+
+// digestStatus describes when the user gets their digests
+func (b *Bot) digestStatus(lang string, settings *core.NotificationSettings) string {
+	at := func(minutes *int) string {
+		if minutes == nil {
+			return b.t(lang, "bot.digest.off")
+		}
+		return core.FormatDigestTime(minutes)
+	}
+	return fmt.Sprintf(b.t(lang, "bot.digest.status"), at(settings.MorningDigest), at(settings.EveningDigest)) +
+		"\n\n" + b.t(lang, "bot.digest.usage")
+}
+
+// handleDigestCompletion completes a task listed in a digest and takes its
+// button off the digest, which stays readable
+func (b *Bot) handleDigestCompletion(c tele.Context, taskID int64) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ User not found"})
+	}
+
+	task, err := b.service.GetTaskByID(taskID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ Task not found"})
+	}
+
+	transaction, err := b.service.CompleteTask(user.ID, taskID, nil)
+	if err != nil {
+		log.Printf("Error completing task from digest: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("❌ Error: %v", err)})
+	}
+
+	if msg := c.Callback().Message; msg != nil && msg.ReplyMarkup != nil {
+		var rows [][]tele.InlineButton
+		for _, row := range msg.ReplyMarkup.InlineKeyboard {
+			var kept []tele.InlineButton
+			for _, btn := range row {
+				if btn.Data != c.Callback().Data {
+					kept = append(kept, btn)
+				}
+			}
+			if len(kept) > 0 {
+				rows = append(rows, kept)
+			}
+		}
+		if err := c.Edit(&tele.ReplyMarkup{InlineKeyboard: rows}); err != nil {
+			log.Printf("Error updating digest buttons: %v", err)
+		}
+	}
+
+	err = c.Respond(&tele.CallbackResponse{
+		Text: fmt.Sprintf("✅ %s · +%d coins!", task.Title, transaction.Amount),
+	})
+
+	b.notifyGroupMembers(task.GroupID, user.ID, fmt.Sprintf(
+		"🎉 %s completed: %s (+%d coins)",
+		user.Username,
+		task.Title,
+		transaction.Amount,
+	))
+
+	return err
+}
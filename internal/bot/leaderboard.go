@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleLeaderboard shows the leaderboards of the user's groups:
+// /leaderboard, /leaderboard month, /leaderboard all, /leaderboard season
+func (b *Bot) handleLeaderboard(c tele.Context) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Send(b.t(b.lang(c, nil), "bot.web.unknown"))
+	}
+	lang := b.lang(c, user)
+
+	period, ok := core.ParseLeaderboardPeriod(c.Message().Payload)
+	if !ok {
+		return c.Send(b.t(lang, "bot.leaderboard.usage"))
+	}
+
+	boards, err := b.service.BuildMemberLeaderboards(user.ID, period, time.Now())
+	if err != nil {
+		log.Printf("Error building leaderboards: %v", err)
+		return c.Send(b.t(lang, "bot.leaderboard.error"))
+	}
+	if len(boards) == 0 {
+		return c.Send(fmt.Sprintf(b.t(lang, "bot.tasks.empty"), b.publicURL))
+	}
+	return c.Send(core.LeaderboardMessage(boards) + "\n\n" + b.t(lang, "bot.leaderboard.hint"))
+}
@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleQuiet shows or changes quiet hours:
+// /quiet 22:00-07:00, /quiet sat 23:00-10:00, /quiet sat default, /quiet urgent on
+func (b *Bot) handleQuiet(c tele.Context) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Send(b.t(b.lang(c, nil), "bot.web.unknown"))
+	}
+	lang := b.lang(c, user)
+
+	settings, err := b.service.GetNotificationSettings(user.ID)
+	if err != nil {
+		return c.Send(b.t(lang, "bot.error.notifications"))
+	}
+
+	args := strings.Fields(strings.ToLower(c.Message().Payload))
+	if len(args) == 0 {
+		return c.Send(b.quietStatus(lang, settings))
+	}
+
+	hours, days, urgent := settings.QuietHours, settings.QuietDays, settings.UrgentDuringQuiet
+	if days == nil {
+		days = make(map[time.Weekday]core.QuietHours)
+	}
+
+	switch day, isDay := core.ParseWeekday(args[0]); {
+	case args[0] == "urgent" || args[0] == "срочные":
+		if len(args) != 2 {
+			return c.Send(b.t(lang, "bot.quiet.usage"))
+		}
+		switch args[1] {
+		case "on", "вкл":
+			urgent = true
+		case "off", "выкл":
+			urgent = false
+		default:
+			return c.Send(b.t(lang, "bot.quiet.usage"))
+		}
+	case isDay:
+		spec := strings.Join(args[1:], " ")
+		if spec == "" || spec == "default" || spec == "обычно" {
+			delete(days, day)
+			break
+		}
+		// "off" is kept as an override so the day stays free of quiet hours
+		dayHours, err := core.ParseQuietHours(spec)
+		if err != nil {
+			return c.Send(fmt.Sprintf(b.t(lang, "bot.quiet.invalid"), spec))
+		}
+		days[day] = dayHours
+	default:
+		spec := strings.Join(args, " ")
+		hours, err = core.ParseQuietHours(spec)
+		if err != nil {
+			return c.Send(fmt.Sprintf(b.t(lang, "bot.quiet.invalid"), spec))
+		}
+	}
+
+	if err := b.service.UpdateQuietHours(user.ID, hours, days, urgent); err != nil {
+		return c.Send(b.t(lang, "bot.quiet.error"))
+	}
+	settings.QuietHours, settings.QuietDays, settings.UrgentDuringQuiet = hours, days, urgent
+	return c.Send(b.t(lang, "bot.quiet.saved") + "\n\n" + b.quietStatus(lang, settings))
+}
+
+// quietStatus describes the user's quiet hours, one line per weekday override
+func (b *Bot) quietStatus(lang string, settings *core.NotificationSettings) string {
+	window := func(hours core.QuietHours) string {
+		if !hours.IsSet() {
+			return b.t(lang, "bot.quiet.none")
+		}
+		return hours.String()
+	}
+
+	var overrides strings.Builder
+	for _, day := range core.QuietWeekdays() {
+		if hours, ok := settings.QuietDays[day]; ok {
+			fmt.Fprintf(&overrides, "\n• %s: %s", b.t(lang, "weekday."+core.WeekdayKey(day)), window(hours))
+		}
+	}
+
+	urgent := b.t(lang, "bot.quiet.urgent.off")
+	if settings.UrgentDuringQuiet {
+		urgent = b.t(lang, "bot.quiet.urgent.on")
+	}
+
+	return fmt.Sprintf(b.t(lang, "bot.quiet.status"), window(settings.QuietHours), overrides.String(), urgent) +
+		"\n\n" + b.t(lang, "bot.quiet.usage")
+}
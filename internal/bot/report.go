@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleReport sends the user's progress report or changes its schedule:
+// /report, /report month, /report last week, /report auto week, /report auto off
+func (b *Bot) handleReport(c tele.Context) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Send(b.t(b.lang(c, nil), "bot.web.unknown"))
+	}
+	lang := b.lang(c, user)
+
+	args := strings.Fields(strings.ToLower(c.Message().Payload))
+	if len(args) > 0 && (args[0] == "auto" || args[0] == "авто") {
+		if len(args) != 2 {
+			return c.Send(b.t(lang, "bot.report.usage"))
+		}
+		period, ok := core.ParseReportPeriod(args[1])
+		if !ok && args[1] != "off" && args[1] != "выкл" {
+			return c.Send(b.t(lang, "bot.report.usage"))
+		}
+		if err := b.service.UpdateReportSchedule(user.ID, period); err != nil {
+			return c.Send(b.t(lang, "bot.report.error"))
+		}
+		if period == "" {
+			return c.Send(b.t(lang, "bot.report.auto.off"))
+		}
+		return c.Send(b.t(lang, "bot.report.auto."+period))
+	}
+
+	back := 0
+	if len(args) > 0 && (args[0] == "last" || strings.HasPrefix(args[0], "прошл")) {
+		back = 1
+		args = args[1:]
+	}
+	period := core.ReportWeek
+	if len(args) > 0 {
+		var ok bool
+		if period, ok = core.ParseReportPeriod(args[0]); !ok || len(args) > 1 {
+			return c.Send(b.t(lang, "bot.report.usage"))
+		}
+	}
+
+	reports, err := b.service.BuildMemberReports(user.ID, period, back, time.Now())
+	if err != nil {
+		log.Printf("Error building reports: %v", err)
+		return c.Send(b.t(lang, "bot.report.error"))
+	}
+	if len(reports) == 0 {
+		return c.Send(fmt.Sprintf(b.t(lang, "bot.tasks.empty"), b.publicURL))
+	}
+	return c.Send(core.ReportMessage(reports))
+}
@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"small-rpg-adhd-monolith/internal/core"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// searchIcons mark the kind of an inline search result
+var searchIcons = map[string]string{
+	core.SearchTask:        "🎯",
+	core.SearchItem:        "🛒",
+	core.SearchTransaction: "📜",
+}
+
+// handleInlineSearch answers inline queries (@bot dishes) with the tasks,
+// shop items and history entries of all the user's groups that match
+func (b *Bot) handleInlineSearch(c tele.Context) error {
+	user, err := b.service.GetUserByTelegramID(c.Sender().ID)
+	if err != nil {
+		return c.Answer(&tele.QueryResponse{
+			Results:           tele.Results{},
+			IsPersonal:        true,
+			SwitchPMText:      b.t(b.lang(c, nil), "bot.search.link"),
+			SwitchPMParameter: "search",
+		})
+	}
+	lang := b.lang(c, user)
+
+	query := strings.TrimSpace(c.Query().Text)
+	results := tele.Results{}
+	if query == "" {
+		return c.Answer(&tele.QueryResponse{Results: results, IsPersonal: true})
+	}
+
+	found, err := b.service.Search(user.ID, 0, query)
+	if err != nil {
+		log.Printf("Error searching: %v", err)
+		return c.Answer(&tele.QueryResponse{Results: results, IsPersonal: true})
+	}
+	for _, r := range found {
+		url := fmt.Sprintf("%s/groups/%d", b.publicURL, r.GroupID)
+		description := r.GroupName
+		if r.Kind == core.SearchTransaction {
+			description += fmt.Sprintf(" · 🧀 %d · %s", r.Amount, r.CreatedAt.In(user.Location()).Format("Jan 2 2006"))
+		}
+		if r.Body != "" {
+			description += " · " + r.Body
+		}
+
+		article := &tele.ArticleResult{
+			Title:       searchIcons[r.Kind] + " " + r.Title,
+			Description: description,
+			Text:        fmt.Sprintf("%s %s\n📁 %s\n%s", searchIcons[r.Kind], r.Title, description, url),
+			URL:         url,
+			HideURL:     true,
+		}
+		article.SetResultID(fmt.Sprintf("%s-%d", r.Kind, r.ID))
+		results = append(results, article)
+	}
+
+	if len(results) == 0 {
+		return c.Answer(&tele.QueryResponse{
+			Results:           results,
+			IsPersonal:        true,
+			SwitchPMText:      b.t(lang, "bot.search.empty"),
+			SwitchPMParameter: "search",
+		})
+	}
+	return c.Answer(&tele.QueryResponse{Results: results, IsPersonal: true})
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// SnoozePresetHours are the quick "hide for..." choices offered on the web and in Telegram
+var SnoozePresetHours = []int{3, 24, 72, 168}
+
+// validateAvailableFrom checks a task's start date against its deadline
+func validateAvailableFrom(availableFrom, dueAt *time.Time) error {
+	if availableFrom != nil && dueAt != nil && availableFrom.After(*dueAt) {
+		return fmt.Errorf("a task cannot stay hidden past its deadline")
+	}
+	return nil
+}
+
+// SplitAvailableTasks separates tasks that can be done now from those hidden until later
+func SplitAvailableTasks(tasks []*Task, now time.Time) (available, hidden []*Task) {
+	for _, task := range tasks {
+		if task.IsAvailable(now) {
+			available = append(available, task)
+		} else {
+			hidden = append(hidden, task)
+		}
+	}
+	return available, hidden
+}
+
+// ApplyTaskSnoozes fills SnoozedUntil of the tasks the user hid from
+// themselves, so IsAvailable and SplitAvailableTasks see them as that member
+func (s *Service) ApplyTaskSnoozes(userID int64, tasks []*Task) error {
+	snoozes, err := s.store.GetTaskSnoozes(userID, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if until, ok := snoozes[task.ID]; ok {
+			task.SnoozedUntil = &until
+		}
+	}
+	return nil
+}
+
+// SnoozeTask hides a task from the user, and only from them, until the given
+// time. The deadline stays where it is, so a task cannot be hidden past it.
+func (s *Service) SnoozeTask(userID, taskID int64, until time.Time) (*Task, error) {
+	task, err := s.store.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember, err := s.store.IsUserInGroup(userID, task.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, fmt.Errorf("user is not a member of this group")
+	}
+	if err := s.ensureGroupWritable(task.GroupID); err != nil {
+		return nil, err
+	}
+
+	if !until.After(time.Now()) {
+		return nil, fmt.Errorf("snooze time must be in the future")
+	}
+	if task.AvailableFrom != nil && !until.After(*task.AvailableFrom) {
+		return nil, fmt.Errorf("task is already hidden until %s", s.userTime(userID, *task.AvailableFrom))
+	}
+	if err := validateAvailableFrom(&until, task.DueAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.SetTaskSnooze(task.ID, userID, &until); err != nil {
+		return nil, err
+	}
+	s.wakeNotificationWorker()
+	task.SnoozedUntil = &until
+	return task, nil
+}
+
+// ShowTaskNow ends the user's snooze of a task so it is visible to them right
+// away. A start date hides the task from the whole group, so only the owner
+// can clear that.
+func (s *Service) ShowTaskNow(userID, taskID int64) (*Task, error) {
+	task, err := s.store.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember, err := s.store.IsUserInGroup(userID, task.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, fmt.Errorf("user is not a member of this group")
+	}
+	if err := s.ensureGroupWritable(task.GroupID); err != nil {
+		return nil, err
+	}
+
+	if task.AvailableFrom != nil && time.Now().Before(*task.AvailableFrom) {
+		if _, err := s.requireGroupOwner(userID, task.GroupID); err != nil {
+			return nil, fmt.Errorf("only the group owner can show a task before its start date")
+		}
+		if err := s.store.SetTaskAvailableFrom(task.ID, nil); err != nil {
+			return nil, err
+		}
+		task.AvailableFrom = nil
+	}
+	if err := s.store.SetTaskSnooze(task.ID, userID, nil); err != nil {
+		return nil, err
+	}
+	s.wakeNotificationWorker()
+	return task, nil
+}
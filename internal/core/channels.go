@@ -0,0 +1,302 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Delivery channels a user can be reminded on
+const (
+	ChannelTelegram = "telegram"
+	ChannelWebPush  = "webpush"
+	ChannelEmail    = "email"
+	ChannelWebhook  = "webhook"
+)
+
+// channelOrder is the order channels are tried in until a user picks their own
+var channelOrder = []string{ChannelTelegram, ChannelWebPush, ChannelEmail, ChannelWebhook}
+
+// ErrNoRecipient is returned by a NotificationChannel when the user has no
+// address on it, e.g. no Telegram account linked. The next channel is tried.
+var ErrNoRecipient = errors.New("no address for the user")
+
+// Message is a notification rendered for delivery over any channel
+type Message struct {
+	Subject string            // One line, e.g. the email subject or push title
+	Text    string            // Full plain-text message
+	Path    string            // Page the message is about, e.g. "/groups/3"
+	Buttons map[string]string // Telegram inline buttons: label -> callback data
+
+	// Details of task reminders, for webhooks
+	Event            string // "task_reminder"
+	NotificationID   int64
+	NotificationType string
+	TaskID           int64
+	TaskTitle        string
+	DueAt            *time.Time
+}
+
+// Recipient is who a channel delivers a message to
+type Recipient struct {
+	User              *User
+	Address           string              // Email address or webhook URL
+	PushSubscriptions []*PushSubscription // Browsers, for Web Push
+}
+
+// NotificationChannel delivers messages over one medium. Send returns
+// ErrNoRecipient when the user has no address on the channel and wraps
+// ErrRecipientBlocked when the address stopped working for good.
+type NotificationChannel interface {
+	Name() string
+	Send(ctx context.Context, to *Recipient, msg *Message) error
+}
+
+// RegisterNotificationChannel makes a delivery channel available to users.
+// Channels are registered at startup, before the web server and the
+// notification worker run.
+func (s *Service) RegisterNotificationChannel(channel NotificationChannel) {
+	s.channels[channel.Name()] = channel
+}
+
+// NotificationChannels lists the names of the registered channels in their
+// default order
+func (s *Service) NotificationChannels() []string {
+	var names []string
+	for _, name := range channelOrder {
+		if _, ok := s.channels[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// WebPushKey returns the VAPID public key browsers subscribe with, or ""
+// when Web Push is not set up
+func (s *Service) WebPushKey() string {
+	if channel, ok := s.channels[ChannelWebPush].(interface{ PublicKey() string }); ok {
+		return channel.PublicKey()
+	}
+	return ""
+}
+
+// defaultChannelPreference is used for channels the user never configured:
+// Telegram and Web Push are on, since they need no address
+func defaultChannelPreference(userID int64, channel string) *ChannelPreference {
+	pref := &ChannelPreference{UserID: userID, Channel: channel, Enabled: channel == ChannelTelegram || channel == ChannelWebPush}
+	for i, name := range channelOrder {
+		if name == channel {
+			pref.Priority = i
+		}
+	}
+	return pref
+}
+
+// GetChannelPreferences returns the user's preferences for every registered
+// channel in the order they are tried
+func (s *Service) GetChannelPreferences(userID int64) ([]*ChannelPreference, error) {
+	stored, err := s.store.GetChannelPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	byChannel := make(map[string]*ChannelPreference)
+	for _, pref := range stored {
+		byChannel[pref.Channel] = pref
+	}
+
+	var prefs []*ChannelPreference
+	for _, name := range s.NotificationChannels() {
+		pref, ok := byChannel[name]
+		if !ok {
+			pref = defaultChannelPreference(userID, name)
+		}
+		prefs = append(prefs, pref)
+	}
+	// Ties keep the default order
+	sort.SliceStable(prefs, func(i, j int) bool {
+		return prefs[i].Priority < prefs[j].Priority
+	})
+	return prefs, nil
+}
+
+// UpdateChannelPreferences saves the user's channel choices. Channels left
+// out keep their current settings.
+func (s *Service) UpdateChannelPreferences(userID int64, prefs []*ChannelPreference) error {
+	for _, pref := range prefs {
+		if _, ok := s.channels[pref.Channel]; !ok {
+			return fmt.Errorf("unknown notification channel %q", pref.Channel)
+		}
+		address, err := normalizeChannelAddress(pref.Channel, pref.Address)
+		if err != nil {
+			return err
+		}
+		if pref.Enabled && address == "" && (pref.Channel == ChannelEmail || pref.Channel == ChannelWebhook) {
+			return fmt.Errorf("%s needs an address to be turned on", pref.Channel)
+		}
+		pref.UserID = userID
+		pref.Address = address
+	}
+	for _, pref := range prefs {
+		if err := s.store.SaveChannelPreference(pref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeChannelAddress checks the address of an email or webhook channel
+func normalizeChannelAddress(channel, address string) (string, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return "", nil
+	}
+	switch channel {
+	case ChannelEmail:
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return "", fmt.Errorf("invalid email address %q", address)
+		}
+		return parsed.Address, nil
+	case ChannelWebhook:
+		parsed, err := url.Parse(address)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return "", fmt.Errorf("webhook URL must start with http:// or https://")
+		}
+		return parsed.String(), nil
+	}
+	return "", nil
+}
+
+// GetPushSubscriptions lists the browsers the user subscribed to Web Push
+func (s *Service) GetPushSubscriptions(userID int64) ([]*PushSubscription, error) {
+	return s.store.GetPushSubscriptions(userID)
+}
+
+// SubscribeWebPush stores a browser's push subscription for the user
+func (s *Service) SubscribeWebPush(userID int64, endpoint, p256dh, auth string) error {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("push endpoint must be an https URL")
+	}
+	if key, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(p256dh, "=")); err != nil || len(key) != 65 {
+		return fmt.Errorf("invalid push subscription key")
+	}
+	if secret, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(auth, "=")); err != nil || len(secret) != 16 {
+		return fmt.Errorf("invalid push subscription secret")
+	}
+	return s.store.SavePushSubscription(&PushSubscription{
+		UserID:   userID,
+		Endpoint: endpoint,
+		P256dh:   p256dh,
+		Auth:     auth,
+	})
+}
+
+// UnsubscribeWebPush forgets a browser's push subscription, e.g. when the
+// user turns notifications off in the browser or the push service expired it
+func (s *Service) UnsubscribeWebPush(endpoint string) error {
+	return s.store.DeletePushSubscription(endpoint)
+}
+
+// deliverMessage sends a message over the user's enabled channels in their
+// order, stopping at the first that delivers. It wraps ErrNoRecipient when
+// no channel has an address for the user and matches ErrRecipientBlocked
+// only when every channel tried has stopped working for good.
+func (s *Service) deliverMessage(ctx context.Context, user *User, msg *Message) error {
+	prefs, err := s.GetChannelPreferences(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get channel preferences: %w", err)
+	}
+
+	var failures channelErrors
+	for _, pref := range prefs {
+		if !pref.Enabled {
+			continue
+		}
+		to := &Recipient{User: user, Address: pref.Address}
+		if pref.Channel == ChannelWebPush {
+			to.PushSubscriptions, err = s.store.GetPushSubscriptions(user.ID)
+			if err != nil {
+				return fmt.Errorf("failed to get push subscriptions: %w", err)
+			}
+		}
+
+		err := s.channels[pref.Channel].Send(ctx, to, msg)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, ErrNoRecipient) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		notifierLog("Sending to user %d via %s failed: %v", user.ID, pref.Channel, err)
+		failures = append(failures, fmt.Errorf("%s: %w", pref.Channel, err))
+	}
+
+	if len(failures) == 0 {
+		return fmt.Errorf("%w on any enabled channel", ErrNoRecipient)
+	}
+	return failures
+}
+
+// channelErrors collects why each channel failed to deliver a message
+type channelErrors []error
+
+func (e channelErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Is matches ErrRecipientBlocked only when every channel is blocked; while
+// one of them may recover, the delivery is retried
+func (e channelErrors) Is(target error) bool {
+	if target != ErrRecipientBlocked {
+		return false
+	}
+	for _, err := range e {
+		if !errors.Is(err, ErrRecipientBlocked) {
+			return false
+		}
+	}
+	return true
+}
+
+// telegramChannel delivers messages through the Telegram bot within its
+// rate limits
+type telegramChannel struct {
+	bot     BotNotifier
+	limiter *sendLimiter
+}
+
+// NewTelegramChannel wraps the bot as a notification channel. It shares the
+// service's rate limiter with the wishlist announcements.
+func (s *Service) NewTelegramChannel(bot BotNotifier) NotificationChannel {
+	return &telegramChannel{bot: bot, limiter: s.telegramLimiter}
+}
+
+// Name implements NotificationChannel
+func (c *telegramChannel) Name() string {
+	return ChannelTelegram
+}
+
+// Send implements NotificationChannel
+func (c *telegramChannel) Send(ctx context.Context, to *Recipient, msg *Message) error {
+	if to.User.TelegramID == nil {
+		return ErrNoRecipient
+	}
+	if err := c.limiter.wait(ctx, *to.User.TelegramID); err != nil {
+		return err
+	}
+	return c.bot.SendNotification(*to.User.TelegramID, msg.Text, msg.Buttons)
+}
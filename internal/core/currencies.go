@@ -0,0 +1,324 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultCurrency returns the built-in cheese currency shared by every group
+func DefaultCurrency() *Currency {
+	return &Currency{
+		ID:     DefaultCurrencyID,
+		Name:   "Cheese",
+		Emoji:  "🧀",
+		Labels: map[string]string{"en": "Cheese", "ru": "Сыр"},
+	}
+}
+
+// GetCurrencies retrieves the currencies a group defined in addition to cheese
+func (s *Service) GetCurrencies(groupID int64) ([]*Currency, error) {
+	return s.store.GetCurrenciesByGroupID(groupID)
+}
+
+// currencyIndex maps currency IDs of a group, including cheese, to currencies
+func (s *Service) currencyIndex(groupID int64) (map[int64]*Currency, error) {
+	currencies, err := s.store.GetCurrenciesByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[int64]*Currency{DefaultCurrencyID: DefaultCurrency()}
+	for _, c := range currencies {
+		index[c.ID] = c
+	}
+	return index, nil
+}
+
+// validateCurrencyInput checks the fields of a new or edited currency
+func (s *Service) validateCurrencyInput(groupID, currencyID int64, name, emoji string) error {
+	if name == "" {
+		return fmt.Errorf("currency name cannot be empty")
+	}
+	if emoji == "" {
+		return fmt.Errorf("currency emoji cannot be empty")
+	}
+	if strings.EqualFold(name, DefaultCurrency().Name) {
+		return fmt.Errorf("cheese is already the default currency")
+	}
+
+	currencies, err := s.store.GetCurrenciesByGroupID(groupID)
+	if err != nil {
+		return err
+	}
+	for _, c := range currencies {
+		if c.ID != currencyID && strings.EqualFold(c.Name, name) {
+			return fmt.Errorf("a currency with this name already exists")
+		}
+	}
+
+	return nil
+}
+
+// CreateCurrency adds a group-defined currency (owner only)
+func (s *Service) CreateCurrency(userID, groupID int64, name, emoji string, labels map[string]string) (*Currency, error) {
+	name = strings.TrimSpace(name)
+	emoji = strings.TrimSpace(emoji)
+
+	if _, err := s.requireGroupOwner(userID, groupID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureGroupWritable(groupID); err != nil {
+		return nil, err
+	}
+	if err := s.validateCurrencyInput(groupID, 0, name, emoji); err != nil {
+		return nil, err
+	}
+
+	return s.store.CreateCurrency(groupID, name, emoji, cleanLabels(labels))
+}
+
+// UpdateCurrency renames a group-defined currency (owner only)
+func (s *Service) UpdateCurrency(userID, currencyID int64, name, emoji string, labels map[string]string) error {
+	name = strings.TrimSpace(name)
+	emoji = strings.TrimSpace(emoji)
+
+	currency, err := s.store.GetCurrencyByID(currencyID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.requireGroupOwner(userID, currency.GroupID); err != nil {
+		return err
+	}
+	if err := s.ensureGroupWritable(currency.GroupID); err != nil {
+		return err
+	}
+	if err := s.validateCurrencyInput(currency.GroupID, currency.ID, name, emoji); err != nil {
+		return err
+	}
+
+	return s.store.UpdateCurrency(currencyID, name, emoji, cleanLabels(labels))
+}
+
+// cleanLabels drops empty localized labels
+func cleanLabels(labels map[string]string) map[string]string {
+	cleaned := make(map[string]string)
+	for locale, label := range labels {
+		if label = strings.TrimSpace(label); label != "" {
+			cleaned[locale] = label
+		}
+	}
+	return cleaned
+}
+
+// GetBalances retrieves a user's balance in cheese and every currency of the group
+func (s *Service) GetBalances(userID, groupID int64) ([]*CurrencyBalance, error) {
+	amounts, err := s.store.GetBalances(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	currencies, err := s.store.GetCurrenciesByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := []*CurrencyBalance{{Currency: DefaultCurrency(), Amount: amounts[DefaultCurrencyID]}}
+	for _, c := range currencies {
+		balances = append(balances, &CurrencyBalance{Currency: c, Amount: amounts[c.ID]})
+	}
+	return balances, nil
+}
+
+// normalizeCurrencyAmounts drops non-positive amounts and verifies every
+// currency belongs to the group. Cheese is not allowed here because it is
+// stored in the task reward or shop item cost itself.
+func (s *Service) normalizeCurrencyAmounts(groupID int64, amounts []CurrencyAmount) ([]CurrencyAmount, error) {
+	if len(amounts) == 0 {
+		return nil, nil
+	}
+
+	index, err := s.currencyIndex(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized []CurrencyAmount
+	for _, a := range amounts {
+		if a.Amount <= 0 {
+			continue
+		}
+		if a.CurrencyID == DefaultCurrencyID {
+			return nil, fmt.Errorf("cheese amounts are set on the reward or cost field")
+		}
+		currency, ok := index[a.CurrencyID]
+		if !ok {
+			return nil, fmt.Errorf("currency not found")
+		}
+		normalized = append(normalized, CurrencyAmount{CurrencyID: a.CurrencyID, Amount: a.Amount, Currency: currency})
+	}
+	return normalized, nil
+}
+
+// withCurrencies fills in the Currency field of each amount for display
+func withCurrencies(amounts []CurrencyAmount, index map[int64]*Currency) []CurrencyAmount {
+	var filled []CurrencyAmount
+	for _, a := range amounts {
+		currency, ok := index[a.CurrencyID]
+		if !ok {
+			continue
+		}
+		a.Currency = currency
+		filled = append(filled, a)
+	}
+	return filled
+}
+
+// loadTaskRewards attaches extra-currency rewards to tasks of one group
+func (s *Service) loadTaskRewards(groupID int64, tasks ...*Task) error {
+	index, err := s.currencyIndex(groupID)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		rewards, err := s.store.GetTaskRewards(task.ID)
+		if err != nil {
+			return err
+		}
+		task.ExtraRewards = withCurrencies(rewards, index)
+	}
+	return nil
+}
+
+// loadShopItemPrices attaches extra-currency prices to shop items of one group
+func (s *Service) loadShopItemPrices(groupID int64, items ...*ShopItem) error {
+	index, err := s.currencyIndex(groupID)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		prices, err := s.store.GetShopItemPrices(item.ID)
+		if err != nil {
+			return err
+		}
+		item.ExtraPrices = withCurrencies(prices, index)
+	}
+	return nil
+}
+
+// GetExchangeRules retrieves a group's exchange rules with their currencies filled in
+func (s *Service) GetExchangeRules(groupID int64) ([]*ExchangeRule, error) {
+	rules, err := s.store.GetExchangeRulesByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	index, err := s.currencyIndex(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	var valid []*ExchangeRule
+	for _, rule := range rules {
+		rule.FromCurrency = index[rule.FromCurrencyID]
+		rule.ToCurrency = index[rule.ToCurrencyID]
+		if rule.FromCurrency == nil || rule.ToCurrency == nil {
+			continue
+		}
+		valid = append(valid, rule)
+	}
+	return valid, nil
+}
+
+// CreateExchangeRule lets the owner define how currencies can be traded
+func (s *Service) CreateExchangeRule(userID, groupID, fromCurrencyID int64, fromAmount int, toCurrencyID int64, toAmount int) (*ExchangeRule, error) {
+	if _, err := s.requireGroupOwner(userID, groupID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureGroupWritable(groupID); err != nil {
+		return nil, err
+	}
+	if fromAmount <= 0 || toAmount <= 0 {
+		return nil, fmt.Errorf("exchange amounts must be positive")
+	}
+	if fromCurrencyID == toCurrencyID {
+		return nil, fmt.Errorf("cannot exchange a currency for itself")
+	}
+
+	index, err := s.currencyIndex(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if index[fromCurrencyID] == nil || index[toCurrencyID] == nil {
+		return nil, fmt.Errorf("currency not found")
+	}
+
+	return s.store.CreateExchangeRule(groupID, fromCurrencyID, fromAmount, toCurrencyID, toAmount)
+}
+
+// DeleteExchangeRule removes an exchange rule (owner only)
+func (s *Service) DeleteExchangeRule(userID, ruleID int64) error {
+	rule, err := s.store.GetExchangeRuleByID(ruleID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.requireGroupOwner(userID, rule.GroupID); err != nil {
+		return err
+	}
+	if err := s.ensureGroupWritable(rule.GroupID); err != nil {
+		return err
+	}
+	return s.store.DeleteExchangeRule(ruleID)
+}
+
+// ExchangeCurrency trades currency once according to an exchange rule.
+// The debit is the main transaction and the credit is linked to it, so
+// undoing the exchange reverses both legs.
+func (s *Service) ExchangeCurrency(userID, ruleID int64) (*Transaction, error) {
+	rule, err := s.store.GetExchangeRuleByID(ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember, err := s.store.IsUserInGroup(userID, rule.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, fmt.Errorf("user is not a member of this group")
+	}
+	if err := s.ensureGroupWritable(rule.GroupID); err != nil {
+		return nil, err
+	}
+
+	index, err := s.currencyIndex(rule.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	from, to := index[rule.FromCurrencyID], index[rule.ToCurrencyID]
+	if from == nil || to == nil {
+		return nil, fmt.Errorf("currency not found")
+	}
+
+	var balance int
+	if rule.FromCurrencyID == DefaultCurrencyID {
+		// Cheese reserved for wishlist goals cannot be exchanged
+		balance, err = s.availableBalance(userID, rule.GroupID, 0)
+	} else {
+		balance, err = s.store.GetCurrencyBalance(userID, rule.GroupID, rule.FromCurrencyID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+	if balance < rule.FromAmount {
+		return nil, fmt.Errorf("insufficient %s: have %d, need %d", from.Name, balance, rule.FromAmount)
+	}
+
+	description := fmt.Sprintf("%s %d → %s %d", from.Emoji, rule.FromAmount, to.Emoji, rule.ToAmount)
+	debit, err := s.store.CreateCurrencyTransaction(userID, rule.GroupID, rule.FromCurrencyID, -rule.FromAmount, SourceTypeExchange, &rule.ID, nil, 1, description, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	if _, err := s.store.CreateCurrencyTransaction(userID, rule.GroupID, rule.ToCurrencyID, rule.ToAmount, SourceTypeExchange, &rule.ID, &debit.ID, 1, description, ""); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	return debit, nil
+}
@@ -0,0 +1,556 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parts of the day a phrase like "tomorrow evening" resolves to
+const (
+	afternoonHour = 14
+	eveningHour   = 19
+	tonightHour   = 20
+)
+
+// naturalUnits maps the duration words of both languages to their length.
+// Days and weeks are kept apart so "in 3 days at 9am" can still pick the hour.
+var naturalUnits = map[string]time.Duration{
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"мин": time.Minute, "минута": time.Minute, "минуту": time.Minute, "минуты": time.Minute, "минут": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"ч": time.Hour, "час": time.Hour, "часа": time.Hour, "часов": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"д": 24 * time.Hour, "день": 24 * time.Hour, "дня": 24 * time.Hour, "дней": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "wk": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	"неделя": 7 * 24 * time.Hour, "неделю": 7 * 24 * time.Hour, "недели": 7 * 24 * time.Hour, "недель": 7 * 24 * time.Hour,
+}
+
+// naturalAmounts are the spelled-out numbers accepted before a unit
+var naturalAmounts = map[string]float64{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10, "couple": 2,
+	"один": 1, "одну": 1, "одна": 1, "два": 2, "две": 2, "пару": 2, "пара": 2,
+	"три": 3, "четыре": 4, "пять": 5, "шесть": 6, "семь": 7, "восемь": 8,
+	"девять": 9, "десять": 10, "полтора": 1.5, "полторы": 1.5,
+}
+
+var naturalWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+	"воскресенье": time.Sunday, "вс": time.Sunday,
+	"понедельник": time.Monday, "пн": time.Monday,
+	"вторник": time.Tuesday, "вт": time.Tuesday,
+	"среда": time.Wednesday, "среду": time.Wednesday, "ср": time.Wednesday,
+	"четверг": time.Thursday, "чт": time.Thursday,
+	"пятница": time.Friday, "пятницу": time.Friday, "пт": time.Friday,
+	"суббота": time.Saturday, "субботу": time.Saturday, "сб": time.Saturday,
+}
+
+var naturalMonths = map[string]time.Month{
+	"january": time.January, "jan": time.January, "января": time.January, "янв": time.January,
+	"february": time.February, "feb": time.February, "февраля": time.February, "фев": time.February,
+	"march": time.March, "mar": time.March, "марта": time.March, "мар": time.March,
+	"april": time.April, "apr": time.April, "апреля": time.April, "апр": time.April,
+	"may": time.May, "мая": time.May,
+	"june": time.June, "jun": time.June, "июня": time.June, "июн": time.June,
+	"july": time.July, "jul": time.July, "июля": time.July, "июл": time.July,
+	"august": time.August, "aug": time.August, "августа": time.August, "авг": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September, "сентября": time.September, "сен": time.September,
+	"october": time.October, "oct": time.October, "октября": time.October, "окт": time.October,
+	"november": time.November, "nov": time.November, "ноября": time.November, "ноя": time.November,
+	"december": time.December, "dec": time.December, "декабря": time.December, "дек": time.December,
+}
+
+// naturalDayParts are words that set a time of day on their own
+var naturalDayParts = map[string]int{
+	"morning": MorningHour, "утром": MorningHour,
+	"noon": 12, "полдень": 12,
+	"afternoon": afternoonHour, "днем": afternoonHour,
+	"evening": eveningHour, "вечером": eveningHour,
+	"tonight": tonightHour,
+}
+
+// naturalFillers carry no meaning of their own
+var naturalFillers = map[string]bool{
+	"on": true, "the": true, "this": true, "next": true, "of": true, "and": true,
+	"на": true, "этот": true, "эту": true, "это": true, "и": true,
+	"следующий": true, "следующую": true, "следующее": true, "следующей": true,
+}
+
+// naturalClockPrepositions announce a time: "at 9", "в 10", "к 18:00"
+var naturalClockPrepositions = map[string]bool{
+	"at": true, "by": true, "в": true, "во": true, "к": true, "до": true,
+}
+
+var (
+	clockPattern   = regexp.MustCompile(`^(\d{1,2}):(\d{2})(am|pm)?$`)
+	meridiemHour   = regexp.MustCompile(`^(\d{1,2})(am|pm)$`)
+	numberPattern  = regexp.MustCompile(`^\d+(\.\d+)?$`)
+	compactPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)([a-zа-я]+)$`)
+	ordinalPattern = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)$`)
+	isoDatePattern = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})(?:t(\d{2}):(\d{2}))?$`)
+	dotDatePattern = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})(?:\.(\d{2}|\d{4}))?$`)
+	decimalComma   = regexp.MustCompile(`(\d),(\d)`)
+)
+
+// naturalTime collects what a phrase says about the date and time
+type naturalTime struct {
+	now    time.Time // in the user's timezone
+	tokens []string
+	pos    int
+
+	date     *time.Time // midnight of an explicit day
+	hasClock bool
+	hour     int
+	minute   int
+	// days and offset come from relative phrases like "in 3 days" or "in 2 hours"
+	days   int
+	offset time.Duration
+}
+
+// ParseNaturalTime interprets a date typed by a user in English or Russian,
+// such as "tomorrow 9am", "in 2 hours", "next friday", "завтра в 10" or
+// "через час", relative to now in the user's timezone. The "2006-01-02T15:04"
+// format of date inputs is accepted too. A day without a time means
+// MorningHour; a time without a day means its next occurrence.
+func ParseNaturalTime(input string, now time.Time, loc *time.Location) (time.Time, error) {
+	p := &naturalTime{now: now.In(loc), tokens: tokenizeNatural(input)}
+	if len(p.tokens) == 0 {
+		return time.Time{}, fmt.Errorf("please enter a date or time")
+	}
+
+	for p.pos < len(p.tokens) {
+		if err := p.parseNext(); err != nil {
+			return time.Time{}, fmt.Errorf("could not understand %q: %w", strings.TrimSpace(input), err)
+		}
+	}
+
+	t, err := p.resolve(loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not understand %q: %w", strings.TrimSpace(input), err)
+	}
+	return t, nil
+}
+
+// tokenizeNatural lowercases the phrase and splits it into words
+func tokenizeNatural(input string) []string {
+	s := strings.ToLower(strings.TrimSpace(input))
+	s = strings.ReplaceAll(s, "ё", "е")
+	s = strings.ReplaceAll(s, "a.m.", "am")
+	s = strings.ReplaceAll(s, "p.m.", "pm")
+	s = decimalComma.ReplaceAllString(s, "$1.$2")
+	s = strings.NewReplacer(",", " ", ";", " ").Replace(s)
+	return strings.Fields(s)
+}
+
+func (p *naturalTime) peek(offset int) string {
+	if p.pos+offset < len(p.tokens) {
+		return p.tokens[p.pos+offset]
+	}
+	return ""
+}
+
+// parseNext consumes the phrase part starting at the current token
+func (p *naturalTime) parseNext() error {
+	tok := p.peek(0)
+
+	switch {
+	case tok == "in" || tok == "через":
+		p.pos++
+		if !p.parseDurations() {
+			return fmt.Errorf("expected a duration after %q", tok)
+		}
+		return nil
+	case naturalClockPrepositions[tok]:
+		// "в 10" is a clock time; "в пятницу" or "at noon" are read on their own
+		p.pos++
+		p.parseClock(true)
+		return nil
+	case tok == "week" || tok == "неделе":
+		// "next week", "на следующей неделе": Monday of next week
+		if p.pos == 0 || !isNextWord(p.tokens[p.pos-1]) {
+			return fmt.Errorf("unexpected %q", tok)
+		}
+		p.pos++
+		daysAhead := (int(time.Monday-p.now.Weekday())+6)%7 + 1
+		return p.setDate(p.today().AddDate(0, 0, daysAhead))
+	case naturalFillers[tok]:
+		p.pos++
+		return nil
+	}
+
+	if p.parseDay() || p.parseDate() || p.parseDurations() || p.parseClock(false) {
+		return nil
+	}
+	if hour, ok := naturalDayParts[tok]; ok {
+		p.pos++
+		if tok == "tonight" && p.date == nil {
+			if err := p.setDate(p.today()); err != nil {
+				return err
+			}
+		}
+		return p.setClock(hour, 0)
+	}
+	return fmt.Errorf("unknown word %q", tok)
+}
+
+func isNextWord(tok string) bool {
+	return tok == "next" || strings.HasPrefix(tok, "следующ")
+}
+
+func (p *naturalTime) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+}
+
+func (p *naturalTime) setDate(d time.Time) error {
+	if p.date != nil {
+		return fmt.Errorf("more than one day given")
+	}
+	p.date = &d
+	return nil
+}
+
+func (p *naturalTime) setClock(hour, minute int) error {
+	if p.hasClock {
+		return fmt.Errorf("more than one time given")
+	}
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return fmt.Errorf("invalid time %d:%02d", hour, minute)
+	}
+	p.hasClock, p.hour, p.minute = true, hour, minute
+	return nil
+}
+
+// parseDay handles today, tomorrow, the day after tomorrow and weekdays
+func (p *naturalTime) parseDay() bool {
+	tok := p.peek(0)
+	today := p.today()
+	if p.date != nil {
+		return false
+	}
+
+	switch {
+	case tok == "today" || tok == "сегодня":
+		p.pos++
+		return p.setDate(today) == nil
+	case tok == "tomorrow" || tok == "завтра":
+		p.pos++
+		return p.setDate(today.AddDate(0, 0, 1)) == nil
+	case tok == "послезавтра":
+		p.pos++
+		return p.setDate(today.AddDate(0, 0, 2)) == nil
+	case tok == "day" && p.peek(1) == "after" && p.peek(2) == "tomorrow":
+		p.pos += 3
+		return p.setDate(today.AddDate(0, 0, 2)) == nil
+	}
+
+	weekday, ok := naturalWeekdays[tok]
+	if !ok {
+		return false
+	}
+	p.pos++
+	// Always the coming one: "friday" on a Friday means a week from today
+	daysAhead := (int(weekday-p.now.Weekday())+6)%7 + 1
+	return p.setDate(today.AddDate(0, 0, daysAhead)) == nil
+}
+
+// parseDate handles "2026-10-20", "20.10", "20.10.2026", "oct 20" and "20 октября"
+func (p *naturalTime) parseDate() bool {
+	tok := p.peek(0)
+	if p.date != nil {
+		return false
+	}
+
+	if m := isoDatePattern.FindStringSubmatch(tok); m != nil && !(m[4] != "" && p.hasClock) {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		if !p.setCalendarDate(year, time.Month(month), day) {
+			return false
+		}
+		p.pos++
+		if m[4] != "" {
+			hour, _ := strconv.Atoi(m[4])
+			minute, _ := strconv.Atoi(m[5])
+			return p.setClock(hour, minute) == nil
+		}
+		return true
+	}
+
+	if m := dotDatePattern.FindStringSubmatch(tok); m != nil {
+		day, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		year := 0
+		if m[3] != "" {
+			year, _ = strconv.Atoi(m[3])
+			if year < 100 {
+				year += 2000
+			}
+		}
+		if !p.setCalendarDate(year, time.Month(month), day) {
+			return false
+		}
+		p.pos++
+		return true
+	}
+
+	// "oct 20", "october 20th"
+	if month, ok := naturalMonths[tok]; ok {
+		if day, ok := naturalDayNumber(p.peek(1)); ok {
+			if !p.setCalendarDate(p.yearAfter(2), month, day) {
+				return false
+			}
+			p.pos += 2
+			p.skipYear()
+			return true
+		}
+		return false
+	}
+
+	// "20 oct", "20 октября"
+	if day, ok := naturalDayNumber(tok); ok {
+		if month, ok := naturalMonths[p.peek(1)]; ok {
+			if !p.setCalendarDate(p.yearAfter(2), month, day) {
+				return false
+			}
+			p.pos += 2
+			p.skipYear()
+			return true
+		}
+	}
+	return false
+}
+
+// naturalDayNumber reads a day of month such as "20" or "20th"
+func naturalDayNumber(tok string) (int, bool) {
+	if m := ordinalPattern.FindStringSubmatch(tok); m != nil {
+		tok = m[1]
+	}
+	day, err := strconv.Atoi(tok)
+	if err != nil || day < 1 || day > 31 {
+		return 0, false
+	}
+	return day, true
+}
+
+// yearAfter returns the four-digit year following a month phrase, or 0
+func (p *naturalTime) yearAfter(offset int) int {
+	year, err := strconv.Atoi(p.peek(offset))
+	if err != nil || year < 1000 {
+		return 0
+	}
+	return year
+}
+
+func (p *naturalTime) skipYear() {
+	if p.date != nil && p.peek(0) == strconv.Itoa(p.date.Year()) {
+		p.pos++
+	}
+}
+
+// setCalendarDate sets an explicit date; without a year it is the next such date
+func (p *naturalTime) setCalendarDate(year int, month time.Month, day int) bool {
+	if month < time.January || month > time.December {
+		return false
+	}
+	explicitYear := year != 0
+	if !explicitYear {
+		year = p.now.Year()
+	}
+	d := time.Date(year, month, day, 0, 0, 0, 0, p.now.Location())
+	if d.Day() != day {
+		return false // e.g. 31.02
+	}
+	if !explicitYear && d.Before(p.today()) {
+		d = d.AddDate(1, 0, 0)
+	}
+	return p.setDate(d) == nil
+}
+
+// parseDurations handles one or more "<amount> <unit>" pairs such as
+// "2 hours", "an hour and 30 minutes", "полчаса" or "2h"
+func (p *naturalTime) parseDurations() bool {
+	parsed := false
+	for {
+		skip := 0
+		if parsed && (p.peek(0) == "and" || p.peek(0) == "и") {
+			skip = 1
+		}
+		amount, unit, ok := p.durationAt(skip)
+		if !ok {
+			return parsed
+		}
+		p.addDuration(amount, unit)
+		parsed = true
+	}
+}
+
+// durationAt reads one duration starting skip tokens ahead and consumes it
+func (p *naturalTime) durationAt(skip int) (float64, time.Duration, bool) {
+	tok, next := p.peek(skip), p.peek(skip+1)
+	consume := func(n int, amount float64, unit time.Duration) (float64, time.Duration, bool) {
+		p.pos += skip + n
+		return amount, unit, true
+	}
+
+	switch {
+	case tok == "полчаса":
+		return consume(1, 30, time.Minute)
+	case tok == "half" && (next == "an" || next == "a") && naturalUnits[p.peek(skip+2)] != 0:
+		return consume(3, 0.5, naturalUnits[p.peek(skip+2)])
+	case tok == "a" && next == "couple" && p.peek(skip+2) == "of" && naturalUnits[p.peek(skip+3)] != 0:
+		return consume(4, 2, naturalUnits[p.peek(skip+3)])
+	}
+
+	if m := compactPattern.FindStringSubmatch(tok); m != nil {
+		if unit, ok := naturalUnits[m[2]]; ok {
+			amount, _ := strconv.ParseFloat(m[1], 64)
+			return consume(1, amount, unit)
+		}
+	}
+
+	amount, hasAmount := naturalAmounts[tok]
+	if numberPattern.MatchString(tok) {
+		amount, _ = strconv.ParseFloat(tok, 64)
+		hasAmount = true
+	}
+	if hasAmount {
+		if unit, ok := naturalUnits[next]; ok {
+			return consume(2, amount, unit)
+		}
+	} else if unit, ok := naturalUnits[tok]; ok && len([]rune(tok)) > 1 {
+		// "через час", "in a week" without a number; single letters need one
+		return consume(1, 1, unit)
+	}
+
+	return 0, 0, false
+}
+
+// addDuration keeps whole days and weeks apart from the rest, so a clock time
+// can still apply to them and they follow the calendar across DST changes
+func (p *naturalTime) addDuration(amount float64, unit time.Duration) {
+	if unit >= 24*time.Hour {
+		days := amount * float64(unit/(24*time.Hour))
+		if days == float64(int(days)) {
+			p.days += int(days)
+			return
+		}
+	}
+	p.offset += time.Duration(amount * float64(unit))
+}
+
+// parseClock reads "9am", "9:30", "21:00", "9 pm" or "10 утра". A bare number
+// counts as an hour only on its own, after "at"/"в", before a meridiem or
+// after a day.
+func (p *naturalTime) parseClock(afterPreposition bool) bool {
+	start, tok := p.pos, p.peek(0)
+	hour, minute := -1, 0
+	meridiem := ""
+
+	if m := clockPattern.FindStringSubmatch(tok); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+		minute, _ = strconv.Atoi(m[2])
+		meridiem = m[3]
+	} else if m := meridiemHour.FindStringSubmatch(tok); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+		meridiem = m[2]
+	} else if n, err := strconv.Atoi(tok); err == nil && len(tok) <= 2 {
+		alone := len(p.tokens) == 1
+		if !afterPreposition && !alone && !isMeridiem(p.peek(1)) && p.date == nil && p.days == 0 {
+			return false
+		}
+		hour = n
+	} else {
+		return false
+	}
+	p.pos++
+
+	// "в 10 часов", "at 9 o'clock"
+	if next := p.peek(0); next == "o'clock" || next == "час" || next == "часа" || next == "часов" {
+		p.pos++
+	}
+	if meridiem == "" && isMeridiem(p.peek(0)) {
+		meridiem = p.peek(0)
+		p.pos++
+	}
+
+	hour, ok := applyMeridiem(hour, meridiem)
+	if !ok || p.setClock(hour, minute) != nil {
+		p.pos = start
+		return false
+	}
+	return true
+}
+
+func isMeridiem(tok string) bool {
+	switch tok {
+	case "am", "pm", "утра", "дня", "вечера", "ночи":
+		return true
+	}
+	return false
+}
+
+// applyMeridiem turns a 12-hour clock reading into 0-23
+func applyMeridiem(hour int, meridiem string) (int, bool) {
+	switch meridiem {
+	case "":
+		return hour, hour >= 0 && hour <= 23
+	case "am", "утра", "ночи":
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		return hour % 12, true
+	default: // pm, дня, вечера
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		return hour%12 + 12, true
+	}
+}
+
+// resolve combines the parsed parts into a point in time
+func (p *naturalTime) resolve(loc *time.Location) (time.Time, error) {
+	if p.offset != 0 {
+		// "in 2 hours" counts from now and leaves no room for a clock time
+		if p.date != nil || p.hasClock {
+			return time.Time{}, fmt.Errorf("a duration in hours or minutes cannot be combined with a day or time")
+		}
+		return p.now.AddDate(0, 0, p.days).Add(p.offset), nil
+	}
+
+	day := p.today()
+	switch {
+	case p.date != nil && p.days != 0:
+		return time.Time{}, fmt.Errorf("a duration cannot be combined with a day")
+	case p.date != nil:
+		day = *p.date
+	case p.days != 0:
+		day = day.AddDate(0, 0, p.days)
+	}
+
+	if !p.hasClock {
+		switch {
+		case p.date != nil:
+			return time.Date(day.Year(), day.Month(), day.Day(), MorningHour, 0, 0, 0, loc), nil
+		case p.days != 0:
+			// "in 3 days" keeps the current time of day
+			return p.now.AddDate(0, 0, p.days), nil
+		default:
+			return time.Time{}, fmt.Errorf("no date or time found")
+		}
+	}
+
+	t := time.Date(day.Year(), day.Month(), day.Day(), p.hour, p.minute, 0, 0, loc)
+	if p.date == nil && p.days == 0 && !t.After(p.now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,216 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNaturalTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, berlin)
+	}
+	// Wednesday afternoon
+	now := at(time.October, 14, 15, 30)
+
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		// English days and times
+		{"tomorrow 9am", at(time.October, 15, 9, 0)},
+		{"Tomorrow at 9:30 PM", at(time.October, 15, 21, 30)},
+		{"tomorrow", at(time.October, 15, MorningHour, 0)},
+		{"tomorrow morning", at(time.October, 15, MorningHour, 0)},
+		{"tomorrow evening", at(time.October, 15, 19, 0)},
+		{"at noon tomorrow", at(time.October, 15, 12, 0)},
+		{"today 17:00", at(time.October, 14, 17, 0)},
+		{"tonight", at(time.October, 14, 20, 0)},
+		{"day after tomorrow", at(time.October, 16, MorningHour, 0)},
+		{"9pm", at(time.October, 14, 21, 0)},
+		{"9 p.m.", at(time.October, 14, 21, 0)},
+		{"9am", at(time.October, 15, 9, 0)},
+		{"18:45", at(time.October, 14, 18, 45)},
+		{"12am", at(time.October, 15, 0, 0)},
+		{"12pm", at(time.October, 15, 12, 0)},
+		{"noon", at(time.October, 15, 12, 0)},
+		{"20", at(time.October, 14, 20, 0)},
+		{"9", at(time.October, 15, 9, 0)},
+
+		// English relative
+		{"in 2 hours", at(time.October, 14, 17, 30)},
+		{"in 30 min", at(time.October, 14, 16, 0)},
+		{"in an hour and 30 minutes", at(time.October, 14, 17, 0)},
+		{"in half an hour", at(time.October, 14, 16, 0)},
+		{"in a couple of hours", at(time.October, 14, 17, 30)},
+		{"in 1.5 hours", at(time.October, 14, 17, 0)},
+		{"2h", at(time.October, 14, 17, 30)},
+		{"in 45m", at(time.October, 14, 16, 15)},
+		{"in 3 days", at(time.October, 17, 15, 30)},
+		{"in 3 days at 9am", at(time.October, 17, 9, 0)},
+		{"in a week", at(time.October, 21, 15, 30)},
+
+		// English weekdays and dates
+		{"next friday", at(time.October, 16, MorningHour, 0)},
+		{"friday 5pm", at(time.October, 16, 17, 0)},
+		{"on Fri, at 17:00", at(time.October, 16, 17, 0)},
+		{"wednesday", at(time.October, 21, MorningHour, 0)},
+		{"mon at 10", at(time.October, 19, 10, 0)},
+		{"next week", at(time.October, 19, MorningHour, 0)},
+		{"oct 20", at(time.October, 20, MorningHour, 0)},
+		{"October 20th at 3pm", at(time.October, 20, 15, 0)},
+		{"20 oct 2027", time.Date(2027, time.October, 20, MorningHour, 0, 0, 0, berlin)},
+		{"3 jan", time.Date(2027, time.January, 3, MorningHour, 0, 0, 0, berlin)},
+		{"2026-10-20", at(time.October, 20, MorningHour, 0)},
+		{"2026-10-20T15:04", at(time.October, 20, 15, 4)},
+		{"2026-10-20 15:04", at(time.October, 20, 15, 4)},
+		{"20.10", at(time.October, 20, MorningHour, 0)},
+
+		// Russian
+		{"завтра в 10", at(time.October, 15, 10, 0)},
+		{"Завтра в 9 утра", at(time.October, 15, 9, 0)},
+		{"завтра в 7 вечера", at(time.October, 15, 19, 0)},
+		{"завтра утром", at(time.October, 15, MorningHour, 0)},
+		{"сегодня в 2 дня", at(time.October, 14, 14, 0)},
+		{"сегодня вечером", at(time.October, 14, 19, 0)},
+		{"послезавтра", at(time.October, 16, MorningHour, 0)},
+		{"через час", at(time.October, 14, 16, 30)},
+		{"через 2 часа", at(time.October, 14, 17, 30)},
+		{"через полчаса", at(time.October, 14, 16, 0)},
+		{"через полтора часа", at(time.October, 14, 17, 0)},
+		{"через 1,5 часа", at(time.October, 14, 17, 0)},
+		{"через 15 минут", at(time.October, 14, 15, 45)},
+		{"через 2 часа 30 минут", at(time.October, 14, 18, 0)},
+		{"через пару часов", at(time.October, 14, 17, 30)},
+		{"через 3 дня", at(time.October, 17, 15, 30)},
+		{"через 2 дня в 10", at(time.October, 16, 10, 0)},
+		{"через неделю", at(time.October, 21, 15, 30)},
+		{"в пятницу", at(time.October, 16, MorningHour, 0)},
+		{"в следующую пятницу в 18:00", at(time.October, 16, 18, 0)},
+		{"во вторник вечером", at(time.October, 20, 19, 0)},
+		{"в пн", at(time.October, 19, MorningHour, 0)},
+		{"на следующей неделе", at(time.October, 19, MorningHour, 0)},
+		{"20 октября", at(time.October, 20, MorningHour, 0)},
+		{"20.10.2026 в 18:00", at(time.October, 20, 18, 0)},
+		{"в 10 часов", at(time.October, 15, 10, 0)},
+		{"в полдень", at(time.October, 15, 12, 0)},
+		{"утром", at(time.October, 15, MorningHour, 0)},
+		{"в 3 ночи", at(time.October, 15, 3, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseNaturalTime(tt.input, now, berlin)
+			if err != nil {
+				t.Fatalf("ParseNaturalTime(%q) error: %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseNaturalTime(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNaturalTimeErrors(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 15, 30, 0, 0, time.UTC)
+
+	tests := []string{
+		"",
+		"   ",
+		"banana",
+		"in",
+		"in 2 bananas",
+		"через",
+		"at",
+		"25:00",
+		"9:75",
+		"13pm",
+		"32",
+		"31.02",
+		"tomorrow friday",
+		"tomorrow in 2 hours",
+		"9am 10am",
+		"week",
+		"завтра послезавтра",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			if got, err := ParseNaturalTime(input, now, time.UTC); err == nil {
+				t.Errorf("ParseNaturalTime(%q) = %v, want an error", input, got)
+			}
+		})
+	}
+}
+
+func TestParseNaturalTimeZones(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		now   time.Time
+		loc   *time.Location
+		want  time.Time
+	}{
+		{
+			// Already the next day in Tokyo
+			name:  "tomorrow is relative to the user's day",
+			input: "tomorrow 9am",
+			now:   time.Date(2026, time.October, 14, 20, 0, 0, 0, time.UTC),
+			loc:   tokyo,
+			want:  time.Date(2026, time.October, 16, 9, 0, 0, 0, tokyo),
+		},
+		{
+			name:  "today in the user's zone",
+			input: "today",
+			now:   time.Date(2026, time.October, 14, 20, 0, 0, 0, time.UTC),
+			loc:   tokyo,
+			want:  time.Date(2026, time.October, 15, MorningHour, 0, 0, 0, tokyo),
+		},
+		{
+			// Clocks go back overnight; 9am is still 9am local time
+			name:  "across the end of daylight saving time",
+			input: "tomorrow 9am",
+			now:   time.Date(2026, time.October, 24, 12, 0, 0, 0, berlin),
+			loc:   berlin,
+			want:  time.Date(2026, time.October, 25, 9, 0, 0, 0, berlin),
+		},
+		{
+			name:  "whole days keep the wall clock across daylight saving time",
+			input: "in 1 day",
+			now:   time.Date(2026, time.October, 24, 12, 0, 0, 0, berlin),
+			loc:   berlin,
+			want:  time.Date(2026, time.October, 25, 12, 0, 0, 0, berlin),
+		},
+		{
+			name:  "hours are exact across daylight saving time",
+			input: "in 24 hours",
+			now:   time.Date(2026, time.October, 24, 12, 0, 0, 0, berlin),
+			loc:   berlin,
+			want:  time.Date(2026, time.October, 25, 11, 0, 0, 0, berlin),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNaturalTime(tt.input, tt.now, tt.loc)
+			if err != nil {
+				t.Fatalf("ParseNaturalTime(%q) error: %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseNaturalTime(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,622 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GroupDefinitionVersion is the version of the group definition format
+// written by exports. Older versions keep being read.
+const GroupDefinitionVersion = 1
+
+// How an import treats tasks, shop items, currencies and exchange rules that
+// the group already has, matched by title or name ignoring case
+const (
+	ImportSkip      = "skip"      // Keep what the group has
+	ImportReplace   = "replace"   // Overwrite it with the definition
+	ImportDuplicate = "duplicate" // Add tasks and shop items again; currencies and exchange rules are still matched
+)
+
+// GroupDefinition is a portable description of a group's tasks, shop items,
+// currencies and settings, written as YAML or JSON. It is a template: due
+// dates, members and history are not part of it.
+type GroupDefinition struct {
+	Version     int                  `yaml:"version" json:"version"`
+	Name        string               `yaml:"name,omitempty" json:"name,omitempty"`
+	Description string               `yaml:"description,omitempty" json:"description,omitempty"` // Shown when choosing a starter pack
+	Settings    *DefinitionSettings  `yaml:"settings,omitempty" json:"settings,omitempty"`
+	Currencies  []CurrencyDefinition `yaml:"currencies,omitempty" json:"currencies,omitempty"`
+	Exchange    []ExchangeDefinition `yaml:"exchange,omitempty" json:"exchange,omitempty"`
+	Tasks       []TaskDefinition     `yaml:"tasks,omitempty" json:"tasks,omitempty"`
+	Rewards     []ShopItemDefinition `yaml:"rewards,omitempty" json:"rewards,omitempty"`
+}
+
+// DefinitionSettings are the group's economy targets
+type DefinitionSettings struct {
+	WorkDays     int `yaml:"work_days,omitempty" json:"work_days,omitempty"`
+	SpendPercent int `yaml:"spend_percent,omitempty" json:"spend_percent,omitempty"`
+}
+
+// CurrencyDefinition is a group-defined currency
+type CurrencyDefinition struct {
+	Name   string            `yaml:"name" json:"name"`
+	Emoji  string            `yaml:"emoji" json:"emoji"`
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+}
+
+// ExchangeDefinition is an exchange rule between currencies named by their
+// name, "Cheese" for the default currency
+type ExchangeDefinition struct {
+	From       string `yaml:"from" json:"from"`
+	FromAmount int    `yaml:"from_amount" json:"from_amount"`
+	To         string `yaml:"to" json:"to"`
+	ToAmount   int    `yaml:"to_amount" json:"to_amount"`
+}
+
+// TaskDefinition is a task with its deadline policy, minus the deadline itself
+type TaskDefinition struct {
+	Title           string         `yaml:"title" json:"title"`
+	Description     string         `yaml:"description,omitempty" json:"description,omitempty"`
+	Type            TaskType       `yaml:"type,omitempty" json:"type,omitempty"` // boolean when empty
+	Reward          int            `yaml:"reward" json:"reward"`
+	DefaultQuantity int            `yaml:"default_quantity,omitempty" json:"default_quantity,omitempty"`
+	OneTime         bool           `yaml:"one_time,omitempty" json:"one_time,omitempty"`
+	ExtraRewards    map[string]int `yaml:"extra_rewards,omitempty" json:"extra_rewards,omitempty"` // Keyed by currency name
+
+	Penalty            int   `yaml:"penalty,omitempty" json:"penalty,omitempty"`
+	LateRewardPercent  *int  `yaml:"late_reward_percent,omitempty" json:"late_reward_percent,omitempty"` // 100 when unset
+	GraceMinutes       int   `yaml:"grace_minutes,omitempty" json:"grace_minutes,omitempty"`
+	Urgent             bool  `yaml:"urgent,omitempty" json:"urgent,omitempty"`
+	Reminders          []int `yaml:"reminders,omitempty" json:"reminders,omitempty"` // Minutes before the deadline
+	NagIntervalMinutes int   `yaml:"nag_interval_minutes,omitempty" json:"nag_interval_minutes,omitempty"`
+	NagEscalateAfter   int   `yaml:"nag_escalate_after,omitempty" json:"nag_escalate_after,omitempty"`
+}
+
+// ShopItemDefinition is a shop item
+type ShopItemDefinition struct {
+	Title       string         `yaml:"title" json:"title"`
+	Description string         `yaml:"description,omitempty" json:"description,omitempty"`
+	Cost        int            `yaml:"cost,omitempty" json:"cost,omitempty"`
+	OneTime     bool           `yaml:"one_time,omitempty" json:"one_time,omitempty"`
+	ExtraPrices map[string]int `yaml:"extra_prices,omitempty" json:"extra_prices,omitempty"` // Keyed by currency name
+}
+
+// DefinitionImport is everything an import writes, checked and resolved by
+// the service, so the store can apply it in one transaction. New currencies
+// have no ID until the store inserts them, so amounts and exchange rules
+// refer to currencies through their Currency fields.
+type DefinitionImport struct {
+	GroupID              int64
+	Targets              *EconomyTargets // nil keeps the group's targets
+	NewCurrencies        []*Currency
+	UpdatedCurrencies    []*Currency
+	DeletedExchangeRules []int64 // Replaced by rules in NewExchangeRules
+	NewExchangeRules     []*ExchangeRule
+	NewTasks             []*Task // Created without a deadline or start date
+	UpdatedTasks         []*Task
+	NewShopItems         []*ShopItem
+	UpdatedShopItems     []*ShopItem
+}
+
+// ImportResult counts what an import did
+type ImportResult struct {
+	Created int
+	Updated int
+	Skipped int
+}
+
+// ParseGroupDefinition reads a group definition in YAML or JSON, rejecting
+// unknown fields so typos do not go unnoticed
+func ParseGroupDefinition(data []byte) (*GroupDefinition, error) {
+	var def GroupDefinition
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&def); err != nil {
+		return nil, fmt.Errorf("invalid group definition: %w", err)
+	}
+	if def.Version == 0 {
+		return nil, fmt.Errorf("invalid group definition: version is missing")
+	}
+	if def.Version > GroupDefinitionVersion {
+		return nil, fmt.Errorf("group definition version %d is newer than this server supports (%d)", def.Version, GroupDefinitionVersion)
+	}
+	if err := def.validate(); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// Encode writes the definition as "yaml" or "json"
+func (d *GroupDefinition) Encode(format string) ([]byte, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(d, "", "  ")
+		return append(data, '\n'), err
+	case "yaml":
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(d); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), encoder.Close()
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// validate checks everything that does not depend on the target group
+func (d *GroupDefinition) validate() error {
+	if d.Settings != nil {
+		if d.Settings.WorkDays != 0 && (d.Settings.WorkDays < 1 || d.Settings.WorkDays > 365) {
+			return fmt.Errorf("settings: days of work must be between 1 and 365")
+		}
+		if d.Settings.SpendPercent != 0 && (d.Settings.SpendPercent < 1 || d.Settings.SpendPercent > 100) {
+			return fmt.Errorf("settings: spending target must be between 1 and 100 percent")
+		}
+	}
+	names := map[string]bool{}
+	for i, c := range d.Currencies {
+		key := strings.ToLower(strings.TrimSpace(c.Name))
+		if key == "" {
+			return fmt.Errorf("currency %d: name cannot be empty", i+1)
+		}
+		if names[key] || key == strings.ToLower(DefaultCurrency().Name) {
+			return fmt.Errorf("currency %q is defined twice", c.Name)
+		}
+		if strings.TrimSpace(c.Emoji) == "" {
+			return fmt.Errorf("currency %q: emoji cannot be empty", c.Name)
+		}
+		names[key] = true
+	}
+	for _, e := range d.Exchange {
+		if e.FromAmount <= 0 || e.ToAmount <= 0 {
+			return fmt.Errorf("exchange %s → %s: amounts must be positive", e.From, e.To)
+		}
+		if strings.EqualFold(e.From, e.To) {
+			return fmt.Errorf("exchange %s → %s: cannot exchange a currency for itself", e.From, e.To)
+		}
+	}
+	for i, t := range d.Tasks {
+		if strings.TrimSpace(t.Title) == "" {
+			return fmt.Errorf("task %d: title cannot be empty", i+1)
+		}
+		if t.Type != "" && t.Type != TaskTypeBoolean && t.Type != TaskTypeInteger {
+			return fmt.Errorf("task %q: type must be 'boolean' or 'integer'", t.Title)
+		}
+		if t.Reward <= 0 {
+			return fmt.Errorf("task %q: reward must be positive", t.Title)
+		}
+		if err := validateDeadlinePolicy(t.policy()); err != nil {
+			return fmt.Errorf("task %q: %w", t.Title, err)
+		}
+	}
+	for i, r := range d.Rewards {
+		if strings.TrimSpace(r.Title) == "" {
+			return fmt.Errorf("reward %d: title cannot be empty", i+1)
+		}
+		if r.Cost < 0 {
+			return fmt.Errorf("reward %q: cost cannot be negative", r.Title)
+		}
+		if r.Cost == 0 && len(r.ExtraPrices) == 0 {
+			return fmt.Errorf("reward %q: cost must be positive", r.Title)
+		}
+	}
+	return nil
+}
+
+// policy is the deadline policy of a task created from the definition
+func (t TaskDefinition) policy() DeadlinePolicy {
+	policy := DefaultDeadlinePolicy()
+	if t.LateRewardPercent != nil {
+		policy.LateRewardPercent = *t.LateRewardPercent
+	}
+	policy.PenaltyAmount = t.Penalty
+	policy.GraceMinutes = t.GraceMinutes
+	policy.Urgent = t.Urgent
+	policy.ReminderOffsets = t.Reminders
+	policy.NagIntervalMinutes = t.NagIntervalMinutes
+	policy.NagEscalateAfter = t.NagEscalateAfter
+	return policy
+}
+
+// ExportGroupDefinition describes a group's tasks, shop items, currencies and
+// settings for import elsewhere (members only)
+func (s *Service) ExportGroupDefinition(userID, groupID int64) (*GroupDefinition, error) {
+	isMember, err := s.store.IsUserInGroup(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, fmt.Errorf("user is not a member of this group")
+	}
+	group, err := s.store.GetGroupByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	def := &GroupDefinition{Version: GroupDefinitionVersion, Name: group.Name}
+
+	targets, err := s.store.GetEconomyTargets(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if defaults := DefaultEconomyTargets(groupID); *targets != *defaults {
+		def.Settings = &DefinitionSettings{WorkDays: targets.WorkDays, SpendPercent: targets.SpendPercent}
+	}
+
+	currencies, err := s.store.GetCurrenciesByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range currencies {
+		def.Currencies = append(def.Currencies, CurrencyDefinition{Name: c.Name, Emoji: c.Emoji, Labels: c.Labels})
+	}
+	rules, err := s.GetExchangeRules(groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		def.Exchange = append(def.Exchange, ExchangeDefinition{
+			From: rule.FromCurrency.Name, FromAmount: rule.FromAmount,
+			To: rule.ToCurrency.Name, ToAmount: rule.ToAmount,
+		})
+	}
+
+	tasks, err := s.GetTasksByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		t := TaskDefinition{
+			Title:              task.Title,
+			Description:        task.Description,
+			Type:               task.TaskType,
+			Reward:             task.RewardValue,
+			OneTime:            task.IsOneTime,
+			ExtraRewards:       definitionAmounts(task.ExtraRewards),
+			Penalty:            task.Deadline.PenaltyAmount,
+			GraceMinutes:       task.Deadline.GraceMinutes,
+			Urgent:             task.Deadline.Urgent,
+			Reminders:          task.Deadline.ReminderOffsets,
+			NagIntervalMinutes: task.Deadline.NagIntervalMinutes,
+			NagEscalateAfter:   task.Deadline.NagEscalateAfter,
+		}
+		if task.TaskType == TaskTypeInteger {
+			t.DefaultQuantity = task.DefaultQuantity
+		}
+		if task.Deadline.LateRewardPercent != 100 {
+			percent := task.Deadline.LateRewardPercent
+			t.LateRewardPercent = &percent
+		}
+		def.Tasks = append(def.Tasks, t)
+	}
+
+	items, err := s.GetShopItemsByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		def.Rewards = append(def.Rewards, ShopItemDefinition{
+			Title:       item.Title,
+			Description: item.Description,
+			Cost:        item.Cost,
+			OneTime:     item.IsOneTime,
+			ExtraPrices: definitionAmounts(item.ExtraPrices),
+		})
+	}
+
+	return def, nil
+}
+
+// definitionAmounts keys currency amounts by currency name
+func definitionAmounts(amounts []CurrencyAmount) map[string]int {
+	if len(amounts) == 0 {
+		return nil
+	}
+	named := make(map[string]int, len(amounts))
+	for _, a := range amounts {
+		if a.Currency != nil {
+			named[a.Currency.Name] = a.Amount
+		}
+	}
+	return named
+}
+
+// CreateGroupFromDefinition creates a group owned by the user and fills it
+// from a definition, named after it unless name is given
+func (s *Service) CreateGroupFromDefinition(userID int64, name string, def *GroupDefinition) (*Group, *ImportResult, error) {
+	if name = strings.TrimSpace(name); name == "" {
+		name = def.Name
+	}
+	if name == "" {
+		return nil, nil, fmt.Errorf("group name cannot be empty")
+	}
+	if err := def.validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := checkDefinitionCurrencies(def, nil); err != nil {
+		return nil, nil, err
+	}
+
+	group, err := s.CreateGroup(name, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := s.ImportGroupDefinition(userID, group.ID, def, ImportDuplicate)
+	if err != nil {
+		// Do not leave an empty group behind
+		if cleanupErr := s.store.DeleteGroupData(group.ID); cleanupErr != nil {
+			log.Printf("Warning: failed to delete group %d after a failed import: %v", group.ID, cleanupErr)
+		}
+		return nil, nil, err
+	}
+	return group, result, nil
+}
+
+// ImportGroupDefinition adds a definition's currencies, exchange rules,
+// tasks and shop items to an existing group (owner only). conflicts decides
+// what happens to those the group already has; settings are only kept with
+// ImportSkip when the group changed them from the defaults.
+func (s *Service) ImportGroupDefinition(userID, groupID int64, def *GroupDefinition, conflicts string) (*ImportResult, error) {
+	if conflicts != ImportSkip && conflicts != ImportReplace && conflicts != ImportDuplicate {
+		return nil, fmt.Errorf("invalid conflict handling")
+	}
+	if _, err := s.requireGroupOwner(userID, groupID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureGroupWritable(groupID); err != nil {
+		return nil, err
+	}
+	if err := def.validate(); err != nil {
+		return nil, err
+	}
+	existing, err := s.store.GetCurrenciesByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	// Every currency must resolve before anything is written
+	if err := checkDefinitionCurrencies(def, existing); err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	plan := &DefinitionImport{GroupID: groupID}
+
+	if def.Settings != nil {
+		targets, err := s.store.GetEconomyTargets(groupID)
+		if err != nil {
+			return nil, err
+		}
+		if conflicts != ImportSkip || *targets == *DefaultEconomyTargets(groupID) {
+			if def.Settings.WorkDays != 0 {
+				targets.WorkDays = def.Settings.WorkDays
+			}
+			if def.Settings.SpendPercent != 0 {
+				targets.SpendPercent = def.Settings.SpendPercent
+			}
+			plan.Targets = targets
+		}
+	}
+
+	byName := map[string]*Currency{}
+	for _, c := range existing {
+		byName[strings.ToLower(c.Name)] = c
+	}
+	created := map[*Currency]bool{}
+	for _, c := range def.Currencies {
+		name, emoji := strings.TrimSpace(c.Name), strings.TrimSpace(c.Emoji)
+		current, ok := byName[strings.ToLower(name)]
+		switch {
+		case !ok:
+			currency := &Currency{GroupID: groupID, Name: name, Emoji: emoji, Labels: cleanLabels(c.Labels)}
+			plan.NewCurrencies = append(plan.NewCurrencies, currency)
+			byName[strings.ToLower(name)] = currency
+			created[currency] = true
+			result.Created++
+		case conflicts == ImportReplace:
+			updated := *current
+			updated.Name, updated.Emoji, updated.Labels = name, emoji, cleanLabels(c.Labels)
+			plan.UpdatedCurrencies = append(plan.UpdatedCurrencies, &updated)
+			result.Updated++
+		default:
+			result.Skipped++
+		}
+	}
+	// New currencies get their IDs from the store, so amounts and rules
+	// point at the currency itself
+	currency := func(name string) *Currency {
+		if c, ok := byName[strings.ToLower(strings.TrimSpace(name))]; ok {
+			return c
+		}
+		return DefaultCurrency()
+	}
+	amounts := func(named map[string]int) []CurrencyAmount {
+		var list []CurrencyAmount
+		for name, amount := range named {
+			if amount > 0 {
+				c := currency(name)
+				list = append(list, CurrencyAmount{CurrencyID: c.ID, Amount: amount, Currency: c})
+			}
+		}
+		return list
+	}
+
+	rules, err := s.store.GetExchangeRulesByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range def.Exchange {
+		from, to := currency(e.From), currency(e.To)
+		var current *ExchangeRule
+		for _, rule := range rules {
+			if !created[from] && !created[to] && rule.FromCurrencyID == from.ID && rule.ToCurrencyID == to.ID {
+				current = rule
+			}
+		}
+		if current != nil {
+			if conflicts != ImportReplace {
+				result.Skipped++
+				continue
+			}
+			plan.DeletedExchangeRules = append(plan.DeletedExchangeRules, current.ID)
+			result.Updated++
+		} else {
+			result.Created++
+		}
+		plan.NewExchangeRules = append(plan.NewExchangeRules, &ExchangeRule{
+			GroupID: groupID, FromCurrency: from, FromAmount: e.FromAmount, ToCurrency: to, ToAmount: e.ToAmount,
+		})
+	}
+
+	tasks, err := s.store.GetTasksByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	tasksByTitle := map[string]*Task{}
+	for _, task := range tasks {
+		tasksByTitle[strings.ToLower(task.Title)] = task
+	}
+	var previousPolicies []DeadlinePolicy
+	for _, t := range def.Tasks {
+		taskType := t.Type
+		if taskType == "" {
+			taskType = TaskTypeBoolean
+		}
+		defaultQuantity := t.DefaultQuantity
+		if defaultQuantity <= 0 {
+			defaultQuantity = 10 // As for tasks created on the web
+		}
+		current, ok := tasksByTitle[strings.ToLower(strings.TrimSpace(t.Title))]
+		switch {
+		case !ok || conflicts == ImportDuplicate:
+			plan.NewTasks = append(plan.NewTasks, &Task{
+				GroupID: groupID, Title: strings.TrimSpace(t.Title), Description: t.Description, TaskType: taskType,
+				RewardValue: t.Reward, DefaultQuantity: defaultQuantity, IsOneTime: t.OneTime,
+				ExtraRewards: amounts(t.ExtraRewards), Deadline: t.policy(),
+			})
+			result.Created++
+		case conflicts == ImportReplace:
+			// The deadline, start date and partner belong to the group, not the template
+			policy := t.policy()
+			policy.NagPartnerID = current.Deadline.NagPartnerID
+			if !policy.NagEnabled() {
+				policy.NagPartnerID = nil
+			}
+			if err := s.validateNagPartner(groupID, policy); err != nil {
+				return nil, fmt.Errorf("task %q: %w", t.Title, err)
+			}
+			previousPolicies = append(previousPolicies, current.Deadline)
+			updated := *current
+			updated.Description, updated.TaskType, updated.RewardValue = t.Description, taskType, t.Reward
+			updated.DefaultQuantity, updated.IsOneTime = defaultQuantity, t.OneTime
+			updated.ExtraRewards, updated.Deadline = amounts(t.ExtraRewards), policy
+			plan.UpdatedTasks = append(plan.UpdatedTasks, &updated)
+			result.Updated++
+		default:
+			result.Skipped++
+		}
+	}
+
+	items, err := s.store.GetShopItemsByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	itemsByTitle := map[string]*ShopItem{}
+	for _, item := range items {
+		itemsByTitle[strings.ToLower(item.Title)] = item
+	}
+	for _, r := range def.Rewards {
+		current, ok := itemsByTitle[strings.ToLower(strings.TrimSpace(r.Title))]
+		switch {
+		case !ok || conflicts == ImportDuplicate:
+			plan.NewShopItems = append(plan.NewShopItems, &ShopItem{
+				GroupID: groupID, Title: strings.TrimSpace(r.Title), Description: r.Description,
+				Cost: r.Cost, IsOneTime: r.OneTime, ExtraPrices: amounts(r.ExtraPrices),
+			})
+			result.Created++
+		case conflicts == ImportReplace:
+			updated := *current
+			updated.Description, updated.Cost, updated.IsOneTime = r.Description, r.Cost, r.OneTime
+			updated.ExtraPrices = amounts(r.ExtraPrices)
+			plan.UpdatedShopItems = append(plan.UpdatedShopItems, &updated)
+			result.Updated++
+		default:
+			result.Skipped++
+		}
+	}
+
+	// Everything is written at once, so a failure leaves the group untouched
+	if err := s.store.ApplyDefinitionImport(plan); err != nil {
+		return nil, err
+	}
+
+	// Replaced tasks keep their deadline, but its reminders follow the new policy
+	now := time.Now()
+	for i, task := range plan.UpdatedTasks {
+		if !sameReminderOffsets(previousPolicies[i].ReminderOffsets, task.Deadline.ReminderOffsets) {
+			if err := s.rescheduleDeadlineReminders(task, now); err != nil {
+				log.Printf("Warning: failed to reschedule reminders of task %d: %v", task.ID, err)
+			}
+		}
+		if nagPolicyChanged(previousPolicies[i], task.Deadline) {
+			if err := s.rescheduleNags(task, now); err != nil {
+				log.Printf("Warning: failed to reschedule nags of task %d: %v", task.ID, err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// checkDefinitionCurrencies verifies every currency the definition refers to
+// is cheese, defined by it, or one of the group's existing currencies
+func checkDefinitionCurrencies(def *GroupDefinition, existing []*Currency) error {
+	known := map[string]bool{strings.ToLower(DefaultCurrency().Name): true}
+	for _, c := range existing {
+		known[strings.ToLower(c.Name)] = true
+	}
+	for _, c := range def.Currencies {
+		known[strings.ToLower(strings.TrimSpace(c.Name))] = true
+	}
+	check := func(where, name string, allowCheese bool) error {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if !known[key] {
+			return fmt.Errorf("%s: unknown currency %q", where, name)
+		}
+		if !allowCheese && key == strings.ToLower(DefaultCurrency().Name) {
+			return fmt.Errorf("%s: cheese amounts are set on the reward or cost field", where)
+		}
+		return nil
+	}
+
+	for _, e := range def.Exchange {
+		where := fmt.Sprintf("exchange %s → %s", e.From, e.To)
+		if err := check(where, e.From, true); err != nil {
+			return err
+		}
+		if err := check(where, e.To, true); err != nil {
+			return err
+		}
+	}
+	for _, t := range def.Tasks {
+		for name := range t.ExtraRewards {
+			if err := check(fmt.Sprintf("task %q", t.Title), name, false); err != nil {
+				return err
+			}
+		}
+	}
+	for _, r := range def.Rewards {
+		for name := range r.ExtraPrices {
+			if err := check(fmt.Sprintf("reward %q", r.Title), name, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGroupDefinition(t *testing.T) {
+	def, err := ParseGroupDefinition([]byte(`
+version: 1
+name: Home
+currencies:
+  - name: Stars
+    emoji: ⭐
+tasks:
+  - title: Dishes
+    reward: 10
+    late_reward_percent: 50
+    extra_rewards:
+      Stars: 1
+  - title: Pages
+    type: integer
+    reward: 1
+rewards:
+  - title: Movie
+    extra_prices:
+      stars: 3
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if def.Name != "Home" || len(def.Tasks) != 2 || len(def.Rewards) != 1 {
+		t.Fatalf("unexpected definition: %+v", def)
+	}
+	if policy := def.Tasks[0].policy(); policy.LateRewardPercent != 50 {
+		t.Errorf("late reward = %d, want 50", policy.LateRewardPercent)
+	}
+	if policy := def.Tasks[1].policy(); policy.LateRewardPercent != 100 {
+		t.Errorf("default late reward = %d, want 100", policy.LateRewardPercent)
+	}
+	if err := checkDefinitionCurrencies(def, nil); err != nil {
+		t.Errorf("currencies: %v", err)
+	}
+
+	// Exports read back unchanged
+	for _, format := range []string{"yaml", "json"} {
+		data, err := def.Encode(format)
+		if err != nil {
+			t.Fatalf("encode %s: %v", format, err)
+		}
+		again, err := ParseGroupDefinition(data)
+		if err != nil || !reflect.DeepEqual(again, def) {
+			t.Errorf("%s round trip: %+v, %v", format, again, err)
+		}
+	}
+
+	// JSON is read the same way
+	def, err = ParseGroupDefinition([]byte(`{"version": 1, "tasks": [{"title": "Dishes", "reward": 10}]}`))
+	if err != nil || len(def.Tasks) != 1 {
+		t.Fatalf("json: %+v, %v", def, err)
+	}
+}
+
+func TestParseGroupDefinitionErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`tasks: []`, "version is missing"},
+		{`version: 99`, "newer"},
+		{`version: 1
+tasks:
+  - title: Dishes
+    reward: 10
+    rewrad: 5`, "rewrad"},
+		{`version: 1
+tasks:
+  - title: Dishes`, "reward must be positive"},
+		{`version: 1
+tasks:
+  - title: Dishes
+    type: daily
+    reward: 1`, "type"},
+		{`version: 1
+rewards:
+  - title: Movie`, "cost must be positive"},
+		{`version: 1
+currencies:
+  - name: Cheese
+    emoji: 🧀`, "defined twice"},
+		{`version: 1
+currencies:
+  - name: Stars`, "emoji"},
+		{`version: 1
+exchange:
+  - from: Stars
+    from_amount: 0
+    to: Cheese
+    to_amount: 1`, "positive"},
+	}
+	for _, tt := range tests {
+		_, err := ParseGroupDefinition([]byte(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseGroupDefinition(%q) error = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestCheckDefinitionCurrencies(t *testing.T) {
+	def := &GroupDefinition{Tasks: []TaskDefinition{{Title: "Dishes", Reward: 1, ExtraRewards: map[string]int{"Stars": 1}}}}
+	if err := checkDefinitionCurrencies(def, nil); err == nil || !strings.Contains(err.Error(), "unknown currency") {
+		t.Errorf("unknown currency error = %v", err)
+	}
+	if err := checkDefinitionCurrencies(def, []*Currency{{Name: "stars"}}); err != nil {
+		t.Errorf("existing currency: %v", err)
+	}
+	def.Tasks[0].ExtraRewards = map[string]int{"cheese": 1}
+	if err := checkDefinitionCurrencies(def, nil); err == nil {
+		t.Errorf("cheese as extra reward should fail")
+	}
+}
+
+func TestStarterPacks(t *testing.T) {
+	packs, err := LoadStarterPacks("../../packs")
+	if err != nil {
+		t.Fatalf("LoadStarterPacks: %v", err)
+	}
+	if len(packs) == 0 {
+		t.Fatal("no starter packs found")
+	}
+	for _, pack := range packs {
+		for locale, def := range pack.Definitions {
+			if def.Name == "" || len(def.Tasks) == 0 {
+				t.Errorf("pack %s.%s is empty", pack.ID, locale)
+			}
+			if err := checkDefinitionCurrencies(def, nil); err != nil {
+				t.Errorf("pack %s.%s: %v", pack.ID, locale, err)
+			}
+		}
+		if pack.Definition("xx") != pack.Definitions["en"] {
+			t.Errorf("pack %s does not fall back to English", pack.ID)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// NotificationMaxAttempts is how often delivery of a notification is tried
+// before it is marked failed
+const NotificationMaxAttempts = 6
+
+// Wait after the first failed attempt; it doubles with every further attempt
+const (
+	notificationRetryBase = 5 * time.Minute
+	notificationRetryMax  = 4 * time.Hour
+)
+
+// ErrRecipientBlocked is returned by a channel when the user blocked the bot
+// or their address stopped working for good. When it happens on every
+// channel, retrying cannot help, so notifications are switched off for the
+// user until they turn them back on.
+var ErrRecipientBlocked = errors.New("recipient blocked or unreachable")
+
+// notificationBackoff returns the wait before the next attempt after the
+// given number of failed attempts: 5m, 10m, 20m, ... up to 4h
+func notificationBackoff(attempts int) time.Duration {
+	delay := notificationRetryBase
+	for i := 1; i < attempts && delay < notificationRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > notificationRetryMax {
+		delay = notificationRetryMax
+	}
+	return delay
+}
+
+// recordDeliveryFailure schedules a retry of a notification that could not
+// be sent, or gives up on it when retrying cannot help or attempts ran out
+func (s *Service) recordDeliveryFailure(notif *TaskNotification, sendErr error, now time.Time) error {
+	attempts := notif.Attempts + 1
+
+	switch {
+	case errors.Is(sendErr, ErrRecipientBlocked):
+		if err := s.store.SetNotificationEnabled(notif.UserID, false); err != nil {
+			log.Printf("Failed to disable notifications of user %d: %v", notif.UserID, err)
+		}
+		return s.store.MarkNotificationFailed(notif.ID, sendErr.Error(), now)
+	case errors.Is(sendErr, ErrNoRecipient), attempts >= NotificationMaxAttempts:
+		return s.store.MarkNotificationFailed(notif.ID, sendErr.Error(), now)
+	}
+
+	return s.store.ScheduleNotificationRetry(notif.ID, sendErr.Error(), now.Add(notificationBackoff(attempts)))
+}
+
+// GetFailedNotifications retrieves the latest notifications that were given
+// up on, with their task and recipient
+func (s *Service) GetFailedNotifications(limit int) ([]*FailedNotification, error) {
+	notifications, err := s.store.GetFailedNotifications(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := make([]*FailedNotification, 0, len(notifications))
+	for _, notif := range notifications {
+		entry := &FailedNotification{Notification: notif}
+		if task, err := s.store.GetTaskByID(notif.TaskID); err == nil {
+			entry.Task = task
+		}
+		user, err := s.store.GetUserByID(notif.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user %d: %w", notif.UserID, err)
+		}
+		entry.User = user
+		failed = append(failed, entry)
+	}
+	return failed, nil
+}
+
+// RetryFailedNotification queues a failed notification for delivery right away
+func (s *Service) RetryFailedNotification(id int64) error {
+	notif, err := s.store.GetNotificationByID(id)
+	if err != nil {
+		return err
+	}
+	if !notif.IsFailed() {
+		return fmt.Errorf("notification has not failed")
+	}
+	if err := s.store.ResetNotificationDelivery(id, time.Now()); err != nil {
+		return err
+	}
+	s.wakeNotificationWorker()
+	return nil
+}
+
+// DismissFailedNotification deletes a failed notification
+func (s *Service) DismissFailedNotification(id int64) error {
+	notif, err := s.store.GetNotificationByID(id)
+	if err != nil {
+		return err
+	}
+	if !notif.IsFailed() {
+		return fmt.Errorf("notification has not failed")
+	}
+	return s.store.DeleteNotification(id)
+}
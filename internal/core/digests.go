@@ -0,0 +1,324 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Digest kinds
+const (
+	DigestMorning = "morning" // The day's plan: tasks due today, overdue ones and balances
+	DigestEvening = "evening" // The day's recap: what was earned and what is still open
+)
+
+// digestWindow is how long after its time a digest may still go out, e.g.
+// after a restart; later it is skipped for the day
+const digestWindow = 2 * time.Hour
+
+// maxDigestButtons limits the tasks a digest offers to complete
+const maxDigestButtons = 8
+
+// Digest summarizes a member's day across their active groups
+type Digest struct {
+	Kind   string
+	Day    time.Time // Local midnight of the day it covers
+	Groups []*DigestGroup
+}
+
+// DigestGroup is the part of a digest about one group
+type DigestGroup struct {
+	Group     *Group
+	Balance   int     // Cheese
+	DueToday  []*Task // Open tasks due later today
+	Overdue   []*Task // Open tasks whose deadline passed
+	Earned    int     // Cheese earned with tasks today
+	Completed int     // Task completions today, minus undone ones
+}
+
+// DueCount returns the number of open tasks due later today
+func (d *Digest) DueCount() int {
+	n := 0
+	for _, g := range d.Groups {
+		n += len(g.DueToday)
+	}
+	return n
+}
+
+// OverdueCount returns the number of open tasks past their deadline
+func (d *Digest) OverdueCount() int {
+	n := 0
+	for _, g := range d.Groups {
+		n += len(g.Overdue)
+	}
+	return n
+}
+
+// Earned returns the cheese earned with tasks today in all groups
+func (d *Digest) Earned() int {
+	n := 0
+	for _, g := range d.Groups {
+		n += g.Earned
+	}
+	return n
+}
+
+// Completed returns the task completions of today in all groups
+func (d *Digest) Completed() int {
+	n := 0
+	for _, g := range d.Groups {
+		n += g.Completed
+	}
+	return n
+}
+
+// OpenTasks returns the tasks still to do, overdue ones first
+func (d *Digest) OpenTasks() []*Task {
+	var tasks []*Task
+	for _, g := range d.Groups {
+		tasks = append(tasks, g.Overdue...)
+	}
+	for _, g := range d.Groups {
+		tasks = append(tasks, g.DueToday...)
+	}
+	return tasks
+}
+
+// ParseDigestTime parses the time of day a digest is sent at, such as
+// "8:00" or "21.30". An empty string or "off" turns the digest off.
+func ParseDigestTime(spec string) (*int, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" || spec == "off" || spec == "выкл" {
+		return nil, nil
+	}
+	minutes, err := parseClockMinutes(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &minutes, nil
+}
+
+// FormatDigestTime formats a digest time as "08:00", or "" when it is off
+func FormatDigestTime(at *int) string {
+	if at == nil {
+		return ""
+	}
+	return fmt.Sprintf("%02d:%02d", *at/60, *at%60)
+}
+
+// MorningDigestClock formats the morning digest time for time inputs
+func (n *NotificationSettings) MorningDigestClock() string {
+	return FormatDigestTime(n.MorningDigest)
+}
+
+// EveningDigestClock formats the evening digest time for time inputs
+func (n *NotificationSettings) EveningDigestClock() string {
+	return FormatDigestTime(n.EveningDigest)
+}
+
+// UpdateDigestTimes sets when the user gets the morning plan and the evening
+// recap; nil turns a digest off
+func (s *Service) UpdateDigestTimes(userID int64, morning, evening *int) error {
+	for _, at := range []*int{morning, evening} {
+		if at != nil && (*at < 0 || *at >= 24*60) {
+			return fmt.Errorf("invalid digest time")
+		}
+	}
+	settings, err := s.store.GetNotificationSettings(userID)
+	if err != nil {
+		return err
+	}
+	settings.MorningDigest = morning
+	settings.EveningDigest = evening
+	return s.store.UpdateNotificationSettings(settings)
+}
+
+// BuildDigest collects the user's day in their active groups: the open tasks
+// due today or overdue, balances, and the cheese earned with tasks since
+// local midnight. Groups the user is resting in are left out.
+func (s *Service) BuildDigest(userID int64, kind string, now time.Time) (*Digest, error) {
+	user, err := s.store.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	day := startOfDay(now.In(user.Location()))
+	tomorrow := day.AddDate(0, 0, 1)
+
+	groups, err := s.store.GetGroupsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+	transactions, err := s.store.GetTransactionsByUserSince(userID, day)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &Digest{Kind: kind, Day: day}
+	for _, group := range groups {
+		if group.IsArchived() || s.pausedAt(userID, group.ID, now) != nil {
+			continue
+		}
+		part := &DigestGroup{Group: group}
+
+		tasks, err := s.store.GetTasksByGroupID(group.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tasks: %w", err)
+		}
+		if err := s.ApplyTaskSnoozes(userID, tasks); err != nil {
+			return nil, fmt.Errorf("failed to get snoozes: %w", err)
+		}
+		SortTasksByDueDate(tasks)
+		for _, task := range tasks {
+			if task.DueAt == nil || !task.DueAt.Before(tomorrow) || !task.IsAvailable(now) {
+				continue
+			}
+			done, err := s.completedBefore(task, userID, now)
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				continue
+			}
+			if task.IsOverdue(now) {
+				part.Overdue = append(part.Overdue, task)
+			} else {
+				part.DueToday = append(part.DueToday, task)
+			}
+		}
+
+		part.Balance, err = s.store.GetBalance(userID, group.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get balance: %w", err)
+		}
+		for _, t := range transactions {
+			if t.GroupID != group.ID || t.SourceType != SourceTypeTask || t.ParentID != nil {
+				continue
+			}
+			part.Earned += t.Amount
+			// Undoing a completion records a negative amount
+			if t.Amount < 0 {
+				part.Completed--
+			} else {
+				part.Completed++
+			}
+		}
+
+		digest.Groups = append(digest.Groups, part)
+	}
+	return digest, nil
+}
+
+// SendDueDigests sends the morning and evening digests whose time has come
+// in each user's timezone. Each digest goes out at most once a day, and not
+// at all if it is more than digestWindow late.
+func (s *Service) SendDueDigests(bot BotNotifier, now time.Time) error {
+	all, err := s.store.GetDigestSettings()
+	if err != nil {
+		return err
+	}
+
+	for _, settings := range all {
+		user, err := s.store.GetUserByID(settings.UserID)
+		if err != nil || user.TelegramID == nil {
+			continue
+		}
+		profile, err := s.store.GetUserProfile(user.ID)
+		if err == nil && profile != nil && !profile.NotificationEnabled {
+			continue
+		}
+
+		local := now.In(user.Location())
+		for _, d := range []struct {
+			kind string
+			at   *int
+		}{{DigestMorning, settings.MorningDigest}, {DigestEvening, settings.EveningDigest}} {
+			if d.at == nil {
+				continue
+			}
+			sendAt := time.Date(local.Year(), local.Month(), local.Day(), *d.at/60, *d.at%60, 0, 0, local.Location())
+			if now.Before(sendAt) || now.Sub(sendAt) > digestWindow {
+				continue
+			}
+			if err := s.sendDigest(bot, user, d.kind, sendAt.Format("2006-01-02"), now); err != nil {
+				log.Printf("Failed to send %s digest to user %d: %v", d.kind, user.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// sendDigest builds and sends one digest unless it was already sent that day.
+// A failed send is tried again on the next run, unless the user blocked the bot.
+func (s *Service) sendDigest(bot BotNotifier, user *User, kind, day string, now time.Time) error {
+	claimed, err := s.store.ClaimDigest(user.ID, kind, day)
+	if err != nil || !claimed {
+		return err
+	}
+
+	digest, err := s.BuildDigest(user.ID, kind, now)
+	if err == nil && len(digest.Groups) == 0 {
+		// Nothing to tell without an active group
+		return nil
+	}
+	if err == nil {
+		message, buttons := digestMessage(digest, user)
+		err = bot.SendNotification(*user.TelegramID, message, buttons)
+	}
+	if err != nil && !errors.Is(err, ErrRecipientBlocked) {
+		if releaseErr := s.store.ReleaseDigest(user.ID, kind, day); releaseErr != nil {
+			log.Printf("Failed to release %s digest of user %d: %v", kind, user.ID, releaseErr)
+		}
+	}
+	return err
+}
+
+// digestMessage renders a digest for Telegram, with a button to complete
+// each open task
+func digestMessage(d *Digest, user *User) (string, map[string]string) {
+	loc := user.Location()
+	var b strings.Builder
+
+	if d.Kind == DigestMorning {
+		b.WriteString("☀️ Good morning! Here's your plan for today\n\n")
+		fmt.Fprintf(&b, "📋 Today: %d due, %d overdue\n", d.DueCount(), d.OverdueCount())
+	} else {
+		b.WriteString("🌙 Your day in review\n\n")
+		fmt.Fprintf(&b, "🏆 You earned %d coins today, %d quests done\n", d.Earned(), d.Completed())
+	}
+
+	for _, g := range d.Groups {
+		if d.Kind == DigestMorning {
+			fmt.Fprintf(&b, "\n📁 %s · 💰 %d coins\n", g.Group.Name, g.Balance)
+		} else {
+			fmt.Fprintf(&b, "\n📁 %s · +%d coins, %d quests · 💰 %d coins\n", g.Group.Name, g.Earned, g.Completed, g.Balance)
+		}
+		for _, task := range g.Overdue {
+			fmt.Fprintf(&b, "⏰ %s — was due %s\n", task.Title, task.DueAt.In(loc).Format("Mon 15:04"))
+		}
+		for _, task := range g.DueToday {
+			fmt.Fprintf(&b, "📌 %s — due %s\n", task.Title, task.DueAt.In(loc).Format("15:04"))
+		}
+	}
+
+	open := d.OpenTasks()
+	switch {
+	case len(open) == 0 && d.Kind == DigestMorning:
+		b.WriteString("\nNothing due today. Enjoy it 🌿")
+	case len(open) == 0:
+		b.WriteString("\nEverything for today is done. Well played! 🎉")
+	case d.Kind == DigestEvening:
+		b.WriteString("\nStill open — there's time to knock one out 💪")
+	}
+
+	// Numbered so the buttons keep the order of the list
+	buttons := make(map[string]string)
+	for i, task := range open {
+		if i == maxDigestButtons {
+			break
+		}
+		buttons[fmt.Sprintf("✅ %d. %s", i+1, task.Title)] = fmt.Sprintf("digestdone:%d", task.ID)
+	}
+	return strings.TrimRight(b.String(), "\n"), buttons
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DueSoonWindow is how close a deadline has to be for a task to count as due soon
+const DueSoonWindow = 24 * time.Hour
+
+// sameMinute reports whether two optional times are both nil or fall in the
+// same minute, the precision of the date inputs on the web
+func sameMinute(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Truncate(time.Minute).Equal(b.Truncate(time.Minute))
+}
+
+// startOfDay returns midnight of the day t falls on, in t's location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// SortTasksByDueDate orders tasks with a deadline first, earliest (and overdue)
+// at the top. Tasks without a deadline keep their order after them.
+func SortTasksByDueDate(tasks []*Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i].DueAt, tasks[j].DueAt
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.Before(*b)
+	})
+}
+
+// DueIn returns the time left until the deadline, rounded for display, e.g. "3h" or "45m"
+func (t *Task) DueIn(now time.Time) string {
+	if t.DueAt == nil {
+		return ""
+	}
+	left := t.DueAt.Sub(now)
+	if left < time.Hour {
+		return fmt.Sprintf("%dm", int(left.Minutes()))
+	}
+	if left < 48*time.Hour {
+		return fmt.Sprintf("%dh", int(left.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(left.Hours()/24))
+}
@@ -0,0 +1,227 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// economyShortlist is how many items the most and least bought lists show
+const economyShortlist = 3
+
+// economyPriceTolerance is how far, as a fraction of the target, an item's
+// days of work may drift before a new price is suggested
+const economyPriceTolerance = 0.5
+
+// EconomyTargets are the owner's goals for a group's economy
+type EconomyTargets struct {
+	GroupID      int64
+	WorkDays     int // Days of an average member's earnings a shop item should cost
+	SpendPercent int // Share of the cheese coming in that should be spent in the shop
+}
+
+// DefaultEconomyTargets returns the targets of a group that never set any
+func DefaultEconomyTargets(groupID int64) *EconomyTargets {
+	return &EconomyTargets{GroupID: groupID, WorkDays: 3, SpendPercent: 70}
+}
+
+// Economy summarizes the flow of cheese in a group over the last 30 days,
+// for the owner to tune prices and rewards
+type Economy struct {
+	Group   *Group
+	Targets *EconomyTargets
+	Since   time.Time // Local midnight 30 days ago
+	Until   time.Time // Local midnight after today
+	Members []*EconomyMember
+	Items   []*EconomyItem
+}
+
+// EconomyMember is a member's balance and cheese flows in the window
+type EconomyMember struct {
+	UserID    int64
+	Username  string
+	Balance   int // Current cheese balance, their share of the money supply
+	Earned    int // From tasks
+	Income    int // From grants, interest and manual entries
+	Spent     int // In the shop
+	Penalties int
+}
+
+// EconomyItem is a shop item with its sales and price in days of work
+type EconomyItem struct {
+	Item          *ShopItem
+	Purchases     int     // Bought in the window, undone purchases excluded
+	WorkDays      float64 // Days an average member works for it, 0 without earnings
+	SuggestedCost int     // Price matching the target, 0 if the price is fine
+}
+
+// Supply returns the cheese held by all members
+func (e *Economy) Supply() int {
+	total := 0
+	for _, m := range e.Members {
+		total += m.Balance
+	}
+	return total
+}
+
+// Earned returns the cheese earned with tasks in the window
+func (e *Economy) Earned() int {
+	total := 0
+	for _, m := range e.Members {
+		total += m.Earned
+	}
+	return total
+}
+
+// Income returns the cheese that came in other than through tasks
+func (e *Economy) Income() int {
+	total := 0
+	for _, m := range e.Members {
+		total += m.Income
+	}
+	return total
+}
+
+// Spent returns the cheese spent in the shop in the window
+func (e *Economy) Spent() int {
+	total := 0
+	for _, m := range e.Members {
+		total += m.Spent
+	}
+	return total
+}
+
+// SpendPercent returns spending as a percentage of all cheese that came in
+func (e *Economy) SpendPercent() int {
+	in := e.Earned() + e.Income()
+	if in <= 0 {
+		return 0
+	}
+	return e.Spent() * 100 / in
+}
+
+// Inflating reports whether members spend less than the target, so cheese
+// piles up and prices feel cheaper over time
+func (e *Economy) Inflating() bool {
+	return e.Earned()+e.Income() > 0 && e.SpendPercent() < e.Targets.SpendPercent
+}
+
+// DailyEarnings returns what an average member earns with tasks per day
+func (e *Economy) DailyEarnings() float64 {
+	if len(e.Members) == 0 {
+		return 0
+	}
+	days := e.Until.Sub(e.Since).Hours() / 24
+	return float64(e.Earned()) / float64(len(e.Members)) / days
+}
+
+// SupplyPercent returns a member's share of the money supply, for bars
+func (e *Economy) SupplyPercent(member *EconomyMember) int {
+	supply := e.Supply()
+	if supply <= 0 || member.Balance <= 0 {
+		return 0
+	}
+	return member.Balance * 100 / supply
+}
+
+// MostBought returns the best selling items, best first
+func (e *Economy) MostBought() []*EconomyItem {
+	var sold []*EconomyItem
+	for _, item := range e.Items {
+		if item.Purchases > 0 {
+			sold = append(sold, item)
+		}
+	}
+	sort.SliceStable(sold, func(i, j int) bool { return sold[i].Purchases > sold[j].Purchases })
+	if len(sold) > economyShortlist {
+		sold = sold[:economyShortlist]
+	}
+	return sold
+}
+
+// LeastBought returns the worst selling items, items nobody bought first
+func (e *Economy) LeastBought() []*EconomyItem {
+	items := append([]*EconomyItem(nil), e.Items...)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Purchases < items[j].Purchases })
+	if len(items) > economyShortlist {
+		items = items[:economyShortlist]
+	}
+	return items
+}
+
+// GetEconomy analyses the cheese economy of a group over the last 30 days (owner only)
+func (s *Service) GetEconomy(userID, groupID int64, now time.Time) (*Economy, error) {
+	group, err := s.requireGroupOwner(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := s.store.GetEconomyTargets(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	economy := &Economy{Group: group, Targets: targets}
+	economy.Since, economy.Until = StatsRange(StatsDaily, now.In(s.groupLocation(group)))
+
+	members, err := s.store.GetUsersByGroupID(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+	flows, err := s.store.GetEconomyFlows(groupID, economy.Since, economy.Until)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		row := flows[m.ID]
+		if row == nil {
+			row = &EconomyMember{UserID: m.ID}
+		}
+		row.Username = m.Username
+		row.Balance, err = s.store.GetBalance(m.ID, groupID)
+		if err != nil {
+			return nil, err
+		}
+		economy.Members = append(economy.Members, row)
+	}
+	sort.SliceStable(economy.Members, func(i, j int) bool {
+		return economy.Members[i].Balance > economy.Members[j].Balance
+	})
+
+	items, err := s.store.GetShopItemsByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	sales, err := s.store.GetPurchaseCounts(groupID, economy.Since, economy.Until)
+	if err != nil {
+		return nil, err
+	}
+	daily := economy.DailyEarnings()
+	for _, item := range items {
+		row := &EconomyItem{Item: item, Purchases: sales[item.ID]}
+		if daily > 0 {
+			row.WorkDays = float64(item.Cost) / daily
+			target := float64(targets.WorkDays)
+			if math.Abs(row.WorkDays-target) > target*economyPriceTolerance {
+				row.SuggestedCost = int(math.Max(1, math.Round(daily*target)))
+			}
+		}
+		economy.Items = append(economy.Items, row)
+	}
+
+	return economy, nil
+}
+
+// UpdateEconomyTargets sets the goals the economy page measures against (owner only)
+func (s *Service) UpdateEconomyTargets(userID, groupID int64, workDays, spendPercent int) error {
+	if _, err := s.requireGroupOwner(userID, groupID); err != nil {
+		return err
+	}
+	if workDays < 1 || workDays > 365 {
+		return fmt.Errorf("days of work must be between 1 and 365")
+	}
+	if spendPercent < 1 || spendPercent > 100 {
+		return fmt.Errorf("spending target must be between 1 and 100 percent")
+	}
+	return s.store.SaveEconomyTargets(&EconomyTargets{GroupID: groupID, WorkDays: workDays, SpendPercent: spendPercent})
+}
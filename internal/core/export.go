@@ -0,0 +1,164 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// Export datasets
+const (
+	ExportTransactions = "transactions"
+	ExportPurchases    = "purchases"
+	ExportTasks        = "tasks"
+)
+
+// ExportDatasets lists the datasets in the order they are offered
+var ExportDatasets = []string{ExportTransactions, ExportPurchases, ExportTasks}
+
+// ExportColumns are the columns of each dataset, in order. They are the
+// header of CSV exports and the keys of JSON exports, so they only ever grow.
+var ExportColumns = map[string][]string{
+	ExportTransactions: {
+		"id", "created_at", "group_id", "group", "user_id", "user", "type", "source_id",
+		"parent_id", "description", "notes", "quantity", "amount", "currency",
+	},
+	ExportPurchases: {
+		"id", "created_at", "group_id", "group", "user_id", "user", "item_id", "item",
+		"cost", "status", "fulfilled_at", "fulfilled_by", "notes", "cancelled_at", "transaction_id",
+	},
+	ExportTasks: {
+		"id", "created_at", "group_id", "group", "title", "description", "type", "reward",
+		"default_quantity", "one_time", "due_at", "available_from", "penalty", "late_reward_percent",
+	},
+}
+
+// ExportRow is one exported record, its values in the order of the dataset's
+// ExportColumns. Values are strings, ints, bools, time.Time or nil when empty.
+type ExportRow []interface{}
+
+// Export streams a dataset to fn one row at a time: of one group, or with
+// groupID 0 of all the user's groups. Transactions and purchases are the
+// user's own unless everyone is set, which only the group's owner may do.
+func (s *Service) Export(userID, groupID int64, everyone bool, dataset string, fn func(ExportRow) error) error {
+	if _, ok := ExportColumns[dataset]; !ok {
+		return fmt.Errorf("unknown export")
+	}
+
+	var groups []*Group
+	if groupID != 0 {
+		isMember, err := s.store.IsUserInGroup(userID, groupID)
+		if err != nil {
+			return err
+		}
+		if !isMember {
+			return fmt.Errorf("user is not a member of this group")
+		}
+		group, err := s.store.GetGroupByID(groupID)
+		if err != nil {
+			return err
+		}
+		if everyone && group.OwnerID != userID {
+			return fmt.Errorf("only the group owner can export everyone's data")
+		}
+		groups = []*Group{group}
+	} else {
+		if everyone {
+			return fmt.Errorf("only the group owner can export everyone's data")
+		}
+		var err error
+		if groups, err = s.store.GetGroupsByUserID(userID); err != nil {
+			return fmt.Errorf("failed to get groups: %w", err)
+		}
+	}
+
+	memberID := userID
+	if everyone {
+		memberID = 0
+	}
+	names := s.exportUserNames()
+	for _, group := range groups {
+		var err error
+		switch dataset {
+		case ExportTransactions:
+			var currencies map[int64]*Currency
+			if currencies, err = s.currencyIndex(group.ID); err != nil {
+				return err
+			}
+			err = s.store.StreamTransactions(group.ID, memberID, func(tx *Transaction) error {
+				currency := ""
+				if c, ok := currencies[tx.CurrencyID]; ok {
+					currency = c.Name
+				}
+				return fn(ExportRow{
+					tx.ID, tx.CreatedAt, group.ID, group.Name, tx.UserID, names(tx.UserID),
+					string(tx.SourceType), optionalID(tx.SourceID), optionalID(tx.ParentID),
+					tx.Description, tx.Notes, tx.Quantity, tx.Amount, currency,
+				})
+			})
+		case ExportPurchases:
+			err = s.store.StreamPurchases(group.ID, memberID, func(ph *PurchaseHistory) error {
+				p := ph.Purchase
+				status := LogStatusPending
+				if p.CancelledAt != nil {
+					status = LogStatusCancelled
+				} else if p.FulfilledAt != nil {
+					status = LogStatusFulfilled
+				}
+				var fulfilledBy interface{}
+				if p.FulfilledBy != nil {
+					fulfilledBy = names(*p.FulfilledBy)
+				}
+				return fn(ExportRow{
+					p.ID, p.CreatedAt, group.ID, group.Name, p.UserID, names(p.UserID),
+					p.ShopItemID, ph.ShopItem.Title, ph.ShopItem.Cost, status,
+					optionalTime(p.FulfilledAt), fulfilledBy, p.Notes, optionalTime(p.CancelledAt), p.TransactionID,
+				})
+			})
+		case ExportTasks:
+			err = s.store.StreamTasks(group.ID, func(task *Task) error {
+				return fn(ExportRow{
+					task.ID, task.CreatedAt, group.ID, group.Name, task.Title, task.Description,
+					string(task.TaskType), task.RewardValue, task.DefaultQuantity, task.IsOneTime,
+					optionalTime(task.DueAt), optionalTime(task.AvailableFrom),
+					task.Deadline.PenaltyAmount, task.Deadline.LateRewardPercent,
+				})
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportUserNames returns a lookup of usernames by ID that loads each user
+// once, including members who have since left the group
+func (s *Service) exportUserNames() func(int64) string {
+	cache := map[int64]string{}
+	return func(id int64) string {
+		name, ok := cache[id]
+		if !ok {
+			if user, err := s.store.GetUserByID(id); err == nil {
+				name = user.Username
+			}
+			cache[id] = name
+		}
+		return name
+	}
+}
+
+// optionalID is the ID as an export value, nil when unset
+func optionalID(id *int64) interface{} {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+// optionalTime is the time as an export value, nil when unset
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
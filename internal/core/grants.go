@@ -0,0 +1,265 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// maxGrantCatchUp limits how many missed schedule slots one grant pays out
+// in a single run, e.g. after the server was down for a long time
+const maxGrantCatchUp = 60
+
+// CreateScheduledGrant sets up an allowance or interest payment (owner only)
+func (s *Service) CreateScheduledGrant(userID int64, grant *ScheduledGrant) (*ScheduledGrant, error) {
+	if _, err := s.requireGroupOwner(userID, grant.GroupID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureGroupWritable(grant.GroupID); err != nil {
+		return nil, err
+	}
+
+	grant.Schedule = strings.TrimSpace(grant.Schedule)
+	if _, err := ParseSchedule(grant.Schedule); err != nil {
+		return nil, err
+	}
+
+	switch grant.Kind {
+	case GrantKindFixed:
+		if grant.Amount <= 0 {
+			return nil, fmt.Errorf("grant amount must be positive")
+		}
+		grant.RatePercent = 0
+	case GrantKindInterest:
+		if grant.RatePercent <= 0 || grant.RatePercent > 100 {
+			return nil, fmt.Errorf("interest rate must be between 1 and 100 percent")
+		}
+		if grant.Amount < 0 {
+			return nil, fmt.Errorf("interest cap cannot be negative")
+		}
+	default:
+		return nil, fmt.Errorf("unknown grant type")
+	}
+
+	index, err := s.currencyIndex(grant.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	currency, ok := index[grant.CurrencyID]
+	if !ok {
+		return nil, fmt.Errorf("currency not found")
+	}
+
+	for _, memberID := range grant.MemberIDs {
+		isMember, err := s.store.IsUserInGroup(memberID, grant.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		if !isMember {
+			return nil, fmt.Errorf("user is not a member of this group")
+		}
+	}
+
+	grant.Description = strings.TrimSpace(grant.Description)
+	if grant.Description == "" {
+		if grant.Kind == GrantKindInterest {
+			grant.Description = fmt.Sprintf("%s %d%% interest", currency.Emoji, grant.RatePercent)
+		} else {
+			grant.Description = "Allowance"
+		}
+	}
+
+	grant.CreatedBy = userID
+	return s.store.CreateScheduledGrant(grant)
+}
+
+// GetScheduledGrants retrieves a group's grants with currency and next run filled in
+func (s *Service) GetScheduledGrants(groupID int64) ([]*ScheduledGrant, error) {
+	grants, err := s.store.GetScheduledGrantsByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	index, err := s.currencyIndex(groupID)
+	if err != nil {
+		return nil, err
+	}
+	group, err := s.store.GetGroupByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	loc := s.groupLocation(group)
+
+	for _, grant := range grants {
+		grant.Currency = index[grant.CurrencyID]
+		if grant.Currency == nil {
+			grant.Currency = DefaultCurrency()
+		}
+		if schedule, err := ParseSchedule(grant.Schedule); err == nil {
+			grant.NextRunAt = schedule.Next(grantCursor(grant, loc))
+		}
+	}
+	return grants, nil
+}
+
+// getOwnedGrant loads a grant and verifies userID owns its group
+func (s *Service) getOwnedGrant(userID, grantID int64) (*ScheduledGrant, error) {
+	grant, err := s.store.GetScheduledGrantByID(grantID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.requireGroupOwner(userID, grant.GroupID); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+// SetScheduledGrantPaused pauses or resumes a grant (owner only). Slots that
+// fall inside the pause are skipped rather than paid on resume.
+func (s *Service) SetScheduledGrantPaused(userID, grantID int64, paused bool) (*ScheduledGrant, error) {
+	grant, err := s.getOwnedGrant(userID, grantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureGroupWritable(grant.GroupID); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.SetScheduledGrantPaused(grant.ID, paused, time.Now()); err != nil {
+		return nil, err
+	}
+	grant.Paused = paused
+	return grant, nil
+}
+
+// DeleteScheduledGrant stops a grant for good (owner only); past payments stay in the ledger
+func (s *Service) DeleteScheduledGrant(userID, grantID int64) (*ScheduledGrant, error) {
+	grant, err := s.getOwnedGrant(userID, grantID)
+	if err != nil {
+		return nil, err
+	}
+	return grant, s.store.DeleteScheduledGrant(grant.ID)
+}
+
+// grantCursor returns the time after which the grant's next slot is due, in
+// the timezone the schedule is read in
+func grantCursor(grant *ScheduledGrant, loc *time.Location) time.Time {
+	if grant.LastRunAt != nil {
+		return grant.LastRunAt.In(loc)
+	}
+	return grant.CreatedAt.In(loc)
+}
+
+// RunDueGrants pays every schedule slot that is due by now and returns the
+// number of payments made. Each (grant, member, slot) is paid at most once,
+// so running it again, or after a restart, does not pay twice.
+func (s *Service) RunDueGrants(now time.Time) (int, error) {
+	grants, err := s.store.GetActiveScheduledGrants()
+	if err != nil {
+		return 0, err
+	}
+
+	paid := 0
+	for _, grant := range grants {
+		schedule, err := ParseSchedule(grant.Schedule)
+		if err != nil {
+			log.Printf("[Scheduler] Grant %d has an invalid schedule: %v", grant.ID, err)
+			continue
+		}
+		group, err := s.store.GetGroupByID(grant.GroupID)
+		if err != nil || group.IsArchived() {
+			continue
+		}
+
+		// Schedules follow the owner's calendar, so "daily at 8:00" is their 8:00
+		cursor := grantCursor(grant, s.groupLocation(group))
+		for slots := 0; ; slots++ {
+			next := schedule.Next(cursor)
+			if next.IsZero() || next.After(now) {
+				break
+			}
+			if slots == maxGrantCatchUp {
+				log.Printf("[Scheduler] Grant %d missed more than %d runs, skipping ahead", grant.ID, maxGrantCatchUp)
+				if err := s.store.SetScheduledGrantLastRun(grant.ID, now); err != nil {
+					log.Printf("[Scheduler] Failed to update grant %d: %v", grant.ID, err)
+				}
+				break
+			}
+
+			// Slots that fall inside a group pause are skipped, not paid later
+			if s.pausedAt(0, grant.GroupID, next) != nil {
+				if err := s.store.SetScheduledGrantLastRun(grant.ID, next); err != nil {
+					log.Printf("[Scheduler] Failed to update grant %d: %v", grant.ID, err)
+					break
+				}
+				cursor = next
+				continue
+			}
+
+			n, err := s.executeGrant(grant, next)
+			paid += n
+			if err != nil {
+				// Leave the cursor on the failed slot so the next tick retries it
+				log.Printf("[Scheduler] Failed to run grant %d: %v", grant.ID, err)
+				break
+			}
+			if err := s.store.SetScheduledGrantLastRun(grant.ID, next); err != nil {
+				log.Printf("[Scheduler] Failed to update grant %d: %v", grant.ID, err)
+				break
+			}
+			cursor = next
+		}
+	}
+
+	return paid, nil
+}
+
+// executeGrant pays one schedule slot of a grant to each of its recipients
+func (s *Service) executeGrant(grant *ScheduledGrant, runAt time.Time) (int, error) {
+	members, err := s.store.GetUsersByGroupID(grant.GroupID)
+	if err != nil {
+		return 0, err
+	}
+
+	selected := make(map[int64]bool, len(grant.MemberIDs))
+	for _, id := range grant.MemberIDs {
+		selected[id] = true
+	}
+
+	paid := 0
+	for _, member := range members {
+		if len(selected) > 0 && !selected[member.ID] {
+			continue
+		}
+		if s.pausedAt(member.ID, grant.GroupID, runAt) != nil {
+			continue
+		}
+
+		amount := grant.Amount
+		sourceType := SourceTypeGrant
+		if grant.Kind == GrantKindInterest {
+			sourceType = SourceTypeInterest
+			balance, err := s.store.GetCurrencyBalance(member.ID, grant.GroupID, grant.CurrencyID)
+			if err != nil {
+				return paid, err
+			}
+			amount = balance * grant.RatePercent / 100
+			if grant.Amount > 0 && amount > grant.Amount {
+				amount = grant.Amount
+			}
+		}
+		if amount <= 0 {
+			continue
+		}
+
+		ok, err := s.store.PayScheduledGrant(grant, member.ID, runAt, amount, sourceType)
+		if err != nil {
+			return paid, err
+		}
+		if ok {
+			paid++
+		}
+	}
+
+	return paid, nil
+}
@@ -0,0 +1,388 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Leaderboard periods
+const (
+	LeaderboardWeek   = "week"
+	LeaderboardMonth  = "month"
+	LeaderboardAll    = "all"
+	LeaderboardSeason = "season"
+)
+
+// seasonBadges are awarded to the top three of a season, by rank
+var seasonBadges = []string{"🥇", "🥈", "🥉"}
+
+// leaderboardMessageSize is how many members a bot leaderboard lists
+const leaderboardMessageSize = 10
+
+// Leaderboard ranks the members of a group by cheese earned with tasks (not
+// balance) over a period
+type Leaderboard struct {
+	Group   *Group
+	Period  string
+	Season  *Season   // The running season, nil if there is none
+	Start   time.Time // Zero for all time
+	End     time.Time
+	Entries []*LeaderboardEntry // Empty for a season leaderboard without a season
+}
+
+// LeaderboardEntry is a member's place on a leaderboard
+type LeaderboardEntry struct {
+	Rank        int // Members who earned the same share a rank
+	UserID      int64
+	Username    string
+	Earned      int
+	Completions int
+	Badges      []*SeasonStanding // Badges won in past seasons
+}
+
+// ParseLeaderboardPeriod reads a leaderboard period in English or Russian,
+// defaulting to this week
+func ParseLeaderboardPeriod(s string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "week", "неделя":
+		return LeaderboardWeek, true
+	case "month", "месяц":
+		return LeaderboardMonth, true
+	case "all", "всё", "все":
+		return LeaderboardAll, true
+	case "season", "сезон":
+		return LeaderboardSeason, true
+	}
+	return "", false
+}
+
+// Title describes the leaderboard's period
+func (l *Leaderboard) Title() string {
+	switch l.Period {
+	case LeaderboardMonth:
+		return "This month"
+	case LeaderboardAll:
+		return "All time"
+	case LeaderboardSeason:
+		if l.Season == nil {
+			return "No season running"
+		}
+		return fmt.Sprintf("%s · until %s", l.Season.Name, l.Season.EndsAt.In(l.End.Location()).Format("Jan 2"))
+	}
+	return "This week"
+}
+
+// BuildLeaderboard ranks a group's members for a member of it. Weeks and
+// months follow the viewer's timezone.
+func (s *Service) BuildLeaderboard(viewerID, groupID int64, period string, now time.Time) (*Leaderboard, error) {
+	isMember, err := s.store.IsUserInGroup(viewerID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, fmt.Errorf("user is not a member of this group")
+	}
+	viewer, err := s.store.GetUserByID(viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	group, err := s.store.GetGroupByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &Leaderboard{Group: group, Period: period}
+	local := now.In(viewer.Location())
+	switch period {
+	case LeaderboardWeek:
+		board.Start, board.End = ReportRange(ReportWeek, local, 0)
+	case LeaderboardMonth:
+		board.Start, board.End = ReportRange(ReportMonth, local, 0)
+	case LeaderboardAll:
+		board.End = startOfDay(local).AddDate(0, 0, 1)
+	case LeaderboardSeason:
+		seasons, err := s.store.GetSeasonsByGroupID(groupID)
+		if err != nil {
+			return nil, err
+		}
+		for _, season := range seasons {
+			if season.IsActive(now) {
+				board.Season = season
+			}
+		}
+		if board.Season == nil {
+			board.End = local
+			return board, nil
+		}
+		board.Start, board.End = board.Season.StartsAt.In(local.Location()), board.Season.EndsAt.In(local.Location())
+	default:
+		return nil, fmt.Errorf("unknown leaderboard period %q", period)
+	}
+
+	board.Entries, err = s.rankMembers(groupID, board.Start, board.End)
+	if err != nil {
+		return nil, err
+	}
+
+	badges, err := s.store.GetSeasonBadges(groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range board.Entries {
+		for _, badge := range badges {
+			if badge.UserID == entry.UserID {
+				entry.Badges = append(entry.Badges, badge)
+			}
+		}
+	}
+	return board, nil
+}
+
+// rankMembers ranks a group's members by cheese earned in [since, until),
+// then by completions
+func (s *Service) rankMembers(groupID int64, since, until time.Time) ([]*LeaderboardEntry, error) {
+	members, err := s.store.GetUsersByGroupID(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+	totals, err := s.store.GetMemberReport(groupID, 0, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*LeaderboardEntry, 0, len(members))
+	for _, m := range members {
+		entry := &LeaderboardEntry{UserID: m.ID, Username: m.Username}
+		if row := totals[m.ID]; row != nil {
+			entry.Earned, entry.Completions = row.Earned, row.Completions
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Earned != b.Earned {
+			return a.Earned > b.Earned
+		}
+		if a.Completions != b.Completions {
+			return a.Completions > b.Completions
+		}
+		return a.Username < b.Username
+	})
+	for i, entry := range entries {
+		entry.Rank = i + 1
+		if i > 0 && entry.Earned == entries[i-1].Earned {
+			entry.Rank = entries[i-1].Rank
+		}
+	}
+	return entries, nil
+}
+
+// Medal returns the medal of the top three, if they earned anything
+func (e *LeaderboardEntry) Medal() string {
+	if e.Rank > len(seasonBadges) || e.Earned <= 0 {
+		return ""
+	}
+	return seasonBadges[e.Rank-1]
+}
+
+// Summary renders the top of the leaderboard for a bot message
+func (l *Leaderboard) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📁 %s · %s", l.Group.Name, l.Title())
+	for i, entry := range l.Entries {
+		if i == leaderboardMessageSize {
+			fmt.Fprintf(&b, "\n… and %d more", len(l.Entries)-i)
+			break
+		}
+		medal := entry.Medal()
+		if medal == "" {
+			medal = fmt.Sprintf("%d.", entry.Rank)
+		}
+		fmt.Fprintf(&b, "\n%s %s — 🧀 %d · ✅ %d", medal, entry.Username, entry.Earned, entry.Completions)
+		for _, badge := range entry.Badges {
+			b.WriteString(" " + badge.Badge)
+		}
+	}
+	return b.String()
+}
+
+// BuildMemberLeaderboards builds the leaderboard of each of the user's active groups
+func (s *Service) BuildMemberLeaderboards(userID int64, period string, now time.Time) ([]*Leaderboard, error) {
+	groups, err := s.store.GetGroupsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+	var boards []*Leaderboard
+	for _, group := range groups {
+		if group.IsArchived() {
+			continue
+		}
+		board, err := s.BuildLeaderboard(userID, group.ID, period, now)
+		if err != nil {
+			return nil, err
+		}
+		boards = append(boards, board)
+	}
+	return boards, nil
+}
+
+// LeaderboardMessage renders leaderboards of several groups as one Telegram message
+func LeaderboardMessage(boards []*Leaderboard) string {
+	parts := []string{"🏆 Leaderboard"}
+	for _, board := range boards {
+		parts = append(parts, board.Summary())
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// CreateSeason schedules a leaderboard season (owner only). An empty start
+// means the season starts right away. Seasons of a group cannot overlap.
+func (s *Service) CreateSeason(userID, groupID int64, name string, startsAt, endsAt time.Time, awardBadges bool) (*Season, error) {
+	if _, err := s.requireGroupOwner(userID, groupID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureGroupWritable(groupID); err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("season name is required")
+	}
+	now := time.Now()
+	if startsAt.IsZero() {
+		startsAt = now
+	}
+	if endsAt.IsZero() || !endsAt.After(startsAt) {
+		return nil, fmt.Errorf("season must end after it starts")
+	}
+	if !endsAt.After(now) {
+		return nil, fmt.Errorf("season must end in the future")
+	}
+
+	seasons, err := s.store.GetSeasonsByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range seasons {
+		if startsAt.Before(other.EndsAt) && other.StartsAt.Before(endsAt) {
+			return nil, fmt.Errorf("season overlaps with %s", other.Name)
+		}
+	}
+
+	return s.store.CreateSeason(&Season{
+		GroupID:     groupID,
+		Name:        name,
+		StartsAt:    startsAt,
+		EndsAt:      endsAt,
+		AwardBadges: awardBadges,
+		CreatedBy:   userID,
+	})
+}
+
+// GetSeasons retrieves a group's seasons, latest first, with the final
+// standings of closed ones
+func (s *Service) GetSeasons(groupID int64) ([]*Season, error) {
+	seasons, err := s.store.GetSeasonsByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, season := range seasons {
+		if season.ClosedAt == nil {
+			continue
+		}
+		season.Standings, err = s.store.GetSeasonStandings(season.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return seasons, nil
+}
+
+// getOwnedSeason loads a season and verifies userID owns its group
+func (s *Service) getOwnedSeason(userID, seasonID int64) (*Season, error) {
+	season, err := s.store.GetSeasonByID(seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.requireGroupOwner(userID, season.GroupID); err != nil {
+		return nil, err
+	}
+	return season, nil
+}
+
+// EndSeason ends a running season early and archives its standings (owner only)
+func (s *Service) EndSeason(userID, seasonID int64) (*Season, error) {
+	season, err := s.getOwnedSeason(userID, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !season.IsActive(now) {
+		return nil, fmt.Errorf("season is not running")
+	}
+
+	if err := s.store.SetSeasonEnd(season.ID, now); err != nil {
+		return nil, err
+	}
+	season.EndsAt = now
+	return season, s.closeSeason(season, now)
+}
+
+// DeleteSeason removes a season that has not started yet (owner only)
+func (s *Service) DeleteSeason(userID, seasonID int64) (*Season, error) {
+	season, err := s.getOwnedSeason(userID, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if !season.IsUpcoming(time.Now()) {
+		return nil, fmt.Errorf("only upcoming seasons can be deleted")
+	}
+	return season, s.store.DeleteSeason(season.ID)
+}
+
+// CloseEndedSeasons archives the final standings of every season that ended
+func (s *Service) CloseEndedSeasons(now time.Time) (int, error) {
+	seasons, err := s.store.GetSeasonsToClose(now)
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for _, season := range seasons {
+		if err := s.closeSeason(season, now); err != nil {
+			log.Printf("Failed to close season %d: %v", season.ID, err)
+			continue
+		}
+		closed++
+	}
+	return closed, nil
+}
+
+// closeSeason ranks the members over the season and archives the standings,
+// with badges for the top three if the season awards them
+func (s *Service) closeSeason(season *Season, now time.Time) error {
+	entries, err := s.rankMembers(season.GroupID, season.StartsAt, season.EndsAt)
+	if err != nil {
+		return err
+	}
+
+	standings := make([]*SeasonStanding, 0, len(entries))
+	for _, entry := range entries {
+		standing := &SeasonStanding{
+			SeasonID:    season.ID,
+			UserID:      entry.UserID,
+			Rank:        entry.Rank,
+			Earned:      entry.Earned,
+			Completions: entry.Completions,
+		}
+		if season.AwardBadges {
+			standing.Badge = entry.Medal()
+		}
+		standings = append(standings, standing)
+	}
+	return s.store.CloseSeason(season.ID, standings, now)
+}
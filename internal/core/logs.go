@@ -0,0 +1,106 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// LogPageSize is how many entries a page of the task or purchase log shows
+const LogPageSize = 25
+
+// Log statuses. Task log entries are completions or the reversals recorded
+// when one is undone; purchases are pending, fulfilled or cancelled (undone).
+const (
+	LogStatusCompleted = "completed"
+	LogStatusUndone    = "undone"
+	LogStatusPending   = "pending"
+	LogStatusFulfilled = "fulfilled"
+	LogStatusCancelled = "cancelled"
+)
+
+// LogFilter narrows the task or purchase log of a group. Pages run from the
+// newest entry back; Before is the ID of the last entry of the previous page.
+type LogFilter struct {
+	UserID   int64     // Member whose entries to list, 0 for every member (owner only)
+	SourceID int64     // Task or shop item, 0 for any
+	Since    time.Time // Zero for no lower bound
+	Until    time.Time // Exclusive, zero for no upper bound
+	Status   string    // One of the log statuses, "" for any
+	Before   int64     // Cursor, 0 for the first page
+	Limit    int
+}
+
+// checkLogFilter verifies the viewer may read the log the filter asks for
+// and that its status fits the log
+func (s *Service) checkLogFilter(viewerID, groupID int64, filter *LogFilter, statuses ...string) error {
+	isMember, err := s.store.IsUserInGroup(viewerID, groupID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return fmt.Errorf("user is not a member of this group")
+	}
+	if filter.UserID != viewerID {
+		if _, err := s.requireGroupOwner(viewerID, groupID); err != nil {
+			return err
+		}
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Until.After(filter.Since) {
+		return fmt.Errorf("date range ends before it starts")
+	}
+
+	valid := filter.Status == ""
+	for _, status := range statuses {
+		valid = valid || filter.Status == status
+	}
+	if !valid {
+		return fmt.Errorf("unknown status %q", filter.Status)
+	}
+	if filter.Limit <= 0 || filter.Limit > LogPageSize {
+		filter.Limit = LogPageSize
+	}
+	return nil
+}
+
+// GetTaskLog retrieves a page of a group's task completions, newest first,
+// with the cursor of the next page (0 on the last page). Members see their
+// own entries, the owner may list anyone's or everyone's.
+func (s *Service) GetTaskLog(viewerID, groupID int64, filter LogFilter) ([]*TaskCompletionHistory, int64, error) {
+	if err := s.checkLogFilter(viewerID, groupID, &filter, LogStatusCompleted, LogStatusUndone); err != nil {
+		return nil, 0, err
+	}
+
+	// Ask for one more entry to know whether another page follows
+	limit := filter.Limit
+	filter.Limit++
+	entries, err := s.store.GetTaskCompletionHistory(groupID, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(entries) <= limit {
+		return entries, 0, nil
+	}
+	entries = entries[:limit]
+	return entries, entries[limit-1].Transaction.ID, nil
+}
+
+// GetPurchaseLog retrieves a page of a group's purchases, newest first, with
+// the cursor of the next page (0 on the last page). Members see their own
+// purchases, the owner may list anyone's or everyone's.
+func (s *Service) GetPurchaseLog(viewerID, groupID int64, filter LogFilter) ([]*PurchaseHistory, int64, error) {
+	if err := s.checkLogFilter(viewerID, groupID, &filter, LogStatusPending, LogStatusFulfilled, LogStatusCancelled); err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+	entries, err := s.store.GetPurchaseHistory(groupID, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(entries) <= limit {
+		return entries, 0, nil
+	}
+	entries = entries[:limit]
+	return entries, entries[limit-1].Purchase.ID, nil
+}
@@ -8,16 +8,40 @@ type User struct {
 	TelegramID *int64 // Nullable
 	Username   string
 	Language   string
+	Timezone   string // IANA name, e.g. "Europe/Berlin"; empty means server time
 	CreatedAt  time.Time
 }
 
 // Group represents a group in the system
 type Group struct {
-	ID         int64
-	Name       string
-	InviteCode string
-	OwnerID    int64
-	CreatedAt  time.Time
+	ID           int64
+	Name         string
+	InviteCode   string
+	OwnerID      int64
+	ArchivedAt   *time.Time // Set when the group is read-only and hidden
+	DeleteAfter  *time.Time // Set when the owner requested deletion
+	RetainLedger bool       // Keep an exported ledger after deletion
+	CreatedAt    time.Time
+}
+
+// IsArchived reports whether the group is archived (read-only)
+func (g *Group) IsArchived() bool {
+	return g.ArchivedAt != nil
+}
+
+// IsPendingDeletion reports whether the group is scheduled for deletion
+func (g *Group) IsPendingDeletion() bool {
+	return g.DeleteAfter != nil
+}
+
+// GroupLedgerExport is a ledger snapshot kept after a group was deleted
+type GroupLedgerExport struct {
+	ID        int64
+	GroupID   int64
+	GroupName string
+	OwnerID   int64
+	Data      string // JSON encoded transactions and purchases
+	CreatedAt time.Time
 }
 
 // GroupMember represents a user's membership in a group
@@ -45,8 +69,89 @@ type Task struct {
 	RewardValue     int // Coins per completion or per unit
 	DefaultQuantity int // Default quantity for integer tasks
 	IsOneTime       bool
-	DueAt           *time.Time // Optional deadline for the task
-	CreatedAt       time.Time
+	DueAt           *time.Time       // Optional deadline for the task
+	AvailableFrom   *time.Time       // Task stays hidden until this moment; nil = always visible
+	SnoozedUntil    *time.Time       // The viewing member hid the task from themselves until then, see ApplyTaskSnoozes
+	Deadline        DeadlinePolicy   // What happens once DueAt has passed
+	ExtraRewards    []CurrencyAmount // Rewards in group-defined currencies, on top of RewardValue
+	// DeadlineEvaluatedAt is the DueAt the penalty evaluator last processed,
+	// so each deadline is penalized once even when DueAt later moves
+	DeadlineEvaluatedAt *time.Time
+	CreatedAt           time.Time
+}
+
+// DeadlinePolicy configures the reminders about a task's deadline and the
+// consequences of missing it
+type DeadlinePolicy struct {
+	PenaltyAmount     int  // Cheese deducted from each member who missed the deadline, 0 = none
+	LateRewardPercent int  // Share of the reward paid for late completions, 100 = full reward
+	GraceMinutes      int  // Minutes after DueAt before the deadline counts as missed
+	Urgent            bool // The on-deadline reminder may break through quiet hours
+	// ReminderOffsets are the minutes before DueAt members are reminded at,
+	// earliest first; empty = each member's ReminderDeltaMinutes
+	ReminderOffsets []int
+
+	// Nag mode: after the deadline is missed, keep reminding each member who
+	// has not completed the task, more often every time, until they do it or
+	// dismiss the reminders
+	NagIntervalMinutes int    // Wait before the first nag, halved for each next one; 0 = off
+	NagEscalateAfter   int    // Nags before NagPartnerID is told; 0 = DefaultNagEscalateAfter
+	NagPartnerID       *int64 // Group member who keeps the others accountable, optional
+}
+
+// DefaultDeadlinePolicy is used for tasks without penalties
+func DefaultDeadlinePolicy() DeadlinePolicy {
+	return DeadlinePolicy{LateRewardPercent: 100}
+}
+
+// IsAvailable reports whether the task can be seen and done at the given time
+// by the member whose snooze is in SnoozedUntil
+func (t *Task) IsAvailable(now time.Time) bool {
+	return (t.AvailableFrom == nil || !now.Before(*t.AvailableFrom)) &&
+		(t.SnoozedUntil == nil || !now.Before(*t.SnoozedUntil))
+}
+
+// HiddenUntil returns when the task shows up again: the later of its start
+// date and the member's snooze, nil when neither is set
+func (t *Task) HiddenUntil() *time.Time {
+	if t.SnoozedUntil != nil && (t.AvailableFrom == nil || t.SnoozedUntil.After(*t.AvailableFrom)) {
+		return t.SnoozedUntil
+	}
+	return t.AvailableFrom
+}
+
+// IsSnoozed reports whether the member's own snooze, not the start date, is
+// what keeps the task hidden the longest
+func (t *Task) IsSnoozed() bool {
+	return t.SnoozedUntil != nil && t.HiddenUntil() == t.SnoozedUntil
+}
+
+// IsOverdue reports whether the task's deadline has passed
+func (t *Task) IsOverdue(now time.Time) bool {
+	return t.DueAt != nil && now.After(*t.DueAt)
+}
+
+// IsDueSoon reports whether the deadline is ahead but within DueSoonWindow
+func (t *Task) IsDueSoon(now time.Time) bool {
+	return t.DueAt != nil && !t.IsOverdue(now) && t.DueAt.Sub(now) <= DueSoonWindow
+}
+
+// MissedAt returns when the deadline counts as missed: DueAt plus the grace period
+func (t *Task) MissedAt() time.Time {
+	if t.DueAt == nil {
+		return time.Time{}
+	}
+	return t.DueAt.Add(time.Duration(t.Deadline.GraceMinutes) * time.Minute)
+}
+
+// IsLate reports whether the deadline and its grace period have passed
+func (t *Task) IsLate(now time.Time) bool {
+	return t.DueAt != nil && now.After(t.MissedAt())
+}
+
+// ExtraReward returns the reward in the given currency, or 0
+func (t *Task) ExtraReward(currencyID int64) int {
+	return findCurrencyAmount(t.ExtraRewards, currencyID)
 }
 
 // ShopItem represents an item in the group shop
@@ -55,11 +160,74 @@ type ShopItem struct {
 	GroupID     int64
 	Title       string
 	Description string
-	Cost        int
+	Cost        int // Price in the default currency (cheese)
 	IsOneTime   bool
+	ExtraPrices []CurrencyAmount // Prices in group-defined currencies, on top of Cost
 	CreatedAt   time.Time
 }
 
+// ExtraPrice returns the price in the given currency, or 0
+func (i *ShopItem) ExtraPrice(currencyID int64) int {
+	return findCurrencyAmount(i.ExtraPrices, currencyID)
+}
+
+// DefaultCurrencyID identifies the built-in cheese currency every group has
+const DefaultCurrencyID int64 = 0
+
+// Currency represents a group-defined currency such as "golden cheese"
+type Currency struct {
+	ID        int64
+	GroupID   int64
+	Name      string
+	Emoji     string
+	Labels    map[string]string // Localized display names keyed by locale
+	CreatedAt time.Time
+}
+
+// Label returns the localized name of the currency, falling back to Name
+func (c *Currency) Label(locale string) string {
+	if label := c.Labels[locale]; label != "" {
+		return label
+	}
+	return c.Name
+}
+
+// CurrencyAmount is an amount in a specific currency
+type CurrencyAmount struct {
+	CurrencyID int64
+	Amount     int
+	Currency   *Currency // Filled in by the service for display
+}
+
+// findCurrencyAmount returns the amount for currencyID in amounts, or 0
+func findCurrencyAmount(amounts []CurrencyAmount, currencyID int64) int {
+	for _, a := range amounts {
+		if a.CurrencyID == currencyID {
+			return a.Amount
+		}
+	}
+	return 0
+}
+
+// CurrencyBalance is a user's balance in one currency
+type CurrencyBalance struct {
+	Currency *Currency
+	Amount   int
+}
+
+// ExchangeRule lets members trade FromAmount of one currency for ToAmount of another
+type ExchangeRule struct {
+	ID             int64
+	GroupID        int64
+	FromCurrencyID int64
+	FromAmount     int
+	ToCurrencyID   int64
+	ToAmount       int
+	FromCurrency   *Currency // Filled in by the service for display
+	ToCurrency     *Currency // Filled in by the service for display
+	CreatedAt      time.Time
+}
+
 // SourceType represents the source of a transaction
 type SourceType string
 
@@ -67,6 +235,10 @@ const (
 	SourceTypeTask     SourceType = "task"
 	SourceTypeShopItem SourceType = "shop_item"
 	SourceTypeManual   SourceType = "manual"
+	SourceTypeExchange SourceType = "exchange"
+	SourceTypeGrant    SourceType = "grant"
+	SourceTypeInterest SourceType = "interest"
+	SourceTypePenalty  SourceType = "penalty"
 )
 
 // Transaction represents a coin transaction
@@ -74,7 +246,9 @@ type Transaction struct {
 	ID          int64
 	UserID      int64
 	GroupID     int64
-	Amount      int // Positive for earnings, negative for spending
+	Amount      int    // Positive for earnings, negative for spending
+	CurrencyID  int64  // DefaultCurrencyID for cheese
+	ParentID    *int64 // Set on extra-currency legs of a completion, purchase or exchange
 	SourceType  SourceType
 	SourceID    *int64 // Nullable FK to Task or ShopItem
 	Quantity    int    // For integer tasks: how many units were completed
@@ -83,6 +257,54 @@ type Transaction struct {
 	CreatedAt   time.Time
 }
 
+// WishlistItem is a shop item a member pinned as a savings goal
+type WishlistItem struct {
+	ID         int64
+	UserID     int64
+	GroupID    int64
+	ShopItemID int64
+	Reserved   int        // Cheese locked toward this goal, not spendable elsewhere
+	NotifiedAt *time.Time // Set once the member was told they can afford it
+	CreatedAt  time.Time
+}
+
+// WishlistProgress describes how close a member is to affording a wishlist item
+type WishlistProgress struct {
+	Entry      *WishlistItem
+	ShopItem   *ShopItem
+	Group      *Group
+	Available  int // Cheese that can go toward this item (balance minus other reservations)
+	Percent    int // 0-100, limited by the currency the member is furthest from
+	Affordable bool
+}
+
+// GrantKind distinguishes fixed allowances from interest on saved balances
+type GrantKind string
+
+const (
+	GrantKindFixed    GrantKind = "fixed"
+	GrantKindInterest GrantKind = "interest"
+)
+
+// ScheduledGrant pays members automatically on a cron schedule
+type ScheduledGrant struct {
+	ID          int64
+	GroupID     int64
+	CreatedBy   int64
+	Kind        GrantKind
+	CurrencyID  int64
+	Amount      int // Fixed amount per member, or the interest cap per member (0 = no cap)
+	RatePercent int // Interest paid on the saved balance, in percent
+	Schedule    string
+	Description string
+	MemberIDs   []int64 // Recipients; empty means every member of the group
+	Paused      bool
+	LastRunAt   *time.Time // Latest schedule slot that was executed
+	CreatedAt   time.Time
+	Currency    *Currency // Filled in by the service for display
+	NextRunAt   time.Time // Filled in by the service for display
+}
+
 // Purchase represents a shop item purchase with fulfillment tracking
 type Purchase struct {
 	ID            int64
@@ -94,6 +316,7 @@ type Purchase struct {
 	FulfilledAt   *time.Time
 	FulfilledBy   *int64 // User who fulfilled the purchase
 	Notes         string
+	CancelledAt   *time.Time // Set when the purchase was undone
 	CreatedAt     time.Time
 }
 
@@ -122,19 +345,132 @@ type PurchaseHistory struct {
 // NotificationSettings represents user-specific notification preferences
 type NotificationSettings struct {
 	UserID               int64
-	ReminderDeltaMinutes int // Notify X minutes before deadline
-	SnoozeDefaultMinutes int // Default snooze duration
+	ReminderDeltaMinutes int                         // Notify X minutes before deadline, unless the task sets its own offsets
+	SnoozeDefaultMinutes int                         // Default snooze duration
+	QuietHours           QuietHours                  // Nightly window in which reminders are held back
+	QuietDays            map[time.Weekday]QuietHours // Per-weekday overrides, keyed by the day the window starts
+	UrgentDuringQuiet    bool                        // Deliver on-deadline reminders of urgent tasks during quiet hours
+	MorningDigest        *int                        // Minutes after local midnight the day's plan is sent at; nil = off
+	EveningDigest        *int                        // Minutes after local midnight the day's recap is sent at; nil = off
+	ReportPeriod         string                      // ReportWeek or ReportMonth to get the past period's report via bot; "" = off
 	CreatedAt            time.Time
 	UpdatedAt            time.Time
 }
 
+// ChannelPreference is whether, and in which order, a user is reminded on a
+// delivery channel
+type ChannelPreference struct {
+	UserID   int64
+	Channel  string // ChannelTelegram, ChannelEmail, ...
+	Enabled  bool
+	Address  string // Email address or webhook URL; unused by the other channels
+	Priority int    // Channels are tried lowest first until one delivers
+}
+
+// PushSubscription is a browser subscribed to Web Push notifications
+type PushSubscription struct {
+	ID        int64
+	UserID    int64
+	Endpoint  string // Push service URL, unique per browser
+	P256dh    string // Browser public key, base64url
+	Auth      string // Authentication secret, base64url
+	CreatedAt time.Time
+}
+
 // TaskNotification represents a scheduled or sent notification for a task
 type TaskNotification struct {
 	ID               int64
 	TaskID           int64
 	UserID           int64
-	NotificationType string // 'before_deadline', 'on_deadline', 'snooze'
+	NotificationType string // 'before_deadline', 'on_deadline', 'snooze', 'nag', 'nag_partner', 'nag_dismissed'
 	ScheduledAt      time.Time
 	SentAt           *time.Time // NULL if pending
+	Attempts         int        // Failed delivery attempts so far
+	LastError        string     // Error of the last failed attempt
+	NextAttemptAt    *time.Time // When a failed delivery is retried; NULL = at ScheduledAt
+	FailedAt         *time.Time // Set once delivery is given up; the notification stays unsent
+	Stage            int        // Number of the nag, counting from 1; 0 for other types
+	AboutUserID      *int64     // Member a 'nag_partner' alert is about
 	CreatedAt        time.Time
 }
+
+// IsFailed reports whether delivery of the notification was given up
+func (n *TaskNotification) IsFailed() bool {
+	return n.FailedAt != nil
+}
+
+// FailedNotification is a notification that could not be delivered, with
+// its task and recipient for the admin view. Task is nil once it was deleted.
+type FailedNotification struct {
+	Notification *TaskNotification
+	Task         *Task
+	User         *User
+}
+
+// Pause is a rest period for one user (UserID set) or a whole group (GroupID set).
+// While a pause is active, reminders are held back, deadlines are frozen and
+// scheduled grants skip their slots.
+type Pause struct {
+	ID        int64
+	UserID    *int64
+	GroupID   *int64
+	StartsAt  time.Time
+	EndsAt    time.Time
+	Reason    string
+	ResumedAt *time.Time // Set once the scheduler has resumed work after the pause
+	CreatedBy int64
+	CreatedAt time.Time
+}
+
+// IsActive reports whether the pause covers the given time
+func (p *Pause) IsActive(at time.Time) bool {
+	return !at.Before(p.StartsAt) && at.Before(p.EndsAt)
+}
+
+// IsUpcoming reports whether the pause has not started yet
+func (p *Pause) IsUpcoming(now time.Time) bool {
+	return now.Before(p.StartsAt)
+}
+
+// Duration returns how long the pause lasts
+func (p *Pause) Duration() time.Duration {
+	return p.EndsAt.Sub(p.StartsAt)
+}
+
+// Season is a stretch of time the group owner sets for a leaderboard. When it
+// ends, the final standings are archived and, optionally, the top three
+// members get season badges.
+type Season struct {
+	ID          int64
+	GroupID     int64
+	Name        string
+	StartsAt    time.Time
+	EndsAt      time.Time
+	AwardBadges bool
+	ClosedAt    *time.Time // Set once the final standings were archived
+	CreatedBy   int64
+	CreatedAt   time.Time
+	Standings   []*SeasonStanding // Final standings, filled in for closed seasons
+}
+
+// IsActive reports whether the season is running at the given time
+func (s *Season) IsActive(at time.Time) bool {
+	return s.ClosedAt == nil && !at.Before(s.StartsAt) && at.Before(s.EndsAt)
+}
+
+// IsUpcoming reports whether the season has not started yet
+func (s *Season) IsUpcoming(now time.Time) bool {
+	return now.Before(s.StartsAt)
+}
+
+// SeasonStanding is a member's final place in a closed season
+type SeasonStanding struct {
+	SeasonID    int64
+	SeasonName  string
+	UserID      int64
+	Username    string
+	Rank        int
+	Earned      int
+	Completions int
+	Badge       string // 🥇, 🥈 or 🥉 when the season awards badges, else empty
+}
@@ -0,0 +1,260 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultNagEscalateAfter is the number of nags after which the accountability
+// partner is told, unless the task sets its own
+const DefaultNagEscalateAfter = 3
+
+// MaxNagStages caps a nag chain so a forgotten task does not nag forever
+const MaxNagStages = 24
+
+// minNagInterval is the shortest wait between two nags
+const minNagInterval = 15 * time.Minute
+
+// NagEnabled reports whether the task keeps reminding after a missed deadline
+func (p DeadlinePolicy) NagEnabled() bool {
+	return p.NagIntervalMinutes > 0
+}
+
+// EscalateAfter returns the number of nags after which the partner is told
+func (p DeadlinePolicy) EscalateAfter() int {
+	if p.NagEscalateAfter > 0 {
+		return p.NagEscalateAfter
+	}
+	return DefaultNagEscalateAfter
+}
+
+// IsNagPartner reports whether the user is the task's accountability partner
+func (t *Task) IsNagPartner(userID int64) bool {
+	return t.Deadline.NagPartnerID != nil && *t.Deadline.NagPartnerID == userID
+}
+
+// nagDelay returns the wait before the given nag: the configured interval
+// after the missed deadline for the first, halved for every next one down
+// to minNagInterval (or the interval itself, if that is shorter)
+func nagDelay(policy DeadlinePolicy, stage int) time.Duration {
+	interval := time.Duration(policy.NagIntervalMinutes) * time.Minute
+	floor := minNagInterval
+	if interval < floor {
+		floor = interval
+	}
+
+	delay := interval
+	for i := 1; i < stage && delay > floor; i++ {
+		delay /= 2
+	}
+	if delay < floor {
+		delay = floor
+	}
+	return delay
+}
+
+// nagPolicyChanged reports whether the nag settings differ
+func nagPolicyChanged(a, b DeadlinePolicy) bool {
+	if a.NagIntervalMinutes != b.NagIntervalMinutes || a.EscalateAfter() != b.EscalateAfter() {
+		return true
+	}
+	if (a.NagPartnerID == nil) != (b.NagPartnerID == nil) {
+		return true
+	}
+	return a.NagPartnerID != nil && *a.NagPartnerID != *b.NagPartnerID
+}
+
+// validateNagPartner checks that the accountability partner is a group member
+func (s *Service) validateNagPartner(groupID int64, policy DeadlinePolicy) error {
+	if policy.NagPartnerID == nil {
+		return nil
+	}
+	isMember, err := s.store.IsUserInGroup(*policy.NagPartnerID, groupID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return fmt.Errorf("accountability partner must be a member of the group")
+	}
+	return nil
+}
+
+// scheduleFirstNag schedules the first nag of a member for the task's deadline
+func (s *Service) scheduleFirstNag(task *Task, userID int64) error {
+	nag := &TaskNotification{
+		TaskID:           task.ID,
+		UserID:           userID,
+		NotificationType: "nag",
+		Stage:            1,
+		ScheduledAt:      task.MissedAt().Add(nagDelay(task.Deadline, 1)),
+	}
+	if err := s.createNotification(nag); err != nil {
+		return fmt.Errorf("failed to create nag notification: %w", err)
+	}
+	return nil
+}
+
+// scheduleNextNag continues the chain after a nag was sent: the next nag
+// comes sooner, and once the member ignored enough of them their
+// accountability partner is alerted. Completing the task or dismissing the
+// nags deletes the pending one, which ends the chain.
+func (s *Service) scheduleNextNag(notif *TaskNotification, now time.Time) error {
+	task, err := s.store.GetTaskByID(notif.TaskID)
+	if err != nil {
+		// Deleted meanwhile
+		return nil
+	}
+	policy := task.Deadline
+	if !policy.NagEnabled() {
+		return nil
+	}
+	group, err := s.store.GetGroupByID(task.GroupID)
+	if err != nil {
+		return fmt.Errorf("failed to get group: %w", err)
+	}
+	if group.IsArchived() {
+		return nil
+	}
+
+	// A resting member was not nagged; keep the stage until the pause is over
+	if s.pausedAt(notif.UserID, group.ID, now) != nil {
+		return s.createNotification(&TaskNotification{
+			TaskID:           task.ID,
+			UserID:           notif.UserID,
+			NotificationType: "nag",
+			Stage:            notif.Stage,
+			ScheduledAt:      now.Add(nagDelay(policy, notif.Stage)),
+		})
+	}
+
+	if notif.Stage == policy.EscalateAfter() && policy.NagPartnerID != nil && *policy.NagPartnerID != notif.UserID {
+		isMember, err := s.store.IsUserInGroup(*policy.NagPartnerID, group.ID)
+		if err != nil {
+			return err
+		}
+		if isMember {
+			alert := &TaskNotification{
+				TaskID:           task.ID,
+				UserID:           *policy.NagPartnerID,
+				NotificationType: "nag_partner",
+				Stage:            notif.Stage,
+				AboutUserID:      &notif.UserID,
+				ScheduledAt:      now,
+			}
+			if err := s.createNotification(alert); err != nil {
+				return fmt.Errorf("failed to create partner alert: %w", err)
+			}
+		}
+	}
+
+	if notif.Stage >= MaxNagStages {
+		return nil
+	}
+	return s.createNotification(&TaskNotification{
+		TaskID:           task.ID,
+		UserID:           notif.UserID,
+		NotificationType: "nag",
+		Stage:            notif.Stage + 1,
+		ScheduledAt:      now.Add(nagDelay(policy, notif.Stage+1)),
+	})
+}
+
+// rescheduleNags applies changed nag settings of a task whose deadline did
+// not move. Before the deadline is missed the chains start over; after it,
+// running chains pick up the new settings with their next nag, or stop when
+// nagging was turned off.
+func (s *Service) rescheduleNags(task *Task, now time.Time) error {
+	if task.DueAt == nil {
+		return nil
+	}
+	if task.IsLate(now) && task.Deadline.NagEnabled() {
+		return nil
+	}
+
+	if err := s.store.DeleteNagNotifications(task.ID); err != nil {
+		return err
+	}
+	s.wakeNotificationWorker()
+	if task.IsLate(now) || !task.Deadline.NagEnabled() {
+		return nil
+	}
+
+	members, err := s.store.GetUsersByGroupID(task.GroupID)
+	if err != nil {
+		return fmt.Errorf("failed to get group members: %w", err)
+	}
+	for _, member := range members {
+		completed, err := s.completedBeforeDeadline(task, member.ID)
+		if err != nil {
+			return err
+		}
+		if completed {
+			continue
+		}
+		if err := s.scheduleFirstNag(task, member.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DismissNag stops the user's nag reminders for the current deadline of a
+// task, along with a pending alert to their partner, and records the dismissal
+func (s *Service) DismissNag(userID, taskID int64) error {
+	task, err := s.store.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+	isMember, err := s.store.IsUserInGroup(userID, task.GroupID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return fmt.Errorf("user is not a member of this group")
+	}
+
+	stopped, err := s.store.DeleteNagNotificationsForUser(taskID, userID)
+	if err != nil {
+		return err
+	}
+	if !stopped {
+		return fmt.Errorf("no reminders to stop for this task")
+	}
+	s.wakeNotificationWorker()
+
+	now := time.Now()
+	return s.store.CreateNotification(&TaskNotification{
+		TaskID:           taskID,
+		UserID:           userID,
+		NotificationType: "nag_dismissed",
+		ScheduledAt:      now,
+		SentAt:           &now,
+	})
+}
+
+// GetNaggedTaskIDs returns the tasks the user is being nagged about
+func (s *Service) GetNaggedTaskIDs(userID int64) (map[int64]bool, error) {
+	return s.store.GetNaggedTaskIDs(userID)
+}
+
+// nagPartnerMessage renders the alert telling a partner that a member keeps
+// ignoring the nags of a task
+func nagPartnerMessage(notif *TaskNotification, task *Task, member, partner *User) *Message {
+	text := fmt.Sprintf("🚨 Accountability check\n\n%s still hasn't done: %s", member.Username, task.Title)
+	if task.DueAt != nil {
+		text += fmt.Sprintf("\nWas due: %s", task.DueAt.In(partner.Location()).Format("Mon, 02 Jan 2006 at 15:04 MST"))
+	}
+	text += fmt.Sprintf("\nReminders ignored: %d", notif.Stage)
+
+	return &Message{
+		Subject:          fmt.Sprintf("🚨 %s still hasn't done: %s", member.Username, task.Title),
+		Text:             text,
+		Path:             fmt.Sprintf("/groups/%d", task.GroupID),
+		Event:            "task_reminder",
+		NotificationID:   notif.ID,
+		NotificationType: notif.NotificationType,
+		TaskID:           task.ID,
+		TaskTitle:        task.Title,
+		DueAt:            task.DueAt,
+	}
+}
@@ -0,0 +1,272 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// notificationWorkers is how many notifications are delivered at once
+const notificationWorkers = 4
+
+// notificationIdleWait is the longest the worker sleeps without being woken.
+// It picks up queue changes made outside the service, e.g. by hand in the DB.
+const notificationIdleWait = 15 * time.Minute
+
+// Telegram allows about 30 messages per second overall and one per second
+// to the same chat
+const (
+	telegramGlobalInterval = time.Second / 30
+	telegramChatInterval   = time.Second
+)
+
+// notifierLog prints a worker message with the usual prefix
+func notifierLog(format string, args ...interface{}) {
+	fmt.Printf("[NotificationWorker] "+format+"\n", args...)
+}
+
+// StartNotificationWorker delivers notifications over the registered
+// channels until ctx is cancelled. It sleeps until the next notification is
+// due and is woken early whenever the queue changes; due notifications are
+// sent by a small worker pool, Telegram messages within its rate limits.
+//
+// Failed sends are retried with exponential backoff and marked failed after
+// NotificationMaxAttempts, or right away when the user cannot be reached.
+// Wishlist goals, daily digests and scheduled reports are sent via bot, which
+// may be nil without Telegram.
+func (s *Service) StartNotificationWorker(ctx context.Context, bot BotNotifier) {
+	notifier := &limitedNotifier{ctx: ctx, bot: bot, limiter: s.telegramLimiter}
+
+	// Wishlist goals depend on balances, digests and reports on the clock,
+	// not on the queue, so they are still checked every minute
+	var minuteTick <-chan time.Time
+	if bot != nil {
+		minuteTicker := time.NewTicker(1 * time.Minute)
+		defer minuteTicker.Stop()
+		minuteTick = minuteTicker.C
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	notifierLog("Starting notification worker...")
+
+	for {
+		select {
+		case <-ctx.Done():
+			notifierLog("Shutdown signal received, stopping notification worker...")
+			return
+
+		case <-minuteTick:
+			if err := s.NotifyAffordableWishlistItems(notifier); err != nil {
+				notifierLog("Error checking wishlist goals: %v", err)
+			}
+			if err := s.SendDueDigests(notifier, time.Now()); err != nil {
+				notifierLog("Error sending digests: %v", err)
+			}
+			if err := s.SendDueReports(notifier, time.Now()); err != nil {
+				notifierLog("Error sending reports: %v", err)
+			}
+			continue
+
+		case <-s.notificationWake:
+			// The queue changed; work out the next wake-up again
+
+		case <-timer.C:
+			s.deliverDueNotifications(ctx, time.Now())
+		}
+
+		timer.Reset(s.untilNextNotification(time.Now()))
+	}
+}
+
+// wakeNotificationWorker tells the worker the queue changed. It never blocks:
+// one pending wake-up is enough for any number of changes.
+func (s *Service) wakeNotificationWorker() {
+	select {
+	case s.notificationWake <- struct{}{}:
+	default:
+	}
+}
+
+// createNotification stores a notification and wakes the worker for it
+func (s *Service) createNotification(notification *TaskNotification) error {
+	if err := s.store.CreateNotification(notification); err != nil {
+		return err
+	}
+	s.wakeNotificationWorker()
+	return nil
+}
+
+// untilNextNotification returns how long the worker may sleep
+func (s *Service) untilNextNotification(now time.Time) time.Duration {
+	next, ok, err := s.store.GetNextNotificationTime()
+	if err != nil {
+		notifierLog("Error finding the next notification: %v", err)
+		return time.Minute
+	}
+	if !ok {
+		return notificationIdleWait
+	}
+	wait := next.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > notificationIdleWait {
+		wait = notificationIdleWait
+	}
+	return wait
+}
+
+// deliverDueNotifications sends every notification due at now through a
+// bounded pool and waits until all of them are handled
+func (s *Service) deliverDueNotifications(ctx context.Context, now time.Time) {
+	notifications, err := s.store.GetPendingNotifications(now)
+	if err != nil {
+		notifierLog("Error fetching pending notifications: %v", err)
+		return
+	}
+	if len(notifications) == 0 {
+		return
+	}
+
+	notifierLog("Found %d pending notification(s) to send", len(notifications))
+
+	jobs := make(chan *TaskNotification)
+	var wg sync.WaitGroup
+	for i := 0; i < notificationWorkers && i < len(notifications); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for notif := range jobs {
+				s.deliverNotification(ctx, notif, now)
+			}
+		}()
+	}
+
+	for _, notif := range notifications {
+		select {
+		case jobs <- notif:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// deliverNotification defers, sends or records the failure of one notification
+func (s *Service) deliverNotification(ctx context.Context, notif *TaskNotification, now time.Time) {
+	// Hold reminders back until the user's quiet hours are over
+	if until, quiet := s.quietUntil(notif, now); quiet {
+		if err := s.store.DeferNotification(notif.ID, until); err != nil {
+			notifierLog("Error deferring notification %d: %v", notif.ID, err)
+		}
+		return
+	}
+
+	if err := s.sendNotification(ctx, notif); err != nil {
+		// Shutting down is not the recipient's fault; try again after restart
+		if ctx.Err() != nil {
+			return
+		}
+		notifierLog("Error sending notification %d (attempt %d): %v", notif.ID, notif.Attempts+1, err)
+		// Retry later with backoff, or give up after too many attempts
+		if err := s.recordDeliveryFailure(notif, err, now); err != nil {
+			notifierLog("Error recording failure of notification %d: %v", notif.ID, err)
+		}
+		return
+	}
+
+	if err := s.store.MarkNotificationSent(notif.ID); err != nil {
+		// A nag deleted meanwhile, because the task was completed or the
+		// nags dismissed, ends its chain here
+		notifierLog("Error marking notification %d as sent: %v", notif.ID, err)
+		return
+	}
+
+	if notif.NotificationType == "nag" {
+		if err := s.scheduleNextNag(notif, now); err != nil {
+			notifierLog("Error scheduling the nag after notification %d: %v", notif.ID, err)
+		}
+	}
+}
+
+// sendLimiter spaces out messages to keep under a global and a per-chat rate
+type sendLimiter struct {
+	mu       sync.Mutex
+	global   time.Duration
+	perChat  time.Duration
+	nextAny  time.Time
+	nextChat map[int64]time.Time
+}
+
+func newSendLimiter(global, perChat time.Duration) *sendLimiter {
+	return &sendLimiter{
+		global:   global,
+		perChat:  perChat,
+		nextChat: make(map[int64]time.Time),
+	}
+}
+
+// reserve books the earliest free send slot for the chat and returns how
+// long to wait for it
+func (l *sendLimiter) reserve(chatID int64, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	at := now
+	if l.nextAny.After(at) {
+		at = l.nextAny
+	}
+	if next := l.nextChat[chatID]; next.After(at) {
+		at = next
+	}
+	l.nextAny = at.Add(l.global)
+	l.nextChat[chatID] = at.Add(l.perChat)
+
+	// Forget chats whose slot has passed so the map stays small
+	if len(l.nextChat) > 1000 {
+		for id, next := range l.nextChat {
+			if !next.After(now) {
+				delete(l.nextChat, id)
+			}
+		}
+	}
+
+	return at.Sub(now)
+}
+
+// wait blocks until the chat's next send slot or until ctx is done
+func (l *sendLimiter) wait(ctx context.Context, chatID int64) error {
+	wait := l.reserve(chatID, time.Now())
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// limitedNotifier waits for a free send slot before passing a message on
+type limitedNotifier struct {
+	ctx     context.Context
+	bot     BotNotifier
+	limiter *sendLimiter
+}
+
+// SendNotification implements BotNotifier
+func (n *limitedNotifier) SendNotification(chatID int64, message string, buttons map[string]string) error {
+	if err := n.limiter.wait(n.ctx, chatID); err != nil {
+		return err
+	}
+	return n.bot.SendNotification(chatID, message, buttons)
+}
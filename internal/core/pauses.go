@@ -0,0 +1,294 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// maxPauseLength caps a single rest period so nothing stays frozen forever
+const maxPauseLength = 90 * 24 * time.Hour
+
+// validatePause checks the dates of a new pause and fills in defaults
+func validatePause(pause *Pause, now time.Time) error {
+	if pause.StartsAt.IsZero() || pause.StartsAt.Before(now) {
+		pause.StartsAt = now
+	}
+	if pause.EndsAt.IsZero() {
+		return fmt.Errorf("pause end date is required")
+	}
+	if !pause.EndsAt.After(pause.StartsAt) {
+		return fmt.Errorf("pause must end after it starts")
+	}
+	if pause.Duration() > maxPauseLength {
+		return fmt.Errorf("pause cannot be longer than %d days", int(maxPauseLength.Hours()/24))
+	}
+	pause.Reason = strings.TrimSpace(pause.Reason)
+	return nil
+}
+
+// CreateUserPause starts a personal rest period for the user in all their groups
+func (s *Service) CreateUserPause(userID int64, startsAt, endsAt time.Time, reason string) (*Pause, error) {
+	pause := &Pause{UserID: &userID, StartsAt: startsAt, EndsAt: endsAt, Reason: reason, CreatedBy: userID}
+	if err := validatePause(pause, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.store.CreatePause(pause)
+}
+
+// CreateGroupPause puts a whole group to rest (owner only)
+func (s *Service) CreateGroupPause(userID, groupID int64, startsAt, endsAt time.Time, reason string) (*Pause, error) {
+	if _, err := s.requireGroupOwner(userID, groupID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureGroupWritable(groupID); err != nil {
+		return nil, err
+	}
+
+	pause := &Pause{GroupID: &groupID, StartsAt: startsAt, EndsAt: endsAt, Reason: reason, CreatedBy: userID}
+	if err := validatePause(pause, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.store.CreatePause(pause)
+}
+
+// GetUserPauses retrieves the user's current and upcoming personal pauses
+func (s *Service) GetUserPauses(userID int64) ([]*Pause, error) {
+	pauses, err := s.store.GetPausesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return unfinishedPauses(pauses, time.Now()), nil
+}
+
+// GetGroupPauses retrieves the group's current and upcoming pauses
+func (s *Service) GetGroupPauses(groupID int64) ([]*Pause, error) {
+	pauses, err := s.store.GetPausesByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return unfinishedPauses(pauses, time.Now()), nil
+}
+
+// unfinishedPauses drops pauses that have already ended
+func unfinishedPauses(pauses []*Pause, now time.Time) []*Pause {
+	var current []*Pause
+	for _, p := range pauses {
+		if p.EndsAt.After(now) {
+			current = append(current, p)
+		}
+	}
+	return current
+}
+
+// EndPause ends an active pause right away, or cancels one that has not
+// started yet. Users end their own pauses; group pauses need the owner.
+func (s *Service) EndPause(userID, pauseID int64) (*Pause, error) {
+	pause, err := s.store.GetPauseByID(pauseID)
+	if err != nil {
+		return nil, err
+	}
+	if pause.UserID != nil && *pause.UserID != userID {
+		return nil, fmt.Errorf("pause not found")
+	}
+	if pause.GroupID != nil {
+		if _, err := s.requireGroupOwner(userID, *pause.GroupID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if !pause.EndsAt.After(now) {
+		return nil, fmt.Errorf("pause has already ended")
+	}
+	if pause.IsUpcoming(now) {
+		return pause, s.store.DeletePause(pause.ID)
+	}
+
+	if err := s.store.SetPauseEndsAt(pause.ID, now); err != nil {
+		return nil, err
+	}
+	pause.EndsAt = now
+	return pause, s.resumePause(pause, now)
+}
+
+// pausedAt returns the pause that covers the user or group at the given time,
+// or nil. Pass 0 as userID to check the group alone.
+func (s *Service) pausedAt(userID, groupID int64, at time.Time) *Pause {
+	var pauses []*Pause
+	if userID != 0 {
+		userPauses, err := s.store.GetPausesByUserID(userID)
+		if err != nil {
+			log.Printf("Failed to get pauses of user %d: %v", userID, err)
+		}
+		pauses = append(pauses, userPauses...)
+	}
+	groupPauses, err := s.store.GetPausesByGroupID(groupID)
+	if err != nil {
+		log.Printf("Failed to get pauses of group %d: %v", groupID, err)
+	}
+	pauses = append(pauses, groupPauses...)
+
+	for _, p := range pauses {
+		if p.IsActive(at) {
+			return p
+		}
+	}
+	return nil
+}
+
+// GetRestingMembers reports which members of a group are currently on a pause
+func (s *Service) GetRestingMembers(groupID int64) (map[int64]bool, error) {
+	members, err := s.store.GetUsersByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	resting := make(map[int64]bool)
+	for _, member := range members {
+		if s.pausedAt(member.ID, groupID, now) != nil {
+			resting[member.ID] = true
+		}
+	}
+	return resting, nil
+}
+
+// ResumeEndedPauses picks up work held back by pauses that have ended and
+// returns the number of pauses resumed. Each pause is resumed once.
+func (s *Service) ResumeEndedPauses(now time.Time) (int, error) {
+	pauses, err := s.store.GetUnresumedPauses()
+	if err != nil {
+		return 0, err
+	}
+
+	resumed := 0
+	for _, pause := range pauses {
+		if pause.EndsAt.After(now) {
+			continue
+		}
+		if err := s.resumePause(pause, now); err != nil {
+			log.Printf("[Scheduler] Failed to resume pause %d: %v", pause.ID, err)
+			continue
+		}
+		resumed++
+	}
+
+	return resumed, nil
+}
+
+// resumePause moves deadlines that fell inside a group pause back by the
+// pause length, rebuilds reminders and records the pause as resumed
+func (s *Service) resumePause(pause *Pause, now time.Time) error {
+	if pause.GroupID != nil {
+		if err := s.resumeGroup(*pause.GroupID, pause, now); err != nil {
+			return err
+		}
+	}
+	if pause.UserID != nil {
+		groups, err := s.store.GetGroupsByUserID(*pause.UserID)
+		if err != nil {
+			return err
+		}
+		for _, group := range groups {
+			tasks, err := s.store.GetTasksByGroupID(group.ID)
+			if err != nil {
+				return err
+			}
+			for _, task := range tasks {
+				if err := s.catchUpReminders(task, pause, now, *pause.UserID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return s.store.MarkPauseResumed(pause.ID, now)
+}
+
+// resumeGroup unfreezes the deadlines of a group after a pause
+func (s *Service) resumeGroup(groupID int64, pause *Pause, now time.Time) error {
+	tasks, err := s.store.GetTasksByGroupID(groupID)
+	if err != nil {
+		return err
+	}
+	members, err := s.store.GetUsersByGroupID(groupID)
+	if err != nil {
+		return err
+	}
+	memberIDs := make([]int64, 0, len(members))
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.ID)
+	}
+
+	for _, task := range tasks {
+		if task.DueAt == nil {
+			continue
+		}
+
+		// A deadline missed during the pause is moved by the pause length,
+		// so the group gets back exactly the time it was resting
+		if pause.IsActive(task.MissedAt()) {
+			dueAt := task.DueAt.Add(pause.Duration())
+			if err := s.store.SetTaskDueAt(task.ID, &dueAt); err != nil {
+				return err
+			}
+			if err := s.RescheduleNotificationsForTask(task.ID, &dueAt); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.catchUpReminders(task, pause, now, memberIDs...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// catchUpReminders re-sends "before deadline" reminders that were held back
+// during the pause, for tasks that are still due in the future
+func (s *Service) catchUpReminders(task *Task, pause *Pause, now time.Time, userIDs ...int64) error {
+	if task.DueAt == nil || !task.DueAt.After(now) {
+		return nil
+	}
+
+	optedOut, err := s.store.GetTaskReminderOptOuts(task.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if optedOut[userID] {
+			continue
+		}
+		settings, err := s.store.GetNotificationSettings(userID)
+		if err != nil {
+			return fmt.Errorf("failed to get notification settings for user %d: %w", userID, err)
+		}
+		// One catch-up reminder stands in for all that fell into the pause
+		missed := false
+		for _, minutes := range reminderOffsets(task, settings) {
+			if pause.IsActive(task.DueAt.Add(-time.Duration(minutes) * time.Minute)) {
+				missed = true
+			}
+		}
+		if !missed {
+			continue
+		}
+
+		reminder := &TaskNotification{
+			TaskID:           task.ID,
+			UserID:           userID,
+			NotificationType: "before_deadline",
+			ScheduledAt:      now,
+		}
+		if err := s.createNotification(reminder); err != nil {
+			return fmt.Errorf("failed to create before_deadline notification: %w", err)
+		}
+	}
+
+	return nil
+}
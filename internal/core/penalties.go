@@ -0,0 +1,163 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// validateDeadlinePolicy checks the deadline settings of a task
+func validateDeadlinePolicy(policy DeadlinePolicy) error {
+	if policy.PenaltyAmount < 0 {
+		return fmt.Errorf("penalty cannot be negative")
+	}
+	if policy.LateRewardPercent < 0 || policy.LateRewardPercent > 100 {
+		return fmt.Errorf("late reward must be between 0 and 100 percent")
+	}
+	if policy.GraceMinutes < 0 {
+		return fmt.Errorf("grace period cannot be negative")
+	}
+	if err := validateReminderOffsets(policy.ReminderOffsets); err != nil {
+		return err
+	}
+	if policy.NagIntervalMinutes < 0 || policy.NagEscalateAfter < 0 {
+		return fmt.Errorf("nag settings cannot be negative")
+	}
+	if policy.NagPartnerID != nil && !policy.NagEnabled() {
+		return fmt.Errorf("an accountability partner needs nag mode turned on")
+	}
+	return nil
+}
+
+// EvaluateMissedDeadlines records and penalizes members who did not complete a
+// task before its deadline plus grace period, and returns the number of
+// penalties applied. Every deadline is evaluated once; moving DueAt starts a
+// new deadline.
+func (s *Service) EvaluateMissedDeadlines(now time.Time) (int, error) {
+	tasks, err := s.store.GetTasksWithUnevaluatedDeadline()
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, task := range tasks {
+		if !task.IsLate(now) {
+			continue
+		}
+
+		group, err := s.store.GetGroupByID(task.GroupID)
+		if err != nil {
+			continue
+		}
+
+		// Deadlines of a resting group are frozen; resuming the pause moves them
+		if pause := s.pausedAt(0, task.GroupID, task.MissedAt()); pause != nil && pause.ResumedAt == nil {
+			continue
+		}
+
+		// Archived groups are read-only, their deadlines lapse without penalties
+		if !group.IsArchived() {
+			n, err := s.penalizeMissedDeadline(task)
+			applied += n
+			if err != nil {
+				log.Printf("[Scheduler] Failed to evaluate deadline of task %d: %v", task.ID, err)
+				continue
+			}
+		}
+
+		if err := s.store.SetTaskDeadlineEvaluated(task.ID); err != nil {
+			log.Printf("[Scheduler] Failed to mark deadline of task %d: %v", task.ID, err)
+		}
+	}
+
+	return applied, nil
+}
+
+// penalizeMissedDeadline records the missed deadline for reports and applies
+// the task's penalty, if it has one, to each member who missed it
+func (s *Service) penalizeMissedDeadline(task *Task) (int, error) {
+	members, err := s.store.GetUsersByGroupID(task.GroupID)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, member := range members {
+		completed, err := s.completedBeforeDeadline(task, member.ID)
+		if err != nil {
+			return applied, err
+		}
+		if completed {
+			continue
+		}
+		// Members who were resting when the deadline passed are excused
+		if s.pausedAt(member.ID, task.GroupID, task.MissedAt()) != nil {
+			continue
+		}
+
+		if err := s.store.RecordMissedDeadline(task, member.ID); err != nil {
+			return applied, err
+		}
+		if task.Deadline.PenaltyAmount <= 0 {
+			continue
+		}
+
+		// Penalties never push a balance below zero
+		balance, err := s.store.GetBalance(member.ID, task.GroupID)
+		if err != nil {
+			return applied, err
+		}
+		amount := task.Deadline.PenaltyAmount
+		if amount > balance {
+			amount = balance
+		}
+		if amount <= 0 {
+			continue
+		}
+
+		description := fmt.Sprintf("⏰ Missed deadline: %s", task.Title)
+		ok, err := s.store.ApplyTaskPenalty(task, member.ID, amount, description)
+		if err != nil {
+			return applied, err
+		}
+		if ok {
+			applied++
+		}
+	}
+
+	return applied, nil
+}
+
+// completedBeforeDeadline reports whether a member completed the task after
+// the previous deadline (or task creation) and before this one was missed.
+// Undone completions do not count.
+func (s *Service) completedBeforeDeadline(task *Task, userID int64) (bool, error) {
+	return s.completedBefore(task, userID, task.MissedAt())
+}
+
+// completedBefore reports whether a member completed the task after the
+// previous deadline (or task creation) and no later than until
+func (s *Service) completedBefore(task *Task, userID int64, until time.Time) (bool, error) {
+	transactions, err := s.store.GetTaskTransactionsByUser(task.ID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	since := task.CreatedAt
+	if task.DeadlineEvaluatedAt != nil {
+		since = *task.DeadlineEvaluatedAt
+	}
+
+	completions := 0
+	for _, t := range transactions {
+		if t.CreatedAt.Before(since) || t.CreatedAt.After(until) {
+			continue
+		}
+		if t.Amount < 0 {
+			completions--
+		} else {
+			completions++
+		}
+	}
+	return completions > 0, nil
+}
@@ -0,0 +1,265 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuietHours is a daily window, in minutes after local midnight, in which
+// reminders are held back. A window may run past midnight (22:00-07:00);
+// Start == End means no quiet hours.
+type QuietHours struct {
+	Start int
+	End   int
+}
+
+// IsSet reports whether the window covers any time at all
+func (q QuietHours) IsSet() bool {
+	return q.Start != q.End
+}
+
+// String formats the window as "22:00-07:00", or "" when it is not set
+func (q QuietHours) String() string {
+	if !q.IsSet() {
+		return ""
+	}
+	return q.StartClock() + "-" + q.EndClock()
+}
+
+// StartClock formats the start as "22:00" for time inputs, or "" when not set
+func (q QuietHours) StartClock() string {
+	if !q.IsSet() {
+		return ""
+	}
+	return fmt.Sprintf("%02d:%02d", q.Start/60, q.Start%60)
+}
+
+// EndClock formats the end as "07:00" for time inputs, or "" when not set
+func (q QuietHours) EndClock() string {
+	if !q.IsSet() {
+		return ""
+	}
+	return fmt.Sprintf("%02d:%02d", q.End/60, q.End%60)
+}
+
+// ParseQuietHours parses a window such as "22:00-07:00" or "23-9".
+// An empty string or "off" means no quiet hours.
+func ParseQuietHours(spec string) (QuietHours, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" || spec == "off" || spec == "выкл" {
+		return QuietHours{}, nil
+	}
+
+	spec = strings.NewReplacer("–", "-", "—", "-", " to ", "-", " до ", "-").Replace(spec)
+	parts := strings.Split(spec, "-")
+	if len(parts) != 2 {
+		return QuietHours{}, fmt.Errorf("quiet hours must look like 22:00-07:00")
+	}
+	start, err := parseClockMinutes(parts[0])
+	if err != nil {
+		return QuietHours{}, err
+	}
+	end, err := parseClockMinutes(parts[1])
+	if err != nil {
+		return QuietHours{}, err
+	}
+	if start == end {
+		return QuietHours{}, fmt.Errorf("quiet hours must start and end at different times")
+	}
+	return QuietHours{Start: start, End: end}, nil
+}
+
+// parseClockMinutes parses "7", "07:30" or "7.30" into minutes after midnight
+func parseClockMinutes(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	hourPart, minutePart, hasMinutes := strings.Cut(strings.Replace(s, ".", ":", 1), ":")
+	hour, err := strconv.Atoi(hourPart)
+	if err != nil || hour < 0 || hour > 24 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	minute := 0
+	if hasMinutes {
+		minute, err = strconv.Atoi(minutePart)
+		if err != nil || len(minutePart) != 2 || minute < 0 || minute > 59 {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+	}
+	// "24" and "24:00" are accepted as midnight
+	if hour == 24 {
+		if minute != 0 {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+		hour = 0
+	}
+	return hour*60 + minute, nil
+}
+
+// quietWeekdays lists the days in the order they are stored and shown
+var quietWeekdays = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+// QuietWeekdays returns the weekdays in display order, Monday first
+func QuietWeekdays() []time.Weekday {
+	return quietWeekdays
+}
+
+// ParseWeekday parses an English or Russian weekday name such as "sat" or "суббота"
+func ParseWeekday(name string) (time.Weekday, bool) {
+	day, ok := naturalWeekdays[strings.ToLower(strings.TrimSpace(name))]
+	return day, ok
+}
+
+// WeekdayKey is the short name a weekday is stored under, e.g. "sat"
+func WeekdayKey(day time.Weekday) string {
+	return strings.ToLower(day.String()[:3])
+}
+
+// FormatQuietDays encodes per-weekday overrides as "fri=23:00-10:00,sun=off"
+func FormatQuietDays(days map[time.Weekday]QuietHours) string {
+	var parts []string
+	for _, day := range quietWeekdays {
+		hours, ok := days[day]
+		if !ok {
+			continue
+		}
+		spec := hours.String()
+		if spec == "" {
+			spec = "off"
+		}
+		parts = append(parts, WeekdayKey(day)+"="+spec)
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseQuietDays decodes per-weekday overrides written by FormatQuietDays
+func ParseQuietDays(spec string) (map[time.Weekday]QuietHours, error) {
+	days := make(map[time.Weekday]QuietHours)
+	for _, part := range strings.Split(spec, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, window, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid quiet hours override %q", part)
+		}
+		day, ok := ParseWeekday(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", name)
+		}
+		hours, err := ParseQuietHours(window)
+		if err != nil {
+			return nil, err
+		}
+		days[day] = hours
+	}
+	return days, nil
+}
+
+// QuietHoursOn returns the window that starts on the given weekday: the
+// override for that day if there is one, otherwise the everyday window
+func (n *NotificationSettings) QuietHoursOn(day time.Weekday) QuietHours {
+	if hours, ok := n.QuietDays[day]; ok {
+		return hours
+	}
+	return n.QuietHours
+}
+
+// HasQuietHours reports whether any day has quiet hours
+func (n *NotificationSettings) HasQuietHours() bool {
+	for _, day := range quietWeekdays {
+		if n.QuietHoursOn(day).IsSet() {
+			return true
+		}
+	}
+	return false
+}
+
+// QuietUntil reports whether at falls into the user's quiet hours in loc and,
+// if so, when they end. Back-to-back windows are followed to the last one.
+func (n *NotificationSettings) QuietUntil(at time.Time, loc *time.Location) (time.Time, bool) {
+	until, ok := n.quietWindowEnd(at, loc)
+	if !ok {
+		return time.Time{}, false
+	}
+	// A week of windows is the most that can chain together
+	for i := 1; i < len(quietWeekdays); i++ {
+		end, ok := n.quietWindowEnd(until, loc)
+		if !ok {
+			break
+		}
+		until = end
+	}
+	return until, true
+}
+
+// quietWindowEnd returns the end of the window covering at, checking the
+// window that started yesterday (it may run past midnight) and today's
+func (n *NotificationSettings) quietWindowEnd(at time.Time, loc *time.Location) (time.Time, bool) {
+	local := at.In(loc)
+	for _, offset := range []int{-1, 0} {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, loc)
+		hours := n.QuietHoursOn(day.Weekday())
+		if !hours.IsSet() {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), hours.Start/60, hours.Start%60, 0, 0, loc)
+		endDay := day.Day()
+		if hours.End < hours.Start {
+			endDay++
+		}
+		end := time.Date(day.Year(), day.Month(), endDay, hours.End/60, hours.End%60, 0, 0, loc)
+		if !at.Before(start) && at.Before(end) {
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// UpdateQuietHours replaces the user's quiet hours, weekday overrides and
+// whether urgent deadlines may break through them
+func (s *Service) UpdateQuietHours(userID int64, hours QuietHours, days map[time.Weekday]QuietHours, urgentDuringQuiet bool) error {
+	settings, err := s.store.GetNotificationSettings(userID)
+	if err != nil {
+		return err
+	}
+	settings.QuietHours = hours
+	settings.QuietDays = days
+	settings.UrgentDuringQuiet = urgentDuringQuiet
+	return s.store.UpdateNotificationSettings(settings)
+}
+
+// quietUntil reports whether a due notification falls into its user's quiet
+// hours and when it may be delivered instead. The on-deadline reminder of an
+// urgent task and its nags go through if the user opted in.
+func (s *Service) quietUntil(notif *TaskNotification, now time.Time) (time.Time, bool) {
+	settings, err := s.store.GetNotificationSettings(notif.UserID)
+	if err != nil {
+		log.Printf("Failed to get notification settings of user %d: %v", notif.UserID, err)
+		return time.Time{}, false
+	}
+	if !settings.HasQuietHours() {
+		return time.Time{}, false
+	}
+
+	user, err := s.store.GetUserByID(notif.UserID)
+	if err != nil {
+		log.Printf("Failed to get user %d: %v", notif.UserID, err)
+		return time.Time{}, false
+	}
+	until, quiet := settings.QuietUntil(now, user.Location())
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	if (notif.NotificationType == "on_deadline" || notif.NotificationType == "nag") && settings.UrgentDuringQuiet {
+		task, err := s.store.GetTaskByID(notif.TaskID)
+		if err == nil && task.Deadline.Urgent {
+			return time.Time{}, false
+		}
+	}
+	return until, true
+}
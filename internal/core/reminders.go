@@ -0,0 +1,259 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxReminderOffsets limits the reminders a task may send before its deadline
+const MaxReminderOffsets = 10
+
+// maxReminderOffset is the earliest a reminder may come before the deadline
+const maxReminderOffset = 30 * 24 * 60
+
+// reminderUnits maps the units of ParseReminderOffsets to minutes
+var reminderUnits = map[string]int{
+	"": 1, "m": 1, "min": 1, "м": 1, "мин": 1,
+	"h": 60, "ч": 60,
+	"d": 24 * 60, "д": 24 * 60,
+	"w": 7 * 24 * 60, "н": 7 * 24 * 60,
+}
+
+// ParseReminderOffsets reads a list of reminder offsets such as
+// "1d, 2h, 10m" (or "1д 2ч 10м"); a number without a unit counts minutes and
+// "1h30m" combines units. It returns the offsets in minutes, latest reminder
+// last, without duplicates. An empty spec returns nil.
+func ParseReminderOffsets(spec string) ([]int, error) {
+	fields := strings.FieldsFunc(strings.ToLower(spec), func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+
+	seen := make(map[int]bool)
+	var offsets []int
+	for _, field := range fields {
+		minutes, err := parseReminderOffset(field)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[minutes] {
+			seen[minutes] = true
+			offsets = append(offsets, minutes)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+	return offsets, nil
+}
+
+// parseReminderOffset reads one offset such as "2h" or "1h30m"
+func parseReminderOffset(field string) (int, error) {
+	runes := []rune(field)
+	total := 0
+	for i := 0; i < len(runes); {
+		start := i
+		for i < len(runes) && unicode.IsDigit(runes[i]) {
+			i++
+		}
+		if start == i {
+			return 0, fmt.Errorf("invalid reminder time %q", field)
+		}
+		n, err := strconv.Atoi(string(runes[start:i]))
+		if err != nil {
+			return 0, fmt.Errorf("invalid reminder time %q", field)
+		}
+
+		unitStart := i
+		for i < len(runes) && !unicode.IsDigit(runes[i]) {
+			i++
+		}
+		unit, ok := reminderUnits[string(runes[unitStart:i])]
+		if !ok {
+			return 0, fmt.Errorf("invalid reminder time %q: use d, h or m", field)
+		}
+		total += n * unit
+	}
+	return total, nil
+}
+
+// FormatReminderOffsets renders offsets in minutes the way
+// ParseReminderOffsets reads them, e.g. "1d, 2h, 10m"
+func FormatReminderOffsets(offsets []int) string {
+	parts := make([]string, len(offsets))
+	for i, minutes := range offsets {
+		parts[i] = formatReminderOffset(minutes)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatReminderOffset renders one offset in its largest whole units
+func formatReminderOffset(minutes int) string {
+	var b strings.Builder
+	for _, unit := range []struct {
+		name    string
+		minutes int
+	}{{"d", 24 * 60}, {"h", 60}, {"m", 1}} {
+		if minutes >= unit.minutes {
+			fmt.Fprintf(&b, "%d%s", minutes/unit.minutes, unit.name)
+			minutes %= unit.minutes
+		}
+	}
+	if b.Len() == 0 {
+		return "0m"
+	}
+	return b.String()
+}
+
+// validateReminderOffsets checks the reminder offsets of a task
+func validateReminderOffsets(offsets []int) error {
+	if len(offsets) > MaxReminderOffsets {
+		return fmt.Errorf("a task can have at most %d reminders before its deadline", MaxReminderOffsets)
+	}
+	for _, minutes := range offsets {
+		if minutes <= 0 {
+			return fmt.Errorf("reminders must come before the deadline")
+		}
+		if minutes > maxReminderOffset {
+			return fmt.Errorf("reminders can come at most 30 days before the deadline")
+		}
+	}
+	return nil
+}
+
+// sameReminderOffsets reports whether two offset lists are equal
+func sameReminderOffsets(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// reminderOffsets returns the minutes before the deadline a member is
+// reminded of the task: the task's own list, or the member's default
+func reminderOffsets(task *Task, settings *NotificationSettings) []int {
+	if len(task.Deadline.ReminderOffsets) > 0 {
+		return task.Deadline.ReminderOffsets
+	}
+	return []int{settings.ReminderDeltaMinutes}
+}
+
+// scheduleDeadlineReminders creates a member's "before_deadline" reminders at
+// the task's offsets that are still ahead, and the "on_deadline" reminder
+func (s *Service) scheduleDeadlineReminders(task *Task, userID int64, now time.Time) error {
+	settings, err := s.store.GetNotificationSettings(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get notification settings for user %d: %w", userID, err)
+	}
+
+	onDeadlineNotif := &TaskNotification{
+		TaskID:           task.ID,
+		UserID:           userID,
+		NotificationType: "on_deadline",
+		ScheduledAt:      *task.DueAt,
+	}
+	if err := s.createNotification(onDeadlineNotif); err != nil {
+		return fmt.Errorf("failed to create on_deadline notification: %w", err)
+	}
+
+	for _, minutes := range reminderOffsets(task, settings) {
+		beforeDeadline := task.DueAt.Add(-time.Duration(minutes) * time.Minute)
+		// Only schedule if it's still in the future
+		if !beforeDeadline.After(now) {
+			continue
+		}
+		beforeDeadlineNotif := &TaskNotification{
+			TaskID:           task.ID,
+			UserID:           userID,
+			NotificationType: "before_deadline",
+			ScheduledAt:      beforeDeadline,
+		}
+		if err := s.createNotification(beforeDeadlineNotif); err != nil {
+			return fmt.Errorf("failed to create before_deadline notification: %w", err)
+		}
+	}
+	return nil
+}
+
+// rescheduleDeadlineReminders replaces the pending deadline reminders of a
+// task after its reminder offsets changed. Members who already completed the
+// task or opted out of its reminders are skipped.
+func (s *Service) rescheduleDeadlineReminders(task *Task, now time.Time) error {
+	if err := s.store.DeleteDeadlineReminders(task.ID, 0); err != nil {
+		return err
+	}
+	s.wakeNotificationWorker()
+	if task.DueAt == nil || !task.DueAt.After(now) {
+		return nil
+	}
+
+	members, err := s.store.GetUsersByGroupID(task.GroupID)
+	if err != nil {
+		return fmt.Errorf("failed to get group members: %w", err)
+	}
+	optedOut, err := s.store.GetTaskReminderOptOuts(task.ID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if optedOut[member.ID] {
+			continue
+		}
+		completed, err := s.completedBeforeDeadline(task, member.ID)
+		if err != nil {
+			return err
+		}
+		if completed {
+			continue
+		}
+		if err := s.scheduleDeadlineReminders(task, member.ID, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetTaskReminderOptOut turns the user's reminders before and at the deadline
+// of a task off or back on. Nag reminders are stopped separately.
+func (s *Service) SetTaskReminderOptOut(userID, taskID int64, optOut bool) error {
+	task, err := s.store.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+	isMember, err := s.store.IsUserInGroup(userID, task.GroupID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return fmt.Errorf("user is not a member of this group")
+	}
+
+	if err := s.store.SetTaskReminderOptOut(taskID, userID, optOut); err != nil {
+		return err
+	}
+	if err := s.store.DeleteDeadlineReminders(taskID, userID); err != nil {
+		return err
+	}
+	s.wakeNotificationWorker()
+
+	now := time.Now()
+	if optOut || task.DueAt == nil || !task.DueAt.After(now) {
+		return nil
+	}
+	completed, err := s.completedBeforeDeadline(task, userID)
+	if err != nil || completed {
+		return err
+	}
+	return s.scheduleDeadlineReminders(task, userID, now)
+}
+
+// GetReminderOptOuts returns the tasks the user turned reminders off for
+func (s *Service) GetReminderOptOuts(userID int64) (map[int64]bool, error) {
+	return s.store.GetReminderOptOuts(userID)
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReminderOffsets(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []int
+	}{
+		{"", nil},
+		{"1d, 2h, 10m", []int{1440, 120, 10}},
+		{"10m 1d 2h", []int{1440, 120, 10}},
+		{"1h30m", []int{90}},
+		{"45", []int{45}},
+		{"1w", []int{10080}},
+		{"60m, 1h", []int{60}},
+		{"1Д; 2ч 10мин", []int{1440, 120, 10}},
+	}
+	for _, tt := range tests {
+		got, err := ParseReminderOffsets(tt.input)
+		if err != nil {
+			t.Errorf("ParseReminderOffsets(%q) error: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseReminderOffsets(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"soon", "2x", "h", "1d,,later"} {
+		if got, err := ParseReminderOffsets(input); err == nil {
+			t.Errorf("ParseReminderOffsets(%q) = %v, want an error", input, got)
+		}
+	}
+}
+
+func TestFormatReminderOffsets(t *testing.T) {
+	got := FormatReminderOffsets([]int{1440, 150, 10})
+	if want := "1d, 2h30m, 10m"; got != want {
+		t.Errorf("FormatReminderOffsets = %q, want %q", got, want)
+	}
+
+	// Formatted offsets read back the same
+	offsets, err := ParseReminderOffsets(got)
+	if err != nil || !reflect.DeepEqual(offsets, []int{1440, 150, 10}) {
+		t.Errorf("ParseReminderOffsets(%q) = %v, %v", got, offsets, err)
+	}
+}
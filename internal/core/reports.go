@@ -0,0 +1,492 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report periods
+const (
+	ReportWeek  = "week"
+	ReportMonth = "month"
+)
+
+// reportStreakLookback is how many days before a period are searched for
+// the start of the streak running into it
+const reportStreakLookback = 90
+
+// reportBusiestDays is how many days a report highlights as the busiest
+const reportBusiestDays = 3
+
+// defaultReportMinutes is when a scheduled report is sent, in minutes after
+// local midnight, for users without a morning digest
+const defaultReportMinutes = 9 * 60
+
+// Report summarizes a week or month in a group, for one member or all of them
+type Report struct {
+	Group         *Group
+	Member        *User     // nil for the whole group
+	Period        string    // ReportWeek or ReportMonth
+	Back          int       // Periods before the current one, 0 = current
+	Start         time.Time // Local midnight the period starts at
+	End           time.Time // Local midnight after its last day
+	Tasks         []*ReportTask
+	Members       []*ReportMember
+	Days          []*ReportDay // Every day of the period, in order
+	Purchases     int
+	Streak        int // Days in a row with a completion, up to the end of the period
+	LongestStreak int // Longest run of such days within the period
+}
+
+// ReportTask is the completions of one task in a report
+type ReportTask struct {
+	TaskID      int64
+	Title       string
+	Completions int
+	Quantity    int // Units done, for integer tasks
+	Earned      int
+}
+
+// ReportMember is a member's totals in a report
+type ReportMember struct {
+	UserID      int64
+	Username    string
+	Completions int
+	Earned      int // Cheese from tasks
+	Spent       int // Cheese spent in the shop
+	Penalties   int // Cheese lost to missed deadlines
+	Missed      int // Deadlines missed
+}
+
+// ReportDay is one day of a report
+type ReportDay struct {
+	Date        time.Time // Local midnight
+	Completions int
+	Earned      int
+}
+
+// ParseReportPeriod reads "week" or "month", in English or Russian
+func ParseReportPeriod(s string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "week", "weekly", "неделя", "неделю":
+		return ReportWeek, true
+	case "month", "monthly", "месяц":
+		return ReportMonth, true
+	}
+	return "", false
+}
+
+// ReportRange returns the local days a report covers: the week (from Monday)
+// or month containing now, moved back by the given number of periods
+func ReportRange(period string, now time.Time, back int) (start, end time.Time) {
+	day := startOfDay(now)
+	if period == ReportMonth {
+		start = time.Date(day.Year(), day.Month()-time.Month(back), 1, 0, 0, 0, 0, day.Location())
+		return start, start.AddDate(0, 1, 0)
+	}
+	sinceMonday := (int(day.Weekday()) + 6) % 7
+	start = day.AddDate(0, 0, -sinceMonday-7*back)
+	return start, start.AddDate(0, 0, 7)
+}
+
+// LastDay returns local midnight of the period's last day
+func (r *Report) LastDay() time.Time {
+	return r.End.AddDate(0, 0, -1)
+}
+
+// Title names the period, e.g. "Oct 12 – Oct 18, 2026" or "October 2026"
+func (r *Report) Title() string {
+	if r.Period == ReportMonth {
+		return r.Start.Format("January 2006")
+	}
+	return r.Start.Format("Jan 2") + " – " + r.LastDay().Format("Jan 2, 2006")
+}
+
+// Completions returns the task completions of all members in the report
+func (r *Report) Completions() int {
+	n := 0
+	for _, m := range r.Members {
+		n += m.Completions
+	}
+	return n
+}
+
+// Earned returns the cheese earned with tasks in the report
+func (r *Report) Earned() int {
+	n := 0
+	for _, m := range r.Members {
+		n += m.Earned
+	}
+	return n
+}
+
+// Spent returns the cheese spent in the shop in the report
+func (r *Report) Spent() int {
+	n := 0
+	for _, m := range r.Members {
+		n += m.Spent
+	}
+	return n
+}
+
+// Penalties returns the cheese lost to missed deadlines in the report
+func (r *Report) Penalties() int {
+	n := 0
+	for _, m := range r.Members {
+		n += m.Penalties
+	}
+	return n
+}
+
+// Missed returns the deadlines missed in the report
+func (r *Report) Missed() int {
+	n := 0
+	for _, m := range r.Members {
+		n += m.Missed
+	}
+	return n
+}
+
+// BusiestDays returns the days with the most completions, at most
+// reportBusiestDays of them
+func (r *Report) BusiestDays() []*ReportDay {
+	var days []*ReportDay
+	for _, d := range r.Days {
+		if d.Completions > 0 {
+			days = append(days, d)
+		}
+	}
+	sort.SliceStable(days, func(i, j int) bool {
+		return days[i].Completions > days[j].Completions
+	})
+	if len(days) > reportBusiestDays {
+		days = days[:reportBusiestDays]
+	}
+	return days
+}
+
+// DayPercent returns a day's completions relative to the busiest day, for bars
+func (r *Report) DayPercent(day *ReportDay) int {
+	max := 0
+	for _, d := range r.Days {
+		if d.Completions > max {
+			max = d.Completions
+		}
+	}
+	if max == 0 || day.Completions <= 0 {
+		return 0
+	}
+	return day.Completions * 100 / max
+}
+
+// BuildReport summarizes a week or month of a group for the viewer, who must
+// be a member. memberID narrows the report to one member, 0 covers everyone.
+// Days are counted in the viewer's timezone.
+func (s *Service) BuildReport(viewerID, groupID, memberID int64, period string, back int, now time.Time) (*Report, error) {
+	if period != ReportWeek && period != ReportMonth {
+		return nil, fmt.Errorf("unknown report period %q", period)
+	}
+	if back < 0 {
+		return nil, fmt.Errorf("reports cover past periods only")
+	}
+	isMember, err := s.store.IsUserInGroup(viewerID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, fmt.Errorf("user is not a member of this group")
+	}
+	viewer, err := s.store.GetUserByID(viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	group, err := s.store.GetGroupByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.store.GetUsersByGroupID(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+	report := &Report{Group: group, Period: period, Back: back}
+	if memberID != 0 {
+		for _, m := range members {
+			if m.ID == memberID {
+				report.Member = m
+			}
+		}
+		if report.Member == nil {
+			return nil, fmt.Errorf("user is not a member of this group")
+		}
+		members = []*User{report.Member}
+	}
+	report.Start, report.End = ReportRange(period, now.In(viewer.Location()), back)
+
+	report.Tasks, err = s.store.GetTaskReport(groupID, memberID, report.Start, report.End)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.store.GetMemberReport(groupID, memberID, report.Start, report.End)
+	if err != nil {
+		return nil, err
+	}
+	missed, err := s.store.GetMissedDeadlineCounts(groupID, report.Start, report.End)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		row := totals[m.ID]
+		if row == nil {
+			row = &ReportMember{UserID: m.ID}
+		}
+		row.Username = m.Username
+		row.Missed = missed[m.ID]
+		report.Members = append(report.Members, row)
+	}
+
+	report.Purchases, err = s.store.CountPurchases(groupID, memberID, report.Start, report.End)
+	if err != nil {
+		return nil, err
+	}
+
+	// Days reach back before the period so the streak running into it counts
+	lookback := report.Start.AddDate(0, 0, -reportStreakLookback)
+	days, err := s.store.GetDailyReport(groupID, memberID, lookback, report.End)
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]*ReportDay)
+	for _, d := range days {
+		byDate[d.Date.Format("2006-01-02")] = d
+	}
+	active := func(day time.Time) bool {
+		d := byDate[day.Format("2006-01-02")]
+		return d != nil && d.Completions > 0
+	}
+
+	run := 0
+	for day := report.Start; day.Before(report.End); day = day.AddDate(0, 0, 1) {
+		row := &ReportDay{Date: day}
+		if d := byDate[day.Format("2006-01-02")]; d != nil {
+			row.Completions, row.Earned = d.Completions, d.Earned
+		}
+		report.Days = append(report.Days, row)
+
+		if row.Completions > 0 {
+			run++
+		} else {
+			run = 0
+		}
+		if run > report.LongestStreak {
+			report.LongestStreak = run
+		}
+	}
+
+	// A streak is still alive today if the day just has no completion yet
+	day := report.LastDay()
+	if today := startOfDay(now.In(viewer.Location())); day.After(today) {
+		day = today
+	}
+	if !active(day) {
+		day = day.AddDate(0, 0, -1)
+	}
+	for !day.Before(lookback) && active(day) {
+		report.Streak++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return report, nil
+}
+
+// Markdown renders the report as a Markdown document
+func (r *Report) Markdown() string {
+	var b strings.Builder
+
+	kind := "Weekly"
+	if r.Period == ReportMonth {
+		kind = "Monthly"
+	}
+	fmt.Fprintf(&b, "# %s report: %s\n\n", kind, r.Group.Name)
+	if r.Member != nil {
+		fmt.Fprintf(&b, "%s · %s\n\n", r.Member.Username, r.Title())
+	} else {
+		fmt.Fprintf(&b, "All members · %s\n\n", r.Title())
+	}
+
+	b.WriteString("## Summary\n\n")
+	fmt.Fprintf(&b, "- Quests done: %d\n", r.Completions())
+	fmt.Fprintf(&b, "- Cheese earned: %d\n", r.Earned())
+	fmt.Fprintf(&b, "- Cheese spent: %d\n- Purchases: %d\n", r.Spent(), r.Purchases)
+	fmt.Fprintf(&b, "- Missed deadlines: %d", r.Missed())
+	if r.Penalties() > 0 {
+		fmt.Fprintf(&b, " (−%d cheese)", r.Penalties())
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "- Streak: %d days (longest in this %s: %d)\n", r.Streak, r.Period, r.LongestStreak)
+	if busiest := r.BusiestDays(); len(busiest) > 0 {
+		var parts []string
+		for _, d := range busiest {
+			parts = append(parts, fmt.Sprintf("%s (%d)", d.Date.Format("Mon Jan 2"), d.Completions))
+		}
+		fmt.Fprintf(&b, "- Busiest days: %s\n", strings.Join(parts, ", "))
+	}
+
+	if len(r.Tasks) > 0 {
+		b.WriteString("\n## Quests\n\n| Quest | Done | Cheese |\n|---|---:|---:|\n")
+		for _, t := range r.Tasks {
+			fmt.Fprintf(&b, "| %s | %d | %d |\n", markdownCell(t.Title), t.Completions, t.Earned)
+		}
+	}
+
+	if r.Member == nil && len(r.Members) > 0 {
+		b.WriteString("\n## Members\n\n| Member | Done | Earned | Spent | Missed |\n|---|---:|---:|---:|---:|\n")
+		for _, m := range r.Members {
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %d |\n", markdownCell(m.Username), m.Completions, m.Earned, m.Spent, m.Missed)
+		}
+	}
+
+	b.WriteString("\n## Days\n\n| Day | Done | Cheese |\n|---|---:|---:|\n")
+	for _, d := range r.Days {
+		fmt.Fprintf(&b, "| %s | %d | %d |\n", d.Date.Format("Mon Jan 2"), d.Completions, d.Earned)
+	}
+	return b.String()
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// Summary renders the report as a short Telegram message
+func (r *Report) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📁 %s · %s\n", r.Group.Name, r.Title())
+	fmt.Fprintf(&b, "✅ %d quests done · 🧀 +%d earned · 🛒 %d spent\n", r.Completions(), r.Earned(), r.Spent())
+	fmt.Fprintf(&b, "⏰ %d missed deadlines · 🔥 %d-day streak", r.Missed(), r.Streak)
+	if busiest := r.BusiestDays(); len(busiest) > 0 {
+		fmt.Fprintf(&b, "\n📈 Busiest: %s (%d)", busiest[0].Date.Format("Mon Jan 2"), busiest[0].Completions)
+	}
+	if len(r.Tasks) > 0 {
+		top := r.Tasks[0]
+		fmt.Fprintf(&b, "\n🏅 Top quest: %s ×%d", top.Title, top.Completions)
+	}
+	return b.String()
+}
+
+// UpdateReportSchedule sets whether the user gets a report of the past week
+// or month via the bot; "" turns it off
+func (s *Service) UpdateReportSchedule(userID int64, period string) error {
+	if period != "" && period != ReportWeek && period != ReportMonth {
+		return fmt.Errorf("unknown report period %q", period)
+	}
+	settings, err := s.store.GetNotificationSettings(userID)
+	if err != nil {
+		return err
+	}
+	settings.ReportPeriod = period
+	return s.store.UpdateNotificationSettings(settings)
+}
+
+// BuildMemberReports builds the user's own report in each of their active groups
+func (s *Service) BuildMemberReports(userID int64, period string, back int, now time.Time) ([]*Report, error) {
+	groups, err := s.store.GetGroupsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+	var reports []*Report
+	for _, group := range groups {
+		if group.IsArchived() {
+			continue
+		}
+		report, err := s.BuildReport(userID, group.ID, userID, period, back, now)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// SendDueReports sends scheduled reports of the period that just ended, on
+// the first day of the next one at the user's morning digest time (or 9:00)
+func (s *Service) SendDueReports(bot BotNotifier, now time.Time) error {
+	all, err := s.store.GetDigestSettings()
+	if err != nil {
+		return err
+	}
+
+	for _, settings := range all {
+		if settings.ReportPeriod == "" {
+			continue
+		}
+		user, err := s.store.GetUserByID(settings.UserID)
+		if err != nil || user.TelegramID == nil {
+			continue
+		}
+		profile, err := s.store.GetUserProfile(user.ID)
+		if err == nil && profile != nil && !profile.NotificationEnabled {
+			continue
+		}
+
+		local := now.In(user.Location())
+		start, _ := ReportRange(settings.ReportPeriod, local, 0)
+		if !start.Equal(startOfDay(local)) {
+			continue
+		}
+		minutes := defaultReportMinutes
+		if settings.MorningDigest != nil {
+			minutes = *settings.MorningDigest
+		}
+		sendAt := start.Add(time.Duration(minutes) * time.Minute)
+		if now.Before(sendAt) || now.Sub(sendAt) > digestWindow {
+			continue
+		}
+		if err := s.sendReport(bot, user, settings.ReportPeriod, start.Format("2006-01-02"), now); err != nil {
+			log.Printf("Failed to send %s report to user %d: %v", settings.ReportPeriod, user.ID, err)
+		}
+	}
+	return nil
+}
+
+// sendReport sends the user's reports of the past period unless they were
+// already sent, like sendDigest
+func (s *Service) sendReport(bot BotNotifier, user *User, period, day string, now time.Time) error {
+	kind := "report_" + period
+	claimed, err := s.store.ClaimDigest(user.ID, kind, day)
+	if err != nil || !claimed {
+		return err
+	}
+
+	reports, err := s.BuildMemberReports(user.ID, period, 1, now)
+	if err == nil && len(reports) == 0 {
+		return nil
+	}
+	if err == nil {
+		err = bot.SendNotification(*user.TelegramID, ReportMessage(reports), nil)
+	}
+	if err != nil && !errors.Is(err, ErrRecipientBlocked) {
+		if releaseErr := s.store.ReleaseDigest(user.ID, kind, day); releaseErr != nil {
+			log.Printf("Failed to release %s of user %d: %v", kind, user.ID, releaseErr)
+		}
+	}
+	return err
+}
+
+// ReportMessage renders reports of several groups as one Telegram message
+func ReportMessage(reports []*Report) string {
+	heading := "📊 Weekly report"
+	if len(reports) > 0 && reports[0].Period == ReportMonth {
+		heading = "📊 Monthly report"
+	}
+	parts := []string{heading}
+	for _, r := range reports {
+		parts = append(parts, r.Summary())
+	}
+	return strings.Join(parts, "\n\n") + "\n\nThe full report with every quest and day is on the web, under each group's report."
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// groupPurgeInterval is how often the scheduler looks for groups to purge
+const groupPurgeInterval = time.Hour
+
+// StartScheduler runs the service's periodic jobs until ctx is cancelled:
+// resuming ended pauses, scheduled grants, missed-deadline penalties and
+// closing ended seasons every minute and the purge of deleted groups hourly.
+// Jobs are idempotent, so a restart never repeats work that already ran.
+func (s *Service) StartScheduler(ctx context.Context) {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	log.Println("[Scheduler] Started, checking every minute")
+
+	var lastPurge time.Time
+	for {
+		now := time.Now()
+
+		// Resume first so deadlines frozen by a pause are moved before evaluation
+		if resumed, err := s.ResumeEndedPauses(now); err != nil {
+			log.Printf("[Scheduler] Error resuming pauses: %v", err)
+		} else if resumed > 0 {
+			log.Printf("[Scheduler] Resumed %d pause(s)", resumed)
+		}
+
+		if paid, err := s.RunDueGrants(now); err != nil {
+			log.Printf("[Scheduler] Error running scheduled grants: %v", err)
+		} else if paid > 0 {
+			log.Printf("[Scheduler] Paid %d scheduled grant(s)", paid)
+		}
+
+		if applied, err := s.EvaluateMissedDeadlines(now); err != nil {
+			log.Printf("[Scheduler] Error evaluating deadlines: %v", err)
+		} else if applied > 0 {
+			log.Printf("[Scheduler] Applied %d missed-deadline penalties", applied)
+		}
+
+		if closed, err := s.CloseEndedSeasons(now); err != nil {
+			log.Printf("[Scheduler] Error closing seasons: %v", err)
+		} else if closed > 0 {
+			log.Printf("[Scheduler] Closed %d season(s)", closed)
+		}
+
+		if now.Sub(lastPurge) >= groupPurgeInterval {
+			lastPurge = now
+			if purged, err := s.PurgeDeletedGroups(now); err != nil {
+				log.Printf("[Scheduler] Error purging groups: %v", err)
+			} else if purged > 0 {
+				log.Printf("[Scheduler] Purged %d group(s)", purged)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("[Scheduler] Stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
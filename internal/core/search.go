@@ -0,0 +1,71 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Search result kinds
+const (
+	SearchTask        = "task"
+	SearchItem        = "item"
+	SearchTransaction = "transaction"
+)
+
+// searchLimit is how many results a search returns
+const searchLimit = 50
+
+// searchMaxTerms is how many words of a query are used
+const searchMaxTerms = 8
+
+// SearchResult is a task, shop item or history entry matching a search
+type SearchResult struct {
+	Kind      string
+	ID        int64 // Task, shop item or transaction ID
+	GroupID   int64
+	GroupName string
+	Title     string
+	Body      string // Description, or notes of a history entry
+
+	// History entries only
+	Amount     int
+	SourceType SourceType
+	SourceID   *int64
+	CreatedAt  time.Time
+}
+
+// Search finds tasks, shop items and history entries matching every word of
+// the query, in one group or, with groupID 0, all of the user's groups.
+// Members find their own history entries, owners everyone's in their groups.
+func (s *Service) Search(userID, groupID int64, query string) ([]*SearchResult, error) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("enter something to search for")
+	}
+	if len(terms) > searchMaxTerms {
+		terms = terms[:searchMaxTerms]
+	}
+
+	var groupIDs []int64
+	if groupID != 0 {
+		isMember, err := s.store.IsUserInGroup(userID, groupID)
+		if err != nil {
+			return nil, err
+		}
+		if !isMember {
+			return nil, fmt.Errorf("user is not a member of this group")
+		}
+		groupIDs = []int64{groupID}
+	} else {
+		groups, err := s.store.GetGroupsByUserID(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get groups: %w", err)
+		}
+		for _, group := range groups {
+			groupIDs = append(groupIDs, group.ID)
+		}
+	}
+
+	return s.store.Search(userID, groupIDs, terms, searchLimit)
+}
@@ -18,6 +18,7 @@ type Store interface {
 	GetUserByUsername(username string) (*User, error)
 	GetUsersByGroupID(groupID int64) ([]*User, error)
 	UpdateUserLanguage(userID int64, language string) error
+	UpdateUserTimezone(userID int64, timezone string) error
 
 	// Group operations
 	CreateGroup(name, inviteCode string, ownerID int64) (*Group, error)
@@ -26,12 +27,20 @@ type Store interface {
 	GetGroupsByUserID(userID int64) ([]*Group, error)
 	AddUserToGroup(userID, groupID int64) error
 	IsUserInGroup(userID, groupID int64) (bool, error)
+	GetArchivedGroupsByUserID(userID int64) ([]*Group, error)
+	GetGroupsPendingDeletion(now time.Time) ([]*Group, error)
+	SetGroupArchived(groupID int64, archived bool) error
+	ScheduleGroupDeletion(groupID int64, deleteAfter time.Time, retainLedger bool) error
+	CancelGroupDeletion(groupID int64) error
+	DeleteGroupData(groupID int64) error
+	GetLedgerExportsByOwner(ownerID int64) ([]*GroupLedgerExport, error)
+	GetLedgerExportByID(id int64) (*GroupLedgerExport, error)
 
 	// Task operations
-	CreateTask(groupID int64, title, description string, taskType TaskType, rewardValue int, defaultQuantity int, isOneTime bool) (*Task, error)
+	CreateTask(groupID int64, title, description string, taskType TaskType, rewardValue int, defaultQuantity int, isOneTime bool, dueAt *time.Time) (*Task, error)
 	GetTaskByID(id int64) (*Task, error)
 	GetTasksByGroupID(groupID int64) ([]*Task, error)
-	UpdateTask(id int64, title, description string, taskType TaskType, rewardValue int, defaultQuantity int, isOneTime bool) error
+	UpdateTask(id int64, title, description string, taskType TaskType, rewardValue int, defaultQuantity int, isOneTime bool, dueAt *time.Time) error
 	DeleteTask(id int64) error
 	UndoTaskDeletion(id int64) (*Task, error)
 
@@ -47,13 +56,75 @@ type Store interface {
 	CreateTransaction(userID, groupID int64, amount int, sourceType SourceType, sourceID *int64, quantity int, description, notes string) (*Transaction, error)
 	GetTransactionByID(id int64) (*Transaction, error)
 	GetTransactionsByUserAndGroup(userID, groupID int64) ([]*Transaction, error)
+	GetTransactionsByUserSince(userID int64, since time.Time) ([]*Transaction, error)
+	CreateCurrencyTransaction(userID, groupID, currencyID int64, amount int, sourceType SourceType, sourceID, parentID *int64, quantity int, description, notes string) (*Transaction, error)
+	GetChildTransactions(parentID int64) ([]*Transaction, error)
 	GetBalance(userID, groupID int64) (int, error)
-	GetTaskCompletionHistory(userID, groupID int64) ([]*TaskCompletionHistory, error)
+	GetCurrencyBalance(userID, groupID, currencyID int64) (int, error)
+	GetBalances(userID, groupID int64) (map[int64]int, error)
+	GetTaskCompletionHistory(groupID int64, filter LogFilter) ([]*TaskCompletionHistory, error)
+
+	// Currency operations
+	CreateCurrency(groupID int64, name, emoji string, labels map[string]string) (*Currency, error)
+	GetCurrencyByID(id int64) (*Currency, error)
+	GetCurrenciesByGroupID(groupID int64) ([]*Currency, error)
+	UpdateCurrency(id int64, name, emoji string, labels map[string]string) error
+	GetTaskRewards(taskID int64) ([]CurrencyAmount, error)
+	SetTaskRewards(taskID int64, rewards []CurrencyAmount) error
+	GetShopItemPrices(itemID int64) ([]CurrencyAmount, error)
+	SetShopItemPrices(itemID int64, prices []CurrencyAmount) error
+	CreateExchangeRule(groupID, fromCurrencyID int64, fromAmount int, toCurrencyID int64, toAmount int) (*ExchangeRule, error)
+	GetExchangeRuleByID(id int64) (*ExchangeRule, error)
+	GetExchangeRulesByGroupID(groupID int64) ([]*ExchangeRule, error)
+	DeleteExchangeRule(id int64) error
+	ApplyDefinitionImport(plan *DefinitionImport) error
+
+	// Wishlist operations
+	AddWishlistItem(userID, groupID, shopItemID int64) (*WishlistItem, error)
+	GetWishlistItemByID(id int64) (*WishlistItem, error)
+	GetWishlistByUser(userID int64) ([]*WishlistItem, error)
+	GetWishlistByUserAndGroup(userID, groupID int64) ([]*WishlistItem, error)
+	GetAllWishlistItems() ([]*WishlistItem, error)
+	DeleteWishlistItem(id int64) error
+	SetWishlistReserved(id int64, reserved int) error
+	SetWishlistNotified(id int64, notifiedAt *time.Time) error
+	GetReservedTotal(userID, groupID int64) (int, error)
+
+	// Deadline penalty operations
+	SetTaskDeadlinePolicy(taskID int64, policy DeadlinePolicy) error
+	GetTasksWithUnevaluatedDeadline() ([]*Task, error)
+	SetTaskDeadlineEvaluated(taskID int64) error
+	ApplyTaskPenalty(task *Task, userID int64, amount int, description string) (bool, error)
+	GetTaskTransactionsByUser(taskID, userID int64) ([]*Transaction, error)
+
+	// Scheduled grant operations
+	CreateScheduledGrant(grant *ScheduledGrant) (*ScheduledGrant, error)
+	GetScheduledGrantByID(id int64) (*ScheduledGrant, error)
+	GetScheduledGrantsByGroupID(groupID int64) ([]*ScheduledGrant, error)
+	GetActiveScheduledGrants() ([]*ScheduledGrant, error)
+	SetScheduledGrantPaused(id int64, paused bool, lastRunAt time.Time) error
+	SetScheduledGrantLastRun(id int64, runAt time.Time) error
+	DeleteScheduledGrant(id int64) error
+	PayScheduledGrant(grant *ScheduledGrant, userID int64, runAt time.Time, amount int, sourceType SourceType) (bool, error)
+
+	// Pause operations
+	CreatePause(pause *Pause) (*Pause, error)
+	GetPauseByID(id int64) (*Pause, error)
+	GetPausesByUserID(userID int64) ([]*Pause, error)
+	GetPausesByGroupID(groupID int64) ([]*Pause, error)
+	GetUnresumedPauses() ([]*Pause, error)
+	SetPauseEndsAt(id int64, endsAt time.Time) error
+	MarkPauseResumed(id int64, resumedAt time.Time) error
+	DeletePause(id int64) error
+	SetTaskDueAt(taskID int64, dueAt *time.Time) error
+	SetTaskAvailableFrom(taskID int64, availableFrom *time.Time) error
+	SetTaskSnooze(taskID, userID int64, until *time.Time) error
+	GetTaskSnoozes(userID int64, now time.Time) (map[int64]time.Time, error)
 
 	// Purchase operations
 	CreatePurchase(transactionID, userID, groupID, shopItemID int64) (*Purchase, error)
 	GetPurchasesByUserAndGroup(userID, groupID int64) ([]*Purchase, error)
-	GetPurchaseHistoryByUserAndGroup(userID, groupID int64) ([]*PurchaseHistory, error)
+	GetPurchaseHistory(groupID int64, filter LogFilter) ([]*PurchaseHistory, error)
 	MarkPurchaseFulfilled(purchaseID, fulfilledByUserID int64, notes string) error
 	CancelPurchaseByTransactionID(transactionID int64) error
 
@@ -68,20 +139,93 @@ type Store interface {
 	UpdateNotificationSettings(settings *NotificationSettings) error
 	CreateNotification(notification *TaskNotification) error
 	GetPendingNotifications(now time.Time) ([]*TaskNotification, error)
+	GetNextNotificationTime() (time.Time, bool, error)
 	MarkNotificationSent(notificationID int64) error
+	DeferNotification(notificationID int64, until time.Time) error
+	ScheduleNotificationRetry(notificationID int64, lastError string, nextAttemptAt time.Time) error
+	MarkNotificationFailed(notificationID int64, lastError string, failedAt time.Time) error
+	GetFailedNotifications(limit int) ([]*TaskNotification, error)
+	ResetNotificationDelivery(notificationID int64, at time.Time) error
+	DeleteNotification(notificationID int64) error
 	DeleteNotificationsByTask(taskID int64) error
+	DeleteNotificationsByTaskAndUser(taskID, userID int64) error
+	DeleteNagNotifications(taskID int64) error
+	DeleteDeadlineReminders(taskID, userID int64) error
+	SetTaskReminderOptOut(taskID, userID int64, optOut bool) error
+	GetTaskReminderOptOuts(taskID int64) (map[int64]bool, error)
+	GetReminderOptOuts(userID int64) (map[int64]bool, error)
+	DeleteNagNotificationsForUser(taskID, userID int64) (bool, error)
+	GetNaggedTaskIDs(userID int64) (map[int64]bool, error)
 	GetNotificationByID(id int64) (*TaskNotification, error)
+
+	// Digest operations
+	GetDigestSettings() ([]*NotificationSettings, error)
+	ClaimDigest(userID int64, kind, day string) (bool, error)
+	ReleaseDigest(userID int64, kind, day string) error
+
+	// Report operations
+	GetTaskReport(groupID, userID int64, since, until time.Time) ([]*ReportTask, error)
+	GetMemberReport(groupID, userID int64, since, until time.Time) (map[int64]*ReportMember, error)
+	GetDailyReport(groupID, userID int64, since, until time.Time) ([]*ReportDay, error)
+	CountPurchases(groupID, userID int64, since, until time.Time) (int, error)
+	RecordMissedDeadline(task *Task, userID int64) error
+	GetMissedDeadlineCounts(groupID int64, since, until time.Time) (map[int64]int, error)
+
+	// Stats operations
+	GetStatsBuckets(groupID int64, interval string, since, until time.Time) ([]*StatsBucket, error)
+	GetQuantityStats(groupID int64, interval string, since, until time.Time) ([]*StatsQuantity, error)
+
+	// Season operations
+	CreateSeason(season *Season) (*Season, error)
+	GetSeasonByID(id int64) (*Season, error)
+	GetSeasonsByGroupID(groupID int64) ([]*Season, error)
+	GetSeasonsToClose(now time.Time) ([]*Season, error)
+	SetSeasonEnd(id int64, endsAt time.Time) error
+	DeleteSeason(id int64) error
+	CloseSeason(seasonID int64, standings []*SeasonStanding, closedAt time.Time) error
+	GetSeasonStandings(seasonID int64) ([]*SeasonStanding, error)
+	GetSeasonBadges(groupID int64) ([]*SeasonStanding, error)
+
+	// Economy operations
+	GetEconomyTargets(groupID int64) (*EconomyTargets, error)
+	SaveEconomyTargets(targets *EconomyTargets) error
+	GetEconomyFlows(groupID int64, since, until time.Time) (map[int64]*EconomyMember, error)
+	GetPurchaseCounts(groupID int64, since, until time.Time) (map[int64]int, error)
+
+	// Search operations
+	Search(userID int64, groupIDs []int64, terms []string, limit int) ([]*SearchResult, error)
+
+	// Export operations
+	StreamTransactions(groupID, userID int64, fn func(*Transaction) error) error
+	StreamPurchases(groupID, userID int64, fn func(*PurchaseHistory) error) error
+	StreamTasks(groupID int64, fn func(*Task) error) error
+
+	// Delivery channel operations
+	GetChannelPreferences(userID int64) ([]*ChannelPreference, error)
+	SaveChannelPreference(pref *ChannelPreference) error
+	GetPushSubscriptions(userID int64) ([]*PushSubscription, error)
+	SavePushSubscription(sub *PushSubscription) error
+	DeletePushSubscription(endpoint string) error
 }
 
 // Service provides business logic for the application
 type Service struct {
 	store Store
+	// notificationWake tells the notification worker that the queue changed
+	notificationWake chan struct{}
+	// channels deliver notifications, keyed by channel name
+	channels map[string]NotificationChannel
+	// telegramLimiter keeps all Telegram messages within the bot rate limits
+	telegramLimiter *sendLimiter
 }
 
 // NewService creates a new Service instance
 func NewService(store Store) *Service {
 	return &Service{
-		store: store,
+		store:            store,
+		notificationWake: make(chan struct{}, 1),
+		channels:         make(map[string]NotificationChannel),
+		telegramLimiter:  newSendLimiter(telegramGlobalInterval, telegramChatInterval),
 	}
 }
 
@@ -148,12 +292,131 @@ func (s *Service) GetGroupsByUserID(userID int64) ([]*Group, error) {
 	return s.store.GetGroupsByUserID(userID)
 }
 
+// GetArchivedGroupsByUserID retrieves archived and deletion-pending groups for a user
+func (s *Service) GetArchivedGroupsByUserID(userID int64) ([]*Group, error) {
+	return s.store.GetArchivedGroupsByUserID(userID)
+}
+
+// GroupDeletionGracePeriod is how long a group stays recoverable after the owner asks to delete it
+const GroupDeletionGracePeriod = 7 * 24 * time.Hour
+
+// requireGroupOwner loads a group and verifies that userID owns it
+func (s *Service) requireGroupOwner(userID, groupID int64) (*Group, error) {
+	group, err := s.store.GetGroupByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.OwnerID != userID {
+		return nil, fmt.Errorf("only the group owner can do this")
+	}
+	return group, nil
+}
+
+// ensureGroupWritable returns an error if the group is archived and therefore read-only
+func (s *Service) ensureGroupWritable(groupID int64) error {
+	group, err := s.store.GetGroupByID(groupID)
+	if err != nil {
+		return err
+	}
+	if group.IsArchived() {
+		return fmt.Errorf("group is archived")
+	}
+	return nil
+}
+
+// ArchiveGroup makes a group read-only and hides it from dashboards and the bot
+func (s *Service) ArchiveGroup(userID, groupID int64) error {
+	if _, err := s.requireGroupOwner(userID, groupID); err != nil {
+		return err
+	}
+	return s.store.SetGroupArchived(groupID, true)
+}
+
+// UnarchiveGroup restores an archived group unless it is scheduled for deletion
+func (s *Service) UnarchiveGroup(userID, groupID int64) error {
+	group, err := s.requireGroupOwner(userID, groupID)
+	if err != nil {
+		return err
+	}
+	if group.IsPendingDeletion() {
+		return fmt.Errorf("cancel the scheduled deletion first")
+	}
+	return s.store.SetGroupArchived(groupID, false)
+}
+
+// RequestGroupDeletion schedules a group for deletion after the grace period.
+// The owner must confirm by repeating the group name. The group is archived
+// immediately so nobody can change it while the deletion is pending.
+func (s *Service) RequestGroupDeletion(userID, groupID int64, confirmName string, retainLedger bool) (*Group, error) {
+	group, err := s.requireGroupOwner(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if confirmName != group.Name {
+		return nil, fmt.Errorf("group name does not match")
+	}
+
+	deleteAfter := time.Now().Add(GroupDeletionGracePeriod)
+	if err := s.store.ScheduleGroupDeletion(groupID, deleteAfter, retainLedger); err != nil {
+		return nil, err
+	}
+
+	return s.store.GetGroupByID(groupID)
+}
+
+// CancelGroupDeletion cancels a pending deletion; the group stays archived
+func (s *Service) CancelGroupDeletion(userID, groupID int64) error {
+	if _, err := s.requireGroupOwner(userID, groupID); err != nil {
+		return err
+	}
+	return s.store.CancelGroupDeletion(groupID)
+}
+
+// PurgeDeletedGroups permanently removes groups whose grace period has passed
+func (s *Service) PurgeDeletedGroups(now time.Time) (int, error) {
+	groups, err := s.store.GetGroupsPendingDeletion(now)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, group := range groups {
+		if err := s.store.DeleteGroupData(group.ID); err != nil {
+			log.Printf("Failed to purge group %d: %v", group.ID, err)
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// GetLedgerExportsByOwner retrieves retained ledgers of deleted groups
+func (s *Service) GetLedgerExportsByOwner(ownerID int64) ([]*GroupLedgerExport, error) {
+	return s.store.GetLedgerExportsByOwner(ownerID)
+}
+
+// GetLedgerExport retrieves a retained ledger, only for the owner of the deleted group
+func (s *Service) GetLedgerExport(userID, exportID int64) (*GroupLedgerExport, error) {
+	export, err := s.store.GetLedgerExportByID(exportID)
+	if err != nil {
+		return nil, err
+	}
+	if export.OwnerID != userID {
+		return nil, fmt.Errorf("ledger export not found")
+	}
+	return export, nil
+}
+
 // JoinGroup adds a user to a group using an invite code
 func (s *Service) JoinGroup(userID int64, inviteCode string) (*Group, error) {
 	group, err := s.store.GetGroupByInviteCode(inviteCode)
 	if err != nil {
 		return nil, err
 	}
+	if group.IsArchived() {
+		return nil, fmt.Errorf("group is archived")
+	}
 
 	// Check if user is already in the group
 	isMember, err := s.store.IsUserInGroup(userID, group.ID)
@@ -171,8 +434,11 @@ func (s *Service) JoinGroup(userID int64, inviteCode string) (*Group, error) {
 	return group, nil
 }
 
-// CreateTask creates a new task in a group
-func (s *Service) CreateTask(groupID int64, title, description string, taskType TaskType, rewardValue int, defaultQuantity int, isOneTime bool) (*Task, error) {
+// CreateTask creates a new task in a group.
+// extraRewards are paid in group-defined currencies on top of rewardValue cheese.
+// availableFrom optionally hides the task until that time, and dueAt sets an
+// optional deadline whose reminders are scheduled for every member.
+func (s *Service) CreateTask(groupID int64, title, description string, taskType TaskType, rewardValue int, defaultQuantity int, isOneTime bool, extraRewards []CurrencyAmount, policy DeadlinePolicy, availableFrom, dueAt *time.Time) (*Task, error) {
 	if title == "" {
 		return nil, fmt.Errorf("task title cannot be empty")
 	}
@@ -185,13 +451,62 @@ func (s *Service) CreateTask(groupID int64, title, description string, taskType
 	if defaultQuantity <= 0 {
 		defaultQuantity = 10 // Default to 10 if not provided or invalid
 	}
+	if err := s.ensureGroupWritable(groupID); err != nil {
+		return nil, err
+	}
+	extraRewards, err := s.normalizeCurrencyAmounts(groupID, extraRewards)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDeadlinePolicy(policy); err != nil {
+		return nil, err
+	}
+	if err := s.validateNagPartner(groupID, policy); err != nil {
+		return nil, err
+	}
+	if dueAt != nil && !dueAt.After(time.Now()) {
+		return nil, fmt.Errorf("due date must be in the future")
+	}
+	if err := validateAvailableFrom(availableFrom, dueAt); err != nil {
+		return nil, err
+	}
+
+	task, err := s.store.CreateTask(groupID, title, description, taskType, rewardValue, defaultQuantity, isOneTime, dueAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.SetTaskRewards(task.ID, extraRewards); err != nil {
+		return nil, err
+	}
+	task.ExtraRewards = extraRewards
+	if err := s.store.SetTaskDeadlinePolicy(task.ID, policy); err != nil {
+		return nil, err
+	}
+	task.Deadline = policy
+	if availableFrom != nil {
+		if err := s.store.SetTaskAvailableFrom(task.ID, availableFrom); err != nil {
+			return nil, err
+		}
+		task.AvailableFrom = availableFrom
+		s.wakeNotificationWorker()
+	}
+	if err := s.ScheduleNotificationsForTask(task); err != nil {
+		return nil, err
+	}
 
-	return s.store.CreateTask(groupID, title, description, taskType, rewardValue, defaultQuantity, isOneTime)
+	return task, nil
 }
 
 // GetTasksByGroupID retrieves all tasks for a group
 func (s *Service) GetTasksByGroupID(groupID int64) ([]*Task, error) {
-	return s.store.GetTasksByGroupID(groupID)
+	tasks, err := s.store.GetTasksByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadTaskRewards(groupID, tasks...); err != nil {
+		return nil, err
+	}
+	return tasks, nil
 }
 
 // CompleteTask handles task completion logic
@@ -211,6 +526,12 @@ func (s *Service) CompleteTask(userID, taskID int64, quantity *int) (*Transactio
 	if !isMember {
 		return nil, fmt.Errorf("user is not a member of this group")
 	}
+	if err := s.ensureGroupWritable(task.GroupID); err != nil {
+		return nil, err
+	}
+	if !task.IsAvailable(time.Now()) {
+		return nil, fmt.Errorf("task is hidden until %s", s.userTime(userID, *task.AvailableFrom))
+	}
 
 	// Calculate reward based on task type
 	var reward int
@@ -231,6 +552,18 @@ func (s *Service) CompleteTask(userID, taskID int64, quantity *int) (*Transactio
 		return nil, fmt.Errorf("unknown task type: %s", task.TaskType)
 	}
 
+	extraRewards, err := s.store.GetTaskRewards(task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task rewards: %w", err)
+	}
+
+	// Late completions earn the reduced share configured on the task
+	latePercent := 100
+	if task.IsLate(time.Now()) && task.Deadline.LateRewardPercent < 100 && s.pausedAt(userID, task.GroupID, task.MissedAt()) == nil {
+		latePercent = task.Deadline.LateRewardPercent
+		reward = reward * latePercent / 100
+	}
+
 	// Create transaction with task details stored
 	transaction, err := s.store.CreateTransaction(
 		userID,
@@ -246,20 +579,45 @@ func (s *Service) CompleteTask(userID, taskID int64, quantity *int) (*Transactio
 		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
 
+	// Pay extra currencies as legs linked to the main transaction
+	for _, extra := range extraRewards {
+		amount := extra.Amount * finalQuantity * latePercent / 100
+		if amount <= 0 {
+			continue
+		}
+		if _, err := s.store.CreateCurrencyTransaction(
+			userID, task.GroupID, extra.CurrencyID, amount,
+			SourceTypeTask, &task.ID, &transaction.ID, finalQuantity, task.Title, task.Description,
+		); err != nil {
+			return nil, fmt.Errorf("failed to create transaction: %w", err)
+		}
+	}
+
 	// If task is one-time, delete it after completion
 	if task.IsOneTime {
+		if err := s.CancelNotificationsForTask(task.ID); err != nil {
+			log.Printf("Failed to cancel notifications for task %d: %v", task.ID, err)
+		}
 		if err := s.store.DeleteTask(task.ID); err != nil {
 			// Log error but don't fail the transaction
 			// The transaction was successful, deletion is secondary
 			_ = err
 		}
+	} else if task.DueAt != nil {
+		// Members who already finished don't need reminders for this deadline
+		if err := s.store.DeleteNotificationsByTaskAndUser(task.ID, userID); err != nil {
+			log.Printf("Failed to cancel notifications for task %d: %v", task.ID, err)
+		}
+		s.wakeNotificationWorker()
 	}
 
 	return transaction, nil
 }
 
-// UpdateTask updates an existing task
-func (s *Service) UpdateTask(id int64, title, description string, taskType TaskType, rewardValue int, defaultQuantity int, isOneTime bool) error {
+// UpdateTask updates an existing task and replaces its extra-currency rewards.
+// availableFrom replaces the task's start date; nil makes it visible right away.
+// dueAt replaces the deadline (nil clears it) and reschedules reminders when it moves.
+func (s *Service) UpdateTask(id int64, title, description string, taskType TaskType, rewardValue int, defaultQuantity int, isOneTime bool, extraRewards []CurrencyAmount, policy DeadlinePolicy, availableFrom, dueAt *time.Time) error {
 	if title == "" {
 		return fmt.Errorf("task title cannot be empty")
 	}
@@ -272,12 +630,78 @@ func (s *Service) UpdateTask(id int64, title, description string, taskType TaskT
 	if defaultQuantity <= 0 {
 		defaultQuantity = 10 // Default to 10 if not provided or invalid
 	}
+	task, err := s.store.GetTaskByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureGroupWritable(task.GroupID); err != nil {
+		return err
+	}
+	extraRewards, err = s.normalizeCurrencyAmounts(task.GroupID, extraRewards)
+	if err != nil {
+		return err
+	}
+	if err := validateDeadlinePolicy(policy); err != nil {
+		return err
+	}
+	if err := s.validateNagPartner(task.GroupID, policy); err != nil {
+		return err
+	}
+	dueChanged := !sameMinute(task.DueAt, dueAt)
+	if !dueChanged {
+		// Keep the stored deadline so its penalty evaluation is not reset
+		dueAt = task.DueAt
+	} else if dueAt != nil && !dueAt.After(time.Now()) {
+		// An unchanged deadline may already have passed; a new one must be ahead
+		return fmt.Errorf("due date must be in the future")
+	}
+	if err := validateAvailableFrom(availableFrom, dueAt); err != nil {
+		return err
+	}
 
-	return s.store.UpdateTask(id, title, description, taskType, rewardValue, defaultQuantity, isOneTime)
+	if err := s.store.UpdateTask(id, title, description, taskType, rewardValue, defaultQuantity, isOneTime, dueAt); err != nil {
+		return err
+	}
+	// The policy is saved first, as rescheduling reads it
+	if err := s.store.SetTaskDeadlinePolicy(id, policy); err != nil {
+		return err
+	}
+	if dueChanged {
+		if err := s.RescheduleNotificationsForTask(id, dueAt); err != nil {
+			return err
+		}
+	} else {
+		previous := task.Deadline
+		task.Deadline = policy
+		if !sameReminderOffsets(previous.ReminderOffsets, policy.ReminderOffsets) {
+			if err := s.rescheduleDeadlineReminders(task, time.Now()); err != nil {
+				return err
+			}
+		}
+		if nagPolicyChanged(previous, policy) {
+			if err := s.rescheduleNags(task, time.Now()); err != nil {
+				return err
+			}
+		}
+	}
+	if err := s.store.SetTaskAvailableFrom(id, availableFrom); err != nil {
+		return err
+	}
+	// Reminders held back by the old start date may be due now
+	s.wakeNotificationWorker()
+	return s.store.SetTaskRewards(id, extraRewards)
 }
 
 // DeleteTask deletes a task
 func (s *Service) DeleteTask(id int64) error {
+	task, err := s.store.GetTaskByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureGroupWritable(task.GroupID); err != nil {
+		return err
+	}
+
 	// Cancel any pending notifications before deleting the task
 	if err := s.CancelNotificationsForTask(id); err != nil {
 		log.Printf("Warning: failed to cancel notifications for task %d: %v", id, err)
@@ -288,40 +712,103 @@ func (s *Service) DeleteTask(id int64) error {
 
 // UndoTaskDeletion restores a deleted task from cache
 func (s *Service) UndoTaskDeletion(id int64) (*Task, error) {
-	return s.store.UndoTaskDeletion(id)
+	task, err := s.store.UndoTaskDeletion(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ScheduleNotificationsForTask(task); err != nil {
+		return nil, err
+	}
+	return task, nil
 }
 
-// CreateShopItem creates a new shop item in a group
-func (s *Service) CreateShopItem(groupID int64, title, description string, cost int, isOneTime bool) (*ShopItem, error) {
+// CreateShopItem creates a new shop item in a group.
+// An item may be priced in cheese, in group-defined currencies, or both.
+func (s *Service) CreateShopItem(groupID int64, title, description string, cost int, isOneTime bool, extraPrices []CurrencyAmount) (*ShopItem, error) {
 	if title == "" {
 		return nil, fmt.Errorf("shop item title cannot be empty")
 	}
-	if cost <= 0 {
-		return nil, fmt.Errorf("cost must be positive")
+	if err := s.ensureGroupWritable(groupID); err != nil {
+		return nil, err
+	}
+	extraPrices, err := s.normalizeCurrencyAmounts(groupID, extraPrices)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateShopPrice(cost, extraPrices); err != nil {
+		return nil, err
 	}
 
-	return s.store.CreateShopItem(groupID, title, description, cost, isOneTime)
+	item, err := s.store.CreateShopItem(groupID, title, description, cost, isOneTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.SetShopItemPrices(item.ID, extraPrices); err != nil {
+		return nil, err
+	}
+	item.ExtraPrices = extraPrices
+
+	return item, nil
+}
+
+// validateShopPrice ensures an item costs something in at least one currency
+func validateShopPrice(cost int, extraPrices []CurrencyAmount) error {
+	if cost < 0 {
+		return fmt.Errorf("cost cannot be negative")
+	}
+	if cost == 0 && len(extraPrices) == 0 {
+		return fmt.Errorf("cost must be positive")
+	}
+	return nil
 }
 
 // GetShopItemsByGroupID retrieves all shop items for a group
 func (s *Service) GetShopItemsByGroupID(groupID int64) ([]*ShopItem, error) {
-	return s.store.GetShopItemsByGroupID(groupID)
+	items, err := s.store.GetShopItemsByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadShopItemPrices(groupID, items...); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-// UpdateShopItem updates an existing shop item
-func (s *Service) UpdateShopItem(id int64, title, description string, cost int, isOneTime bool) error {
+// UpdateShopItem updates an existing shop item and replaces its extra-currency prices
+func (s *Service) UpdateShopItem(id int64, title, description string, cost int, isOneTime bool, extraPrices []CurrencyAmount) error {
 	if title == "" {
 		return fmt.Errorf("shop item title cannot be empty")
 	}
-	if cost <= 0 {
-		return fmt.Errorf("cost must be positive")
+	item, err := s.store.GetShopItemByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureGroupWritable(item.GroupID); err != nil {
+		return err
+	}
+	extraPrices, err = s.normalizeCurrencyAmounts(item.GroupID, extraPrices)
+	if err != nil {
+		return err
+	}
+	if err := validateShopPrice(cost, extraPrices); err != nil {
+		return err
 	}
 
-	return s.store.UpdateShopItem(id, title, description, cost, isOneTime)
+	if err := s.store.UpdateShopItem(id, title, description, cost, isOneTime); err != nil {
+		return err
+	}
+	return s.store.SetShopItemPrices(id, extraPrices)
 }
 
 // DeleteShopItem deletes a shop item
 func (s *Service) DeleteShopItem(id int64) error {
+	item, err := s.store.GetShopItemByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureGroupWritable(item.GroupID); err != nil {
+		return err
+	}
 	return s.store.DeleteShopItem(id)
 }
 
@@ -345,17 +832,33 @@ func (s *Service) BuyItem(userID, itemID int64) (*Transaction, error) {
 	if !isMember {
 		return nil, fmt.Errorf("user is not a member of this group")
 	}
+	if err := s.ensureGroupWritable(item.GroupID); err != nil {
+		return nil, err
+	}
 
-	// Check if user has enough balance
-	balance, err := s.store.GetBalance(userID, item.GroupID)
+	// Check if user has enough balance; cheese reserved for other wishlist goals is off limits
+	balance, err := s.availableBalance(userID, item.GroupID, item.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get balance: %w", err)
+		return nil, err
 	}
 
 	if balance < item.Cost {
 		return nil, fmt.Errorf("insufficient balance: have %d, need %d", balance, item.Cost)
 	}
 
+	if err := s.loadShopItemPrices(item.GroupID, item); err != nil {
+		return nil, fmt.Errorf("failed to get item prices: %w", err)
+	}
+	for _, price := range item.ExtraPrices {
+		have, err := s.store.GetCurrencyBalance(userID, item.GroupID, price.CurrencyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get balance: %w", err)
+		}
+		if have < price.Amount {
+			return nil, fmt.Errorf("insufficient %s: have %d, need %d", price.Currency.Name, have, price.Amount)
+		}
+	}
+
 	// Create negative transaction for the purchase with item details stored
 	transaction, err := s.store.CreateTransaction(
 		userID,
@@ -371,6 +874,15 @@ func (s *Service) BuyItem(userID, itemID int64) (*Transaction, error) {
 		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
 
+	for _, price := range item.ExtraPrices {
+		if _, err := s.store.CreateCurrencyTransaction(
+			userID, item.GroupID, price.CurrencyID, -price.Amount,
+			SourceTypeShopItem, &item.ID, &transaction.ID, 1, item.Title, item.Description,
+		); err != nil {
+			return nil, fmt.Errorf("failed to create transaction: %w", err)
+		}
+	}
+
 	// Create purchase record for tracking
 	purchase, err := s.store.CreatePurchase(transaction.ID, userID, item.GroupID, item.ID)
 	if err != nil {
@@ -378,6 +890,17 @@ func (s *Service) BuyItem(userID, itemID int64) (*Transaction, error) {
 	}
 	_ = purchase // Purchase record created successfully
 
+	// The savings goal is reached, drop it from the buyer's wishlist
+	if wishlist, err := s.store.GetWishlistByUserAndGroup(userID, item.GroupID); err == nil {
+		for _, entry := range wishlist {
+			if entry.ShopItemID == item.ID {
+				if err := s.store.DeleteWishlistItem(entry.ID); err != nil {
+					log.Printf("Failed to remove wishlist item %d after purchase: %v", entry.ID, err)
+				}
+			}
+		}
+	}
+
 	// If item is one-time, delete it after purchase
 	if item.IsOneTime {
 		if err := s.store.DeleteShopItem(item.ID); err != nil {
@@ -390,16 +913,6 @@ func (s *Service) BuyItem(userID, itemID int64) (*Transaction, error) {
 	return transaction, nil
 }
 
-// GetTaskCompletionHistory retrieves task completion history
-func (s *Service) GetTaskCompletionHistory(userID, groupID int64) ([]*TaskCompletionHistory, error) {
-	return s.store.GetTaskCompletionHistory(userID, groupID)
-}
-
-// GetPurchaseHistory retrieves purchase history
-func (s *Service) GetPurchaseHistory(userID, groupID int64) ([]*PurchaseHistory, error) {
-	return s.store.GetPurchaseHistoryByUserAndGroup(userID, groupID)
-}
-
 // MarkPurchaseFulfilled marks a purchase as fulfilled
 func (s *Service) MarkPurchaseFulfilled(purchaseID, fulfilledByUserID int64, notes string) error {
 	return s.store.MarkPurchaseFulfilled(purchaseID, fulfilledByUserID, notes)
@@ -437,12 +950,26 @@ func (s *Service) GetUsersByGroupID(groupID int64) ([]*User, error) {
 
 // GetTaskByID retrieves a task by ID
 func (s *Service) GetTaskByID(id int64) (*Task, error) {
-	return s.store.GetTaskByID(id)
+	task, err := s.store.GetTaskByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadTaskRewards(task.GroupID, task); err != nil {
+		return nil, err
+	}
+	return task, nil
 }
 
 // GetShopItemByID retrieves a shop item by ID
 func (s *Service) GetShopItemByID(id int64) (*ShopItem, error) {
-	return s.store.GetShopItemByID(id)
+	item, err := s.store.GetShopItemByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadShopItemPrices(item.GroupID, item); err != nil {
+		return nil, err
+	}
+	return item, nil
 }
 
 // UndoTransaction creates a reversal transaction to undo a completed task or purchase
@@ -458,6 +985,20 @@ func (s *Service) UndoTransaction(userID, transactionID int64) error {
 		return fmt.Errorf("transaction does not belong to this user")
 	}
 
+	// Penalties are applied by the deadline evaluator, members cannot undo them
+	if transaction.SourceType == SourceTypePenalty {
+		return fmt.Errorf("penalties cannot be undone")
+	}
+
+	// Extra-currency legs are undone together with their main transaction
+	if transaction.ParentID != nil {
+		return fmt.Errorf("undo the main transaction instead")
+	}
+	legs, err := s.store.GetChildTransactions(transaction.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get linked transactions: %w", err)
+	}
+
 	// Verify user is still in the group
 	isMember, err := s.store.IsUserInGroup(userID, transaction.GroupID)
 	if err != nil {
@@ -466,16 +1007,21 @@ func (s *Service) UndoTransaction(userID, transactionID int64) error {
 	if !isMember {
 		return fmt.Errorf("user is not a member of this group")
 	}
+	if err := s.ensureGroupWritable(transaction.GroupID); err != nil {
+		return err
+	}
 
 	// Create reversal transaction (negative of original amount)
 	// Keep the same description and notes for consistency
 	reversalAmount := -transaction.Amount
-	_, err = s.store.CreateTransaction(
+	reversal, err := s.store.CreateCurrencyTransaction(
 		transaction.UserID,
 		transaction.GroupID,
+		transaction.CurrencyID,
 		reversalAmount,
 		transaction.SourceType,
 		transaction.SourceID,
+		nil,
 		transaction.Quantity,
 		transaction.Description, // Keep original description
 		transaction.Notes,       // Keep original notes
@@ -484,8 +1030,17 @@ func (s *Service) UndoTransaction(userID, transactionID int64) error {
 		return fmt.Errorf("failed to create reversal transaction: %w", err)
 	}
 
+	for _, leg := range legs {
+		if _, err := s.store.CreateCurrencyTransaction(
+			leg.UserID, leg.GroupID, leg.CurrencyID, -leg.Amount,
+			leg.SourceType, leg.SourceID, &reversal.ID, leg.Quantity, leg.Description, leg.Notes,
+		); err != nil {
+			return fmt.Errorf("failed to create reversal transaction: %w", err)
+		}
+	}
+
 	// If this was a purchase transaction, mark the purchase as cancelled
-	if transaction.SourceType == SourceTypeShopItem && transaction.Amount < 0 {
+	if transaction.SourceType == SourceTypeShopItem && transaction.Amount <= 0 {
 		// Find the purchase record for this transaction
 		if err := s.store.CancelPurchaseByTransactionID(transactionID); err != nil {
 			// Log error but don't fail - the reversal transaction was successful
@@ -498,9 +1053,11 @@ func (s *Service) UndoTransaction(userID, transactionID int64) error {
 }
 
 // ScheduleNotificationsForTask creates notification records when a task has a due date
-// Creates two notifications:
+// Creates for every member who did not opt out of the task's reminders:
 // - One "on_deadline" notification scheduled at due_at
-// - One "before_deadline" notification scheduled at due_at minus user's reminder_delta_minutes
+// - One "before_deadline" notification at each of the task's reminder offsets before due_at,
+// or at the user's reminder_delta_minutes when the task has none
+// - With nag mode on, the first "nag" once the deadline is missed; each nag schedules the next
 func (s *Service) ScheduleNotificationsForTask(task *Task) error {
 	// Skip if task has no due date
 	if task.DueAt == nil {
@@ -508,7 +1065,8 @@ func (s *Service) ScheduleNotificationsForTask(task *Task) error {
 	}
 
 	// Skip if due date is in the past
-	if task.DueAt.Before(time.Now()) {
+	now := time.Now()
+	if task.DueAt.Before(now) {
 		return nil
 	}
 
@@ -517,38 +1075,23 @@ func (s *Service) ScheduleNotificationsForTask(task *Task) error {
 	if err != nil {
 		return fmt.Errorf("failed to get group members: %w", err)
 	}
+	optedOut, err := s.store.GetTaskReminderOptOuts(task.ID)
+	if err != nil {
+		return err
+	}
 
 	// Schedule notifications for each member
 	for _, member := range members {
-		// Get user's notification settings
-		settings, err := s.store.GetNotificationSettings(member.ID)
-		if err != nil {
-			return fmt.Errorf("failed to get notification settings for user %d: %w", member.ID, err)
-		}
-
-		// Schedule "on_deadline" notification
-		onDeadlineNotif := &TaskNotification{
-			TaskID:           task.ID,
-			UserID:           member.ID,
-			NotificationType: "on_deadline",
-			ScheduledAt:      *task.DueAt,
-		}
-		if err := s.store.CreateNotification(onDeadlineNotif); err != nil {
-			return fmt.Errorf("failed to create on_deadline notification: %w", err)
+		if !optedOut[member.ID] {
+			if err := s.scheduleDeadlineReminders(task, member.ID, now); err != nil {
+				return err
+			}
 		}
 
-		// Schedule "before_deadline" notification
-		beforeDeadline := task.DueAt.Add(-time.Duration(settings.ReminderDeltaMinutes) * time.Minute)
-		// Only schedule if it's still in the future
-		if beforeDeadline.After(time.Now()) {
-			beforeDeadlineNotif := &TaskNotification{
-				TaskID:           task.ID,
-				UserID:           member.ID,
-				NotificationType: "before_deadline",
-				ScheduledAt:      beforeDeadline,
-			}
-			if err := s.store.CreateNotification(beforeDeadlineNotif); err != nil {
-				return fmt.Errorf("failed to create before_deadline notification: %w", err)
+		// Schedule the first "nag" after the deadline is missed
+		if task.Deadline.NagEnabled() {
+			if err := s.scheduleFirstNag(task, member.ID); err != nil {
+				return err
 			}
 		}
 	}
@@ -563,6 +1106,7 @@ func (s *Service) RescheduleNotificationsForTask(taskID int64, newDueAt *time.Ti
 	if err := s.store.DeleteNotificationsByTask(taskID); err != nil {
 		return fmt.Errorf("failed to delete existing notifications: %w", err)
 	}
+	s.wakeNotificationWorker()
 
 	// If newDueAt is nil, we're done (just cancelled notifications)
 	if newDueAt == nil {
@@ -585,67 +1129,11 @@ func (s *Service) RescheduleNotificationsForTask(taskID int64, newDueAt *time.Ti
 // CancelNotificationsForTask deletes all pending notifications for a task
 // This should be called when a task is marked done or deleted
 func (s *Service) CancelNotificationsForTask(taskID int64) error {
-	return s.store.DeleteNotificationsByTask(taskID)
-}
-
-// StartNotificationWorker runs a background goroutine that checks for pending notifications
-// and sends them via Telegram bot. It runs with a 1-minute ticker and handles graceful shutdown.
-//
-// TODO: Future improvements:
-// - Add retry logic for failed notification sends
-// - Implement batch notification processing for better performance
-// - Add metrics/monitoring for notification delivery
-// - Consider using a proper job queue system for high-scale deployments
-func (s *Service) StartNotificationWorker(ctx context.Context, bot BotNotifier) {
-	// Use a ticker that fires every minute
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
-
-	log := func(format string, args ...interface{}) {
-		// Simple logging helper
-		fmt.Printf("[NotificationWorker] "+format+"\n", args...)
-	}
-
-	log("Starting notification worker...")
-
-	for {
-		select {
-		case <-ctx.Done():
-			log("Shutdown signal received, stopping notification worker...")
-			return
-
-		case <-ticker.C:
-			// Get pending notifications
-			now := time.Now()
-			notifications, err := s.store.GetPendingNotifications(now)
-			if err != nil {
-				log("Error fetching pending notifications: %v", err)
-				continue
-			}
-
-			if len(notifications) == 0 {
-				continue // No notifications to send
-			}
-
-			log("Found %d pending notification(s) to send", len(notifications))
-
-			// Process each notification
-			for _, notif := range notifications {
-				if err := s.sendNotification(notif, bot); err != nil {
-					log("Error sending notification %d: %v", notif.ID, err)
-					// Don't mark as sent if there was an error
-					// TODO: Implement retry logic with exponential backoff
-					continue
-				}
-
-				// Mark as sent
-				if err := s.store.MarkNotificationSent(notif.ID); err != nil {
-					log("Error marking notification %d as sent: %v", notif.ID, err)
-					// Continue anyway - we sent the notification successfully
-				}
-			}
-		}
+	if err := s.store.DeleteNotificationsByTask(taskID); err != nil {
+		return err
 	}
+	s.wakeNotificationWorker()
+	return nil
 }
 
 // BotNotifier interface defines the method needed to send notifications via Telegram
@@ -653,23 +1141,32 @@ type BotNotifier interface {
 	SendNotification(chatID int64, message string, buttons map[string]string) error
 }
 
-// sendNotification sends a single notification via Telegram
-func (s *Service) sendNotification(notif *TaskNotification, bot BotNotifier) error {
+// sendNotification sends a single notification over the user's channels
+func (s *Service) sendNotification(ctx context.Context, notif *TaskNotification) error {
 	// Get task details
 	task, err := s.store.GetTaskByID(notif.TaskID)
 	if err != nil {
 		return fmt.Errorf("failed to get task: %w", err)
 	}
 
-	// Get user to get their Telegram ID
-	user, err := s.store.GetUserByID(notif.UserID)
+	// Archived groups are read-only and stay silent
+	group, err := s.store.GetGroupByID(task.GroupID)
 	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
+		return fmt.Errorf("failed to get group: %w", err)
+	}
+	if group.IsArchived() {
+		return nil
 	}
 
-	// Skip if user has no Telegram ID
-	if user.TelegramID == nil {
-		return fmt.Errorf("user has no Telegram ID")
+	// Resting members and groups are not reminded; resuming the pause
+	// reschedules the reminders that still matter
+	if s.pausedAt(notif.UserID, group.ID, time.Now()) != nil {
+		return nil
+	}
+
+	user, err := s.store.GetUserByID(notif.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
 	}
 
 	// Check if user has notifications enabled
@@ -679,13 +1176,25 @@ func (s *Service) sendNotification(notif *TaskNotification, bot BotNotifier) err
 		return nil
 	}
 
+	if notif.NotificationType == "nag_partner" && notif.AboutUserID != nil {
+		member, err := s.store.GetUserByID(*notif.AboutUserID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		return s.deliverMessage(ctx, user, nagPartnerMessage(notif, task, member, user))
+	}
+
 	// Build notification message
+	heading := "⏰ Task Reminder"
+	if notif.NotificationType == "nag" {
+		heading = fmt.Sprintf("🔁 Still not done (reminder %d)", notif.Stage)
+	}
 	var message string
 	if task.DueAt != nil {
-		dueTimeStr := task.DueAt.Format("Mon, 02 Jan 2006 at 15:04")
-		message = fmt.Sprintf("⏰ Task Reminder\n\nTask: %s\nDue: %s", task.Title, dueTimeStr)
+		dueTimeStr := task.DueAt.In(user.Location()).Format("Mon, 02 Jan 2006 at 15:04 MST")
+		message = fmt.Sprintf("%s\n\nTask: %s\nDue: %s", heading, task.Title, dueTimeStr)
 	} else {
-		message = fmt.Sprintf("⏰ Task Reminder\n\nTask: %s", task.Title)
+		message = fmt.Sprintf("%s\n\nTask: %s", heading, task.Title)
 	}
 
 	if task.Description != "" {
@@ -698,10 +1207,28 @@ func (s *Service) sendNotification(notif *TaskNotification, bot BotNotifier) err
 		"✅ Done":               fmt.Sprintf("notify_done_%d", notif.ID),
 		"⏰ Will do in 15 mins": fmt.Sprintf("notify_snooze_%d", notif.ID),
 		"🔔 Remind later":       fmt.Sprintf("notify_later_%d", notif.ID),
+		"💤 Hide until later":   fmt.Sprintf("hide:%d", task.ID),
+	}
+	if notif.NotificationType == "nag" {
+		// Nags keep coming until the task is done, so they offer a way out
+		// instead of putting the reminder off
+		delete(buttons, "🔔 Remind later")
+		delete(buttons, "💤 Hide until later")
+		buttons["🔕 Stop reminding"] = fmt.Sprintf("notify_stop_%d", notif.ID)
 	}
 
-	// Send via bot
-	return bot.SendNotification(*user.TelegramID, message, buttons)
+	return s.deliverMessage(ctx, user, &Message{
+		Subject:          heading + ": " + task.Title,
+		Text:             message,
+		Path:             fmt.Sprintf("/groups/%d", group.ID),
+		Buttons:          buttons,
+		Event:            "task_reminder",
+		NotificationID:   notif.ID,
+		NotificationType: notif.NotificationType,
+		TaskID:           task.ID,
+		TaskTitle:        task.Title,
+		DueAt:            task.DueAt,
+	})
 }
 
 // GetNotificationByID retrieves a notification by its ID
@@ -716,7 +1243,7 @@ func (s *Service) GetNotificationSettings(userID int64) (*NotificationSettings,
 
 // CreateNotification creates a new notification (used for snoozing)
 func (s *Service) CreateNotification(notification *TaskNotification) error {
-	return s.store.CreateNotification(notification)
+	return s.createNotification(notification)
 }
 
 // generateInviteCode generates a random invite code
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// StarterPack is a bundled group definition offered when creating a group,
+// translated into one or more locales
+type StarterPack struct {
+	ID          string
+	Definitions map[string]*GroupDefinition // Keyed by locale
+}
+
+// Definition returns the pack in the given locale, falling back to English
+func (p *StarterPack) Definition(locale string) *GroupDefinition {
+	if def, ok := p.Definitions[locale]; ok {
+		return def
+	}
+	return p.Definitions["en"]
+}
+
+// LoadStarterPacks reads the packs in dir, one file per pack and locale named
+// like chores.en.yaml. Like locales, dir is also looked up next to the binary.
+func LoadStarterPacks(dir string) ([]*StarterPack, error) {
+	roots := []string{dir}
+	if exe, err := os.Executable(); err == nil {
+		roots = append(roots, filepath.Join(filepath.Dir(exe), dir))
+	}
+
+	packs := map[string]*StarterPack{}
+	for _, root := range roots {
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !strings.HasSuffix(d.Name(), ".yaml") {
+				return nil
+			}
+			id, locale, ok := strings.Cut(strings.TrimSuffix(d.Name(), ".yaml"), ".")
+			if !ok {
+				return fmt.Errorf("starter pack %s: name it <pack>.<locale>.yaml", path)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("read starter pack %s: %w", path, err)
+			}
+			def, err := ParseGroupDefinition(data)
+			if err != nil {
+				return fmt.Errorf("starter pack %s: %w", path, err)
+			}
+			if packs[id] == nil {
+				packs[id] = &StarterPack{ID: id, Definitions: map[string]*GroupDefinition{}}
+			}
+			packs[id].Definitions[locale] = def
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(packs) > 0 {
+			break
+		}
+	}
+
+	var list []*StarterPack
+	for _, pack := range packs {
+		if pack.Definitions["en"] == nil {
+			return nil, fmt.Errorf("starter pack %s has no English version", pack.ID)
+		}
+		list = append(list, pack)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
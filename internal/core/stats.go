@@ -0,0 +1,244 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Stats intervals: the size of one bucket of a stats chart
+const (
+	StatsDaily  = "day"
+	StatsWeekly = "week"
+)
+
+// How many buckets a stats page covers, ending with the current one
+const (
+	statsDays  = 30
+	statsWeeks = 12
+)
+
+// GroupStats is the history of a group bucketed by day or week, for charts
+type GroupStats struct {
+	Group      *Group
+	Interval   string    // StatsDaily or StatsWeekly
+	Start      time.Time // Local midnight the first bucket starts at
+	End        time.Time // Local midnight after the last bucket
+	Buckets    []*StatsBucket
+	Tasks      []*ReportTask // Completions of each task over the whole range
+	Quantities []*StatsQuantity
+	Members    []*StatsMember
+}
+
+// StatsBucket is the activity in one day or week. The store returns one per
+// member and bucket with UserID set; GroupStats sums them for the group.
+type StatsBucket struct {
+	UserID      int64
+	Start       time.Time // Local midnight, a Monday for weeks
+	Completions int
+	Earned      int // Cheese from tasks
+	Spent       int // Cheese spent in the shop
+}
+
+// StatsQuantity is the units done of an integer task in each bucket, e.g.
+// pushups per week. The store returns one per task and bucket; GroupStats
+// collects them per task in Points, aligned with its Buckets.
+type StatsQuantity struct {
+	TaskID   int64
+	Title    string
+	Start    time.Time
+	Quantity int
+	Points   []int
+}
+
+// StatsMember is a member's totals and cheese earned per bucket
+type StatsMember struct {
+	UserID      int64
+	Username    string
+	Completions int
+	Earned      int
+	Spent       int
+	Points      []int // Cheese earned, aligned with GroupStats.Buckets
+}
+
+// Total returns the units done over the whole range
+func (q *StatsQuantity) Total() int {
+	total := 0
+	for _, p := range q.Points {
+		total += p
+	}
+	return total
+}
+
+// ParseStatsInterval reads "day" or "week", defaulting to days
+func ParseStatsInterval(s string) (string, bool) {
+	switch s {
+	case "", StatsDaily:
+		return StatsDaily, true
+	case StatsWeekly:
+		return StatsWeekly, true
+	}
+	return "", false
+}
+
+// StatsRange returns the local days a stats page covers: the last 30 days or
+// 12 weeks (from Monday), including the current one
+func StatsRange(interval string, now time.Time) (start, end time.Time) {
+	if interval == StatsWeekly {
+		_, end = ReportRange(ReportWeek, now, 0)
+		return end.AddDate(0, 0, -7*statsWeeks), end
+	}
+	end = startOfDay(now).AddDate(0, 0, 1)
+	return end.AddDate(0, 0, -statsDays), end
+}
+
+// BucketStarts returns the start of every bucket in the range, in order
+func (g *GroupStats) BucketStarts() []time.Time {
+	step := 1
+	if g.Interval == StatsWeekly {
+		step = 7
+	}
+	var starts []time.Time
+	for t := g.Start; t.Before(g.End); t = t.AddDate(0, 0, step) {
+		starts = append(starts, t)
+	}
+	return starts
+}
+
+// Earned returns the cheese earned with tasks over the whole range
+func (g *GroupStats) Earned() int {
+	total := 0
+	for _, b := range g.Buckets {
+		total += b.Earned
+	}
+	return total
+}
+
+// Spent returns the cheese spent in the shop over the whole range
+func (g *GroupStats) Spent() int {
+	total := 0
+	for _, b := range g.Buckets {
+		total += b.Spent
+	}
+	return total
+}
+
+// Completions returns the tasks done over the whole range
+func (g *GroupStats) Completions() int {
+	total := 0
+	for _, b := range g.Buckets {
+		total += b.Completions
+	}
+	return total
+}
+
+// MemberPercent scales a member's earnings against the top earner, for bars
+func (g *GroupStats) MemberPercent(member *StatsMember) int {
+	top := 0
+	for _, m := range g.Members {
+		if m.Earned > top {
+			top = m.Earned
+		}
+	}
+	if top <= 0 || member.Earned <= 0 {
+		return 0
+	}
+	return member.Earned * 100 / top
+}
+
+// BuildGroupStats charts the recent history of a group for a member of it.
+// Buckets are cut in the viewer's timezone.
+func (s *Service) BuildGroupStats(viewerID, groupID int64, interval string, now time.Time) (*GroupStats, error) {
+	if interval != StatsDaily && interval != StatsWeekly {
+		return nil, fmt.Errorf("unknown stats interval %q", interval)
+	}
+	isMember, err := s.store.IsUserInGroup(viewerID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, fmt.Errorf("user is not a member of this group")
+	}
+	viewer, err := s.store.GetUserByID(viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	group, err := s.store.GetGroupByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.store.GetUsersByGroupID(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+
+	stats := &GroupStats{Group: group, Interval: interval}
+	stats.Start, stats.End = StatsRange(interval, now.In(viewer.Location()))
+	starts := stats.BucketStarts()
+	index := make(map[string]int, len(starts))
+	for i, t := range starts {
+		stats.Buckets = append(stats.Buckets, &StatsBucket{Start: t})
+		index[t.Format("2006-01-02")] = i
+	}
+
+	byMember := make(map[int64]*StatsMember, len(members))
+	for _, m := range members {
+		row := &StatsMember{UserID: m.ID, Username: m.Username, Points: make([]int, len(starts))}
+		byMember[m.ID] = row
+		stats.Members = append(stats.Members, row)
+	}
+
+	rows, err := s.store.GetStatsBuckets(groupID, interval, stats.Start, stats.End)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		i, ok := index[row.Start.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		bucket := stats.Buckets[i]
+		bucket.Completions += row.Completions
+		bucket.Earned += row.Earned
+		bucket.Spent += row.Spent
+		// Members who left keep counting toward the group
+		if m := byMember[row.UserID]; m != nil {
+			m.Completions += row.Completions
+			m.Earned += row.Earned
+			m.Spent += row.Spent
+			m.Points[i] += row.Earned
+		}
+	}
+	sort.SliceStable(stats.Members, func(i, j int) bool {
+		return stats.Members[i].Earned > stats.Members[j].Earned
+	})
+
+	stats.Tasks, err = s.store.GetTaskReport(groupID, 0, stats.Start, stats.End)
+	if err != nil {
+		return nil, err
+	}
+
+	quantities, err := s.store.GetQuantityStats(groupID, interval, stats.Start, stats.End)
+	if err != nil {
+		return nil, err
+	}
+	byTask := make(map[int64]*StatsQuantity)
+	for _, q := range quantities {
+		i, ok := index[q.Start.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		series := byTask[q.TaskID]
+		if series == nil {
+			series = &StatsQuantity{TaskID: q.TaskID, Title: q.Title, Points: make([]int, len(starts))}
+			byTask[q.TaskID] = series
+			stats.Quantities = append(stats.Quantities, series)
+		}
+		series.Points[i] += q.Quantity
+	}
+	sort.SliceStable(stats.Quantities, func(i, j int) bool {
+		return stats.Quantities[i].Total() > stats.Quantities[j].Total()
+	})
+
+	return stats, nil
+}
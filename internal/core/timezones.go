@@ -0,0 +1,79 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// MorningHour is the local hour "tomorrow morning" reminders are moved to
+const MorningHour = 9
+
+// Location returns the user's timezone, falling back to server time when it
+// is unset or no longer known
+func (u *User) Location() *time.Location {
+	if u == nil || u.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// NormalizeTimezone validates an IANA timezone name. Empty clears the setting.
+func NormalizeTimezone(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil
+	}
+	// "Local" would silently mean the server's zone
+	if strings.EqualFold(name, "local") {
+		return "", fmt.Errorf("unknown timezone %q", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return "", fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc.String(), nil
+}
+
+// SetUserTimezone stores the user's timezone, e.g. "Europe/Berlin"
+func (s *Service) SetUserTimezone(userID int64, timezone string) (string, error) {
+	timezone, err := NormalizeTimezone(timezone)
+	if err != nil {
+		return "", err
+	}
+	return timezone, s.store.UpdateUserTimezone(userID, timezone)
+}
+
+// NextMorning returns the next MorningHour o'clock after now in loc
+func NextMorning(now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	morning := time.Date(local.Year(), local.Month(), local.Day(), MorningHour, 0, 0, 0, loc)
+	if !morning.After(now) {
+		morning = time.Date(local.Year(), local.Month(), local.Day()+1, MorningHour, 0, 0, 0, loc)
+	}
+	return morning
+}
+
+// userTime formats t in the user's timezone for messages
+func (s *Service) userTime(userID int64, t time.Time) string {
+	user, err := s.store.GetUserByID(userID)
+	if err != nil {
+		return t.Format("Jan 2, 15:04")
+	}
+	return t.In(user.Location()).Format("Jan 2, 15:04")
+}
+
+// groupLocation returns the timezone a group's calendar runs in: its owner's
+func (s *Service) groupLocation(group *Group) *time.Location {
+	owner, err := s.store.GetUserByID(group.OwnerID)
+	if err != nil {
+		log.Printf("Failed to get owner of group %d: %v", group.ID, err)
+		return time.Local
+	}
+	return owner.Location()
+}
@@ -0,0 +1,271 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// AddToWishlist pins a shop item to the user's wishlist
+func (s *Service) AddToWishlist(userID, shopItemID int64) (*WishlistItem, error) {
+	item, err := s.store.GetShopItemByID(shopItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember, err := s.store.IsUserInGroup(userID, item.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, fmt.Errorf("user is not a member of this group")
+	}
+	if err := s.ensureGroupWritable(item.GroupID); err != nil {
+		return nil, err
+	}
+
+	return s.store.AddWishlistItem(userID, item.GroupID, item.ID)
+}
+
+// getOwnWishlistItem loads a wishlist entry and verifies it belongs to userID
+func (s *Service) getOwnWishlistItem(userID, entryID int64) (*WishlistItem, error) {
+	entry, err := s.store.GetWishlistItemByID(entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.UserID != userID {
+		return nil, fmt.Errorf("wishlist item not found")
+	}
+	return entry, nil
+}
+
+// RemoveFromWishlist unpins a wishlist entry, releasing any reserved cheese
+func (s *Service) RemoveFromWishlist(userID, entryID int64) (*WishlistItem, error) {
+	entry, err := s.getOwnWishlistItem(userID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	return entry, s.store.DeleteWishlistItem(entry.ID)
+}
+
+// ReserveForWishlist locks amount cheese toward a wishlist entry. Reserved cheese
+// still counts toward the balance but can only be spent on that item.
+// Passing 0 releases the reservation.
+func (s *Service) ReserveForWishlist(userID, entryID int64, amount int) (*WishlistItem, error) {
+	entry, err := s.getOwnWishlistItem(userID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	if amount < 0 {
+		return nil, fmt.Errorf("reserved amount cannot be negative")
+	}
+	if err := s.ensureGroupWritable(entry.GroupID); err != nil {
+		return nil, err
+	}
+
+	item, err := s.store.GetShopItemByID(entry.ShopItemID)
+	if err != nil {
+		return nil, err
+	}
+	if amount > item.Cost {
+		return nil, fmt.Errorf("cannot reserve more than the item costs (%d)", item.Cost)
+	}
+
+	available, err := s.availableBalance(userID, entry.GroupID, entry.ShopItemID)
+	if err != nil {
+		return nil, err
+	}
+	if amount > available {
+		return nil, fmt.Errorf("insufficient balance: have %d available, want to reserve %d", available, amount)
+	}
+
+	if err := s.store.SetWishlistReserved(entry.ID, amount); err != nil {
+		return nil, err
+	}
+	entry.Reserved = amount
+	return entry, nil
+}
+
+// GetReservedBalance returns the cheese a user locked toward wishlist goals in a group
+func (s *Service) GetReservedBalance(userID, groupID int64) (int, error) {
+	return s.store.GetReservedTotal(userID, groupID)
+}
+
+// availableBalance returns the cheese a user may spend on shopItemID: the
+// balance minus reservations held for other wishlist items. Use 0 for a
+// spend that is not a shop item, so every reservation is excluded.
+func (s *Service) availableBalance(userID, groupID, shopItemID int64) (int, error) {
+	balance, err := s.store.GetBalance(userID, groupID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	entries, err := s.store.GetWishlistByUserAndGroup(userID, groupID)
+	if err != nil {
+		return 0, err
+	}
+	for _, entry := range entries {
+		if entry.ShopItemID != shopItemID {
+			balance -= entry.Reserved
+		}
+	}
+
+	return balance, nil
+}
+
+// GetWishlistByGroup returns the user's wishlist entries in a group keyed by shop item ID
+func (s *Service) GetWishlistByGroup(userID, groupID int64) (map[int64]*WishlistItem, error) {
+	entries, err := s.store.GetWishlistByUserAndGroup(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	byItem := make(map[int64]*WishlistItem, len(entries))
+	for _, entry := range entries {
+		byItem[entry.ShopItemID] = entry
+	}
+	return byItem, nil
+}
+
+// GetWishlistProgress returns progress toward every wishlist item of a user in active groups
+func (s *Service) GetWishlistProgress(userID int64) ([]*WishlistProgress, error) {
+	entries, err := s.store.GetWishlistByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var progress []*WishlistProgress
+	for _, entry := range entries {
+		p, err := s.wishlistProgress(entry)
+		if err != nil {
+			// Deleted shop items keep their entry so an undo restores the goal
+			continue
+		}
+		if p.Group.IsArchived() {
+			continue
+		}
+		progress = append(progress, p)
+	}
+
+	return progress, nil
+}
+
+// wishlistProgress computes how close the entry's owner is to affording the item
+func (s *Service) wishlistProgress(entry *WishlistItem) (*WishlistProgress, error) {
+	item, err := s.store.GetShopItemByID(entry.ShopItemID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadShopItemPrices(item.GroupID, item); err != nil {
+		return nil, err
+	}
+	group, err := s.store.GetGroupByID(entry.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := s.availableBalance(entry.UserID, entry.GroupID, entry.ShopItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &WishlistProgress{Entry: entry, ShopItem: item, Group: group, Available: available, Percent: 100, Affordable: true}
+
+	// The slowest currency decides the progress
+	track := func(have, need int) {
+		if need <= 0 {
+			return
+		}
+		if have < need {
+			p.Affordable = false
+		}
+		if have < 0 {
+			have = 0
+		}
+		percent := 100
+		if have < need {
+			percent = have * 100 / need
+		}
+		if percent < p.Percent {
+			p.Percent = percent
+		}
+	}
+
+	track(available, item.Cost)
+	for _, price := range item.ExtraPrices {
+		have, err := s.store.GetCurrencyBalance(entry.UserID, entry.GroupID, price.CurrencyID)
+		if err != nil {
+			return nil, err
+		}
+		track(have, price.Amount)
+	}
+
+	return p, nil
+}
+
+// NotifyAffordableWishlistItems tells members when a wishlist item became affordable.
+// Each entry is announced once; the flag resets when the item stops being affordable.
+func (s *Service) NotifyAffordableWishlistItems(bot BotNotifier) error {
+	entries, err := s.store.GetAllWishlistItems()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		p, err := s.wishlistProgress(entry)
+		if err != nil || p.Group.IsArchived() {
+			continue
+		}
+		// Held back while resting, announced once the pause ends
+		if s.pausedAt(entry.UserID, entry.GroupID, time.Now()) != nil {
+			continue
+		}
+
+		if !p.Affordable {
+			if entry.NotifiedAt != nil {
+				if err := s.store.SetWishlistNotified(entry.ID, nil); err != nil {
+					log.Printf("Failed to reset wishlist notification %d: %v", entry.ID, err)
+				}
+			}
+			continue
+		}
+		if entry.NotifiedAt != nil {
+			continue
+		}
+
+		if err := s.sendWishlistNotification(p, bot); err != nil {
+			log.Printf("Failed to send wishlist notification %d: %v", entry.ID, err)
+			continue
+		}
+
+		now := time.Now()
+		if err := s.store.SetWishlistNotified(entry.ID, &now); err != nil {
+			log.Printf("Failed to mark wishlist notification %d: %v", entry.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// sendWishlistNotification sends the "you can afford it" message via Telegram
+func (s *Service) sendWishlistNotification(p *WishlistProgress, bot BotNotifier) error {
+	user, err := s.store.GetUserByID(p.Entry.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.TelegramID == nil {
+		return nil
+	}
+
+	profile, err := s.store.GetUserProfile(user.ID)
+	if err == nil && profile != nil && !profile.NotificationEnabled {
+		return nil
+	}
+
+	message := fmt.Sprintf("🎯 Savings goal reached!\n\nYou can now afford %s in %s.", p.ShopItem.Title, p.Group.Name)
+	buttons := map[string]string{
+		"🛒 Buy now": fmt.Sprintf("wishbuy:%d", p.ShopItem.ID),
+	}
+
+	return bot.SendNotification(*user.TelegramID, message, buttons)
+}
@@ -0,0 +1,184 @@
+// Package notify implements the notification channels that do not go
+// through the Telegram bot: email, webhooks and Web Push.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// sendTimeout bounds a single delivery on any channel
+const sendTimeout = 30 * time.Second
+
+// EmailConfig is the SMTP server reminders are sent through
+type EmailConfig struct {
+	Host      string
+	Port      int    // 587 when zero
+	Username  string // Optional; the password is only sent over TLS or to localhost
+	Password  string
+	From      string // Sender, e.g. "Small RPG <rpg@example.com>"
+	PublicURL string // Base of the links in messages
+}
+
+// EmailChannel sends reminders as plain-text emails
+type EmailChannel struct {
+	cfg  EmailConfig
+	from *mail.Address
+}
+
+// NewEmailChannel checks the configuration and returns the channel
+func NewEmailChannel(cfg EmailConfig) (*EmailChannel, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("SMTP host is required")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", cfg.From, err)
+	}
+	return &EmailChannel{cfg: cfg, from: from}, nil
+}
+
+// Name implements core.NotificationChannel
+func (c *EmailChannel) Name() string {
+	return core.ChannelEmail
+}
+
+// Send implements core.NotificationChannel
+func (c *EmailChannel) Send(ctx context.Context, to *core.Recipient, msg *core.Message) error {
+	if to.Address == "" {
+		return core.ErrNoRecipient
+	}
+	body, err := c.compose(to.Address, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	return c.deliver(ctx, to.Address, body)
+}
+
+// compose renders the message as a MIME email
+func (c *EmailChannel) compose(address string, msg *core.Message, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", c.from.String())
+	header("To", address)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", c.messageID())
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	text := msg.Text
+	if link := absoluteURL(c.cfg.PublicURL, msg.Path); link != "" {
+		text += "\n\n" + link
+	}
+	text += "\n\n-- \nYou get these emails because email reminders are on in your dashboard."
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(text)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// messageID returns a unique Message-ID in the sender's domain
+func (c *EmailChannel) messageID() string {
+	random := make([]byte, 12)
+	rand.Read(random)
+	domain := "localhost"
+	if at := strings.LastIndex(c.from.Address, "@"); at >= 0 {
+		domain = c.from.Address[at+1:]
+	}
+	return "<" + hex.EncodeToString(random) + "@" + domain + ">"
+}
+
+// deliver hands the email to the SMTP server, upgrading to TLS when offered
+func (c *EmailChannel) deliver(ctx context.Context, address string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port)))
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	// Abort a stuck conversation when the worker shuts down or times out
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, c.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to greet SMTP server: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.cfg.Host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if c.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(c.from.Address); err != nil {
+		return fmt.Errorf("sender rejected: %w", err)
+	}
+	if err := client.Rcpt(address); err != nil {
+		// Mailbox unknown or refused; retrying will not change that
+		var smtpErr *textproto.Error
+		if errors.As(err, &smtpErr) && smtpErr.Code >= 550 && smtpErr.Code <= 553 {
+			return fmt.Errorf("%w: %v", core.ErrRecipientBlocked, err)
+		}
+		return fmt.Errorf("recipient rejected: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+	return client.Quit()
+}
+
+// absoluteURL joins the public base URL and a page path, or returns "" when
+// the message links nowhere
+func absoluteURL(base, path string) string {
+	if path == "" {
+		return ""
+	}
+	return strings.TrimRight(base, "/") + path
+}
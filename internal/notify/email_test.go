@@ -0,0 +1,185 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// fakeSMTP is a minimal SMTP server that accepts one message per connection
+// and rejects recipients at unknown.example
+type fakeSMTP struct {
+	listener net.Listener
+	messages chan received
+}
+
+type received struct {
+	from, to string
+	data     string
+}
+
+func startFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &fakeSMTP{listener: listener, messages: make(chan received, 10)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (f *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	var msg received
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-fake")
+			reply("250 8BITMIME")
+		case "MAIL":
+			msg.from = addressOf(line)
+			reply("250 OK")
+		case "RCPT":
+			msg.to = addressOf(line)
+			if strings.HasSuffix(msg.to, "@unknown.example") {
+				reply("550 No such user")
+				continue
+			}
+			reply("250 OK")
+		case "DATA":
+			reply("354 Go ahead")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			msg.data = data.String()
+			f.messages <- msg
+			reply("250 Queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// addressOf extracts the address from "MAIL FROM:<a@b>" or "RCPT TO:<a@b>"
+func addressOf(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+func newTestEmailChannel(t *testing.T, server *fakeSMTP) *EmailChannel {
+	t.Helper()
+	addr := server.listener.Addr().(*net.TCPAddr)
+	channel, err := NewEmailChannel(EmailConfig{
+		Host:      "127.0.0.1",
+		Port:      addr.Port,
+		From:      "Small RPG <rpg@example.com>",
+		PublicURL: "https://rpg.example.com/",
+	})
+	if err != nil {
+		t.Fatalf("NewEmailChannel: %v", err)
+	}
+	return channel
+}
+
+func TestEmailChannelSendsReminder(t *testing.T) {
+	server := startFakeSMTP(t)
+	channel := newTestEmailChannel(t, server)
+
+	to := &core.Recipient{User: &core.User{ID: 1, Username: "alice"}, Address: "alice@example.com"}
+	msg := &core.Message{
+		Subject: "⏰ Task Reminder: Полить цветы",
+		Text:    "⏰ Task Reminder\n\nTask: Полить цветы",
+		Path:    "/groups/3",
+	}
+	if err := channel.Send(context.Background(), to, msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	got := <-server.messages
+	if got.from != "rpg@example.com" || got.to != "alice@example.com" {
+		t.Errorf("envelope = %q -> %q", got.from, got.to)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(got.data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("subject = %q (%v), want %q", subject, err, msg.Subject)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	if err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	for _, want := range []string{"Task: Полить цветы", "https://rpg.example.com/groups/3"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("body %q does not contain %q", body, want)
+		}
+	}
+}
+
+func TestEmailChannelRejectedRecipient(t *testing.T) {
+	server := startFakeSMTP(t)
+	channel := newTestEmailChannel(t, server)
+
+	to := &core.Recipient{User: &core.User{ID: 1}, Address: "ghost@unknown.example"}
+	err := channel.Send(context.Background(), to, &core.Message{Subject: "Hi", Text: "Hi"})
+	if !errors.Is(err, core.ErrRecipientBlocked) {
+		t.Fatalf("Send error = %v, want ErrRecipientBlocked", err)
+	}
+}
+
+func TestEmailChannelWithoutAddress(t *testing.T) {
+	server := startFakeSMTP(t)
+	channel := newTestEmailChannel(t, server)
+
+	err := channel.Send(context.Background(), &core.Recipient{User: &core.User{ID: 1}}, &core.Message{Subject: "Hi"})
+	if !errors.Is(err, core.ErrNoRecipient) {
+		t.Fatalf("Send error = %v, want ErrNoRecipient", err)
+	}
+}
@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// WebhookChannel posts reminders as JSON to a URL of the user's choosing
+type WebhookChannel struct {
+	secret    string
+	publicURL string
+	client    *http.Client
+}
+
+// NewWebhookChannel returns the channel. When secret is set, every request
+// is signed with it in the X-Small-RPG-Signature header. Unless allowPrivate
+// is set, webhooks may only reach public addresses, so users cannot make the
+// server call into its own network.
+func NewWebhookChannel(secret, publicURL string, allowPrivate bool) *WebhookChannel {
+	return &WebhookChannel{
+		secret:    secret,
+		publicURL: publicURL,
+		client:    newOutboundClient(allowPrivate),
+	}
+}
+
+// newOutboundClient returns the client for requests to user-chosen URLs.
+// Unless allowPrivate is set, it only connects to public addresses.
+func newOutboundClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !allowPrivate {
+		dialer.Control = publicAddressOnly
+	}
+	return &http.Client{
+		Timeout:   sendTimeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+}
+
+// webhookPayload is the JSON body of a webhook request
+type webhookPayload struct {
+	Event            string     `json:"event"`
+	Subject          string     `json:"subject"`
+	Text             string     `json:"text"`
+	URL              string     `json:"url,omitempty"`
+	UserID           int64      `json:"user_id"`
+	Username         string     `json:"username"`
+	NotificationID   int64      `json:"notification_id,omitempty"`
+	NotificationType string     `json:"notification_type,omitempty"`
+	TaskID           int64      `json:"task_id,omitempty"`
+	TaskTitle        string     `json:"task_title,omitempty"`
+	DueAt            *time.Time `json:"due_at,omitempty"`
+	SentAt           time.Time  `json:"sent_at"`
+}
+
+// Name implements core.NotificationChannel
+func (c *WebhookChannel) Name() string {
+	return core.ChannelWebhook
+}
+
+// Send implements core.NotificationChannel
+func (c *WebhookChannel) Send(ctx context.Context, to *core.Recipient, msg *core.Message) error {
+	if to.Address == "" {
+		return core.ErrNoRecipient
+	}
+
+	payload := webhookPayload{
+		Event:            msg.Event,
+		Subject:          msg.Subject,
+		Text:             msg.Text,
+		URL:              absoluteURL(c.publicURL, msg.Path),
+		UserID:           to.User.ID,
+		Username:         to.User.Username,
+		NotificationID:   msg.NotificationID,
+		NotificationType: msg.NotificationType,
+		TaskID:           msg.TaskID,
+		TaskTitle:        msg.TaskTitle,
+		DueAt:            msg.DueAt,
+		SentAt:           time.Now().UTC(),
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, to.Address, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "small-rpg-webhook/1.0")
+	req.Header.Set("X-Small-RPG-Event", msg.Event)
+	if c.secret != "" {
+		mac := hmac.New(sha256.New, []byte(c.secret))
+		mac.Write(body)
+		req.Header.Set("X-Small-RPG-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusGone:
+		// The receiver tells us to stop calling
+		return fmt.Errorf("%w: webhook returned %s", core.ErrRecipientBlocked, resp.Status)
+	}
+	return fmt.Errorf("webhook returned %s", resp.Status)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, also used
+// for internal hosts by overlay networks such as Tailscale. net.IP.IsPrivate
+// does not include it.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// publicAddressOnly refuses connections to loopback, private and other
+// non-public addresses. It runs after DNS resolution, so hostnames that
+// point inside the network are caught as well.
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() ||
+		sharedAddressSpace.Contains(ip) { // Contains matches the IPv4-mapped form too
+		return fmt.Errorf("webhook address %s is not public", host)
+	}
+	return nil
+}
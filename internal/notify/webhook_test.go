@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+func TestPublicAddressOnly(t *testing.T) {
+	tests := []struct {
+		address string
+		public  bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:2800:220:1::]:443", true},
+		{"100.63.255.255:80", true},
+		{"100.128.0.0:80", true},
+		{"127.0.0.1:80", false},
+		{"10.0.0.1:80", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"[::1]:80", false},
+		{"100.64.0.1:80", false},
+		{"100.127.255.254:80", false},
+		{"[::ffff:100.64.0.1]:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+	}
+	for _, tt := range tests {
+		err := publicAddressOnly("tcp", tt.address, nil)
+		if (err == nil) != tt.public {
+			t.Errorf("publicAddressOnly(%s) = %v, want public %v", tt.address, err, tt.public)
+		}
+	}
+}
+
+func TestWebhookRefusesSharedAddressSpace(t *testing.T) {
+	channel := NewWebhookChannel("", "", false)
+	for _, url := range []string{"http://100.64.0.1/hook", "http://[::ffff:100.100.100.100]/hook"} {
+		to := &core.Recipient{User: &core.User{ID: 1, Username: "alice"}, Address: url}
+		err := channel.Send(context.Background(), to, &core.Message{Event: "test", Text: "hi"})
+		if err == nil || !strings.Contains(err.Error(), "is not public") {
+			t.Errorf("Send to %s error = %v, want not public", url, err)
+		}
+	}
+}
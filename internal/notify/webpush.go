@@ -0,0 +1,312 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// pushTTL is how long a push service keeps a message for an offline browser
+const pushTTL = 12 * time.Hour
+
+// maxPushBody keeps the encrypted payload under the 4 KB push services accept
+const maxPushBody = 2000
+
+// errSubscriptionGone means the push service dropped the subscription,
+// e.g. because the user revoked the permission
+var errSubscriptionGone = errors.New("push subscription expired")
+
+// WebPushConfig holds the VAPID keys the server identifies itself with
+type WebPushConfig struct {
+	PrivateKey string // VAPID private key, base64url; see GenerateVAPIDKeys
+	Subject    string // Contact for push services: "mailto:..." or an https URL
+	// Expired is called with the endpoint of every subscription the push
+	// service reports as gone
+	Expired func(endpoint string)
+	// AllowPrivate lets push endpoints reach loopback and private network
+	// addresses. Endpoints are chosen by the browser, so by default only
+	// public addresses are accepted, as for webhooks.
+	AllowPrivate bool
+}
+
+// WebPushChannel sends reminders to subscribed browsers (RFC 8030), with
+// encrypted payloads (RFC 8291) and VAPID authentication (RFC 8292)
+type WebPushChannel struct {
+	cfg       WebPushConfig
+	key       *ecdsa.PrivateKey
+	publicKey []byte
+	client    *http.Client
+}
+
+// NewWebPushChannel parses the VAPID key and returns the channel
+func NewWebPushChannel(cfg WebPushConfig) (*WebPushChannel, error) {
+	raw, err := decodeBase64URL(cfg.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	ecdhKey, err := key.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	if cfg.Subject == "" {
+		return nil, fmt.Errorf("VAPID subject is required")
+	}
+	return &WebPushChannel{
+		cfg:       cfg,
+		key:       key,
+		publicKey: ecdhKey.PublicKey().Bytes(),
+		client:    newOutboundClient(cfg.AllowPrivate),
+	}, nil
+}
+
+// GenerateVAPIDKeys creates a new key pair, base64url encoded
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// PublicKey returns the VAPID public key browsers subscribe with
+func (c *WebPushChannel) PublicKey() string {
+	return base64.RawURLEncoding.EncodeToString(c.publicKey)
+}
+
+// Name implements core.NotificationChannel
+func (c *WebPushChannel) Name() string {
+	return core.ChannelWebPush
+}
+
+// pushPayload is what the service worker receives
+type pushPayload struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+	Tag   string `json:"tag,omitempty"`
+}
+
+// Send implements core.NotificationChannel. The message counts as delivered
+// when at least one of the user's browsers accepted it.
+func (c *WebPushChannel) Send(ctx context.Context, to *core.Recipient, msg *core.Message) error {
+	if len(to.PushSubscriptions) == 0 {
+		return core.ErrNoRecipient
+	}
+
+	payload := pushPayload{
+		Title: msg.Subject,
+		Body:  msg.Text,
+		URL:   msg.Path,
+	}
+	if len(payload.Body) > maxPushBody {
+		body := []rune(payload.Body[:maxPushBody])
+		// The cut may split the last character
+		payload.Body = string(body[:len(body)-1]) + "…"
+	}
+	if msg.TaskID != 0 {
+		// Later reminders of the same task replace the earlier one
+		payload.Tag = fmt.Sprintf("task-%d", msg.TaskID)
+	}
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	delivered := false
+	var errs []error
+	for _, sub := range to.PushSubscriptions {
+		err := c.push(ctx, sub, plaintext)
+		switch {
+		case err == nil:
+			delivered = true
+		case errors.Is(err, errSubscriptionGone):
+			if c.cfg.Expired != nil {
+				c.cfg.Expired(sub.Endpoint)
+			}
+		default:
+			errs = append(errs, err)
+		}
+	}
+
+	switch {
+	case delivered:
+		return nil
+	case len(errs) == 0:
+		return fmt.Errorf("%w: all push subscriptions expired", core.ErrRecipientBlocked)
+	}
+	return errors.Join(errs...)
+}
+
+// push encrypts the payload for one browser and posts it to its push service
+func (c *WebPushChannel) push(ctx context.Context, sub *core.PushSubscription, plaintext []byte) error {
+	uaPublic, err := decodeBase64URL(sub.P256dh)
+	if err != nil {
+		return fmt.Errorf("invalid subscription key: %w", err)
+	}
+	authSecret, err := decodeBase64URL(sub.Auth)
+	if err != nil {
+		return fmt.Errorf("invalid subscription secret: %w", err)
+	}
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	body, err := encryptPushPayload(uaPublic, authSecret, plaintext, asPrivate, salt)
+	if err != nil {
+		return err
+	}
+
+	token, err := c.vapidToken(sub.Endpoint, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid push endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", fmt.Sprint(int(pushTTL.Seconds())))
+	req.Header.Set("Urgency", "high")
+	req.Header.Set("Authorization", "vapid t="+token+", k="+c.PublicKey())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return errSubscriptionGone
+	}
+	return fmt.Errorf("push service returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+}
+
+// vapidToken signs the JWT that identifies the server to the push service
+// of the endpoint
+func (c *WebPushChannel) vapidToken(endpoint string, now time.Time) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid push endpoint: %w", err)
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": parsed.Scheme + "://" + parsed.Host,
+		"exp": now.Add(pushTTL).Unix(),
+		"sub": c.cfg.Subject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	r, s, err := ecdsa.Sign(rand.Reader, c.key, digest[:])
+	if err != nil {
+		return "", err
+	}
+	// ES256 signatures are r and s as two fixed-size big-endian numbers
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// encryptPushPayload encrypts a payload for a browser as a single aes128gcm
+// record (RFC 8291), using the ephemeral key asPrivate and a random salt
+func encryptPushPayload(uaPublic, authSecret, plaintext []byte, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	uaKey, err := ecdh.P256().NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+	sharedSecret, err := asPrivate.ECDH(uaKey)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	// Mix the browser's auth secret into the shared secret
+	keyInfo := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	prkKey, err := hkdf.Extract(sha256.New, sharedSecret, authSecret)
+	if err != nil {
+		return nil, err
+	}
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive the content encryption key and nonce (RFC 8188)
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt, record size, key id length and the ephemeral public key
+	var out bytes.Buffer
+	out.Write(salt)
+	binary.Write(&out, binary.BigEndian, uint32(4096))
+	out.WriteByte(byte(len(asPublic)))
+	out.Write(asPublic)
+
+	// A single record ends with the 0x02 padding delimiter
+	record := append(append([]byte{}, plaintext...), 0x02)
+	out.Write(gcm.Seal(nil, nonce, record, nil))
+	return out.Bytes(), nil
+}
+
+// decodeBase64URL accepts base64url with or without padding, as browsers
+// and key generators disagree on it
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
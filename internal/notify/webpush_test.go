@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+func TestWebPushRefusesPrivateEndpoints(t *testing.T) {
+	_, privateKey, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatalf("GenerateVAPIDKeys: %v", err)
+	}
+	channel, err := NewWebPushChannel(WebPushConfig{PrivateKey: privateKey, Subject: "mailto:admin@example.com"})
+	if err != nil {
+		t.Fatalf("NewWebPushChannel: %v", err)
+	}
+
+	browserKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	rand.Read(auth)
+
+	for _, endpoint := range []string{"https://127.0.0.1:8443/push", "https://localhost/push", "https://10.0.0.5/push"} {
+		to := &core.Recipient{
+			User: &core.User{ID: 1, Username: "alice"},
+			PushSubscriptions: []*core.PushSubscription{{
+				Endpoint: endpoint,
+				P256dh:   base64.RawURLEncoding.EncodeToString(browserKey.PublicKey().Bytes()),
+				Auth:     base64.RawURLEncoding.EncodeToString(auth),
+			}},
+		}
+		err := channel.Send(context.Background(), to, &core.Message{Subject: "Reminder", Text: "hi"})
+		if err == nil || !strings.Contains(err.Error(), "is not public") {
+			t.Errorf("Send to %s error = %v, want not public", endpoint, err)
+		}
+	}
+}
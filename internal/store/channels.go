@@ -0,0 +1,98 @@
+package store
+
+import (
+	"fmt"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// GetChannelPreferences retrieves the channel preferences a user saved
+func (s *Store) GetChannelPreferences(userID int64) ([]*core.ChannelPreference, error) {
+	rows, err := s.DB.Query(
+		"SELECT user_id, channel, enabled, address, priority FROM notification_channels WHERE user_id = ? ORDER BY priority, channel",
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel preferences: %w", err)
+	}
+	defer rows.Close()
+
+	var prefs []*core.ChannelPreference
+	for rows.Next() {
+		pref := &core.ChannelPreference{}
+		if err := rows.Scan(&pref.UserID, &pref.Channel, &pref.Enabled, &pref.Address, &pref.Priority); err != nil {
+			return nil, fmt.Errorf("failed to scan channel preference: %w", err)
+		}
+		prefs = append(prefs, pref)
+	}
+
+	return prefs, rows.Err()
+}
+
+// SaveChannelPreference creates or replaces a user's preference for one channel
+func (s *Store) SaveChannelPreference(pref *core.ChannelPreference) error {
+	_, err := s.DB.Exec(`
+		INSERT INTO notification_channels (user_id, channel, enabled, address, priority, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(user_id, channel) DO UPDATE SET
+			enabled = excluded.enabled,
+			address = excluded.address,
+			priority = excluded.priority,
+			updated_at = CURRENT_TIMESTAMP`,
+		pref.UserID, pref.Channel, pref.Enabled, pref.Address, pref.Priority,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save channel preference: %w", err)
+	}
+	return nil
+}
+
+// GetPushSubscriptions retrieves the browsers a user subscribed to Web Push
+func (s *Store) GetPushSubscriptions(userID int64) ([]*core.PushSubscription, error) {
+	rows, err := s.DB.Query(
+		"SELECT id, user_id, endpoint, p256dh, auth, created_at FROM push_subscriptions WHERE user_id = ? ORDER BY id",
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get push subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*core.PushSubscription
+	for rows.Next() {
+		sub := &core.PushSubscription{}
+		if err := rows.Scan(&sub.ID, &sub.UserID, &sub.Endpoint, &sub.P256dh, &sub.Auth, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan push subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// SavePushSubscription stores a push subscription. A browser that subscribes
+// again, possibly for another user, replaces its old subscription.
+func (s *Store) SavePushSubscription(sub *core.PushSubscription) error {
+	_, err := s.DB.Exec(`
+		INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(endpoint) DO UPDATE SET
+			user_id = excluded.user_id,
+			p256dh = excluded.p256dh,
+			auth = excluded.auth`,
+		sub.UserID, sub.Endpoint, sub.P256dh, sub.Auth,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save push subscription: %w", err)
+	}
+	return nil
+}
+
+// DeletePushSubscription removes the subscription of a browser
+func (s *Store) DeletePushSubscription(endpoint string) error {
+	_, err := s.DB.Exec("DELETE FROM push_subscriptions WHERE endpoint = ?", endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	return nil
+}
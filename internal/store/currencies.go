@@ -0,0 +1,295 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// scanCurrency scans a currency row and decodes its localized labels
+func scanCurrency(row rowScanner) (*core.Currency, error) {
+	c := &core.Currency{}
+	var labels string
+
+	if err := row.Scan(&c.ID, &c.GroupID, &c.Name, &c.Emoji, &labels, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	c.Labels = make(map[string]string)
+	if labels != "" {
+		if err := json.Unmarshal([]byte(labels), &c.Labels); err != nil {
+			return nil, fmt.Errorf("failed to decode currency labels: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// encodeLabels encodes localized labels for storage
+func encodeLabels(labels map[string]string) (string, error) {
+	if len(labels) == 0 {
+		return "{}", nil
+	}
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode currency labels: %w", err)
+	}
+	return string(data), nil
+}
+
+// CreateCurrency creates a new group-defined currency
+func (s *Store) CreateCurrency(groupID int64, name, emoji string, labels map[string]string) (*core.Currency, error) {
+	id, err := insertCurrency(s.DB, groupID, name, emoji, labels)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCurrencyByID(id)
+}
+
+// insertCurrency inserts a currency and returns its ID
+func insertCurrency(q execer, groupID int64, name, emoji string, labels map[string]string) (int64, error) {
+	encoded, err := encodeLabels(labels)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := q.Exec(
+		"INSERT INTO currencies (group_id, name, emoji, labels) VALUES (?, ?, ?, ?)",
+		groupID, name, emoji, encoded,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create currency: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	return id, nil
+}
+
+// GetCurrencyByID retrieves a currency by ID
+func (s *Store) GetCurrencyByID(id int64) (*core.Currency, error) {
+	c, err := scanCurrency(s.DB.QueryRow(
+		"SELECT id, group_id, name, emoji, COALESCE(labels, ''), created_at FROM currencies WHERE id = ?",
+		id,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("currency not found")
+		}
+		return nil, fmt.Errorf("failed to get currency: %w", err)
+	}
+
+	return c, nil
+}
+
+// GetCurrenciesByGroupID retrieves all currencies defined by a group
+func (s *Store) GetCurrenciesByGroupID(groupID int64) ([]*core.Currency, error) {
+	rows, err := s.DB.Query(
+		"SELECT id, group_id, name, emoji, COALESCE(labels, ''), created_at FROM currencies WHERE group_id = ? ORDER BY id ASC",
+		groupID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query currencies: %w", err)
+	}
+	defer rows.Close()
+
+	var currencies []*core.Currency
+	for rows.Next() {
+		c, err := scanCurrency(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan currency: %w", err)
+		}
+		currencies = append(currencies, c)
+	}
+
+	return currencies, nil
+}
+
+// UpdateCurrency updates a currency's name, emoji and labels
+func (s *Store) UpdateCurrency(id int64, name, emoji string, labels map[string]string) error {
+	return updateCurrency(s.DB, id, name, emoji, labels)
+}
+
+// updateCurrency updates a currency through db or a transaction
+func updateCurrency(q execer, id int64, name, emoji string, labels map[string]string) error {
+	encoded, err := encodeLabels(labels)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(
+		"UPDATE currencies SET name = ?, emoji = ?, labels = ? WHERE id = ?",
+		name, emoji, encoded, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update currency: %w", err)
+	}
+
+	return nil
+}
+
+// getCurrencyAmounts reads (currency_id, amount) pairs for one owner row
+func (s *Store) getCurrencyAmounts(query string, ownerID int64) ([]core.CurrencyAmount, error) {
+	rows, err := s.DB.Query(query, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query currency amounts: %w", err)
+	}
+	defer rows.Close()
+
+	var amounts []core.CurrencyAmount
+	for rows.Next() {
+		var a core.CurrencyAmount
+		if err := rows.Scan(&a.CurrencyID, &a.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan currency amount: %w", err)
+		}
+		amounts = append(amounts, a)
+	}
+
+	return amounts, nil
+}
+
+// replaceCurrencyAmounts replaces all (currency_id, amount) pairs for one owner row
+func (s *Store) replaceCurrencyAmounts(deleteQuery, insertQuery string, ownerID int64, amounts []core.CurrencyAmount) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := writeCurrencyAmounts(tx, deleteQuery, insertQuery, ownerID, amounts); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// writeCurrencyAmounts replaces the pairs inside a transaction. An amount
+// whose Currency is set is stored under that currency's ID, which lets an
+// import refer to currencies it created in the same transaction.
+func writeCurrencyAmounts(tx execer, deleteQuery, insertQuery string, ownerID int64, amounts []core.CurrencyAmount) error {
+	if _, err := tx.Exec(deleteQuery, ownerID); err != nil {
+		return fmt.Errorf("failed to clear currency amounts: %w", err)
+	}
+
+	for _, a := range amounts {
+		if a.Amount <= 0 {
+			continue
+		}
+		currencyID := a.CurrencyID
+		if a.Currency != nil {
+			currencyID = a.Currency.ID
+		}
+		if _, err := tx.Exec(insertQuery, ownerID, currencyID, a.Amount); err != nil {
+			return fmt.Errorf("failed to store currency amount: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetTaskRewards retrieves a task's rewards in group-defined currencies
+func (s *Store) GetTaskRewards(taskID int64) ([]core.CurrencyAmount, error) {
+	return s.getCurrencyAmounts("SELECT currency_id, amount FROM task_rewards WHERE task_id = ? ORDER BY currency_id", taskID)
+}
+
+// SetTaskRewards replaces a task's rewards in group-defined currencies
+func (s *Store) SetTaskRewards(taskID int64, rewards []core.CurrencyAmount) error {
+	return s.replaceCurrencyAmounts(taskRewardsDelete, taskRewardsInsert, taskID, rewards)
+}
+
+// Queries replacing a task's rewards and a shop item's prices
+const (
+	taskRewardsDelete    = "DELETE FROM task_rewards WHERE task_id = ?"
+	taskRewardsInsert    = "INSERT INTO task_rewards (task_id, currency_id, amount) VALUES (?, ?, ?)"
+	shopItemPricesDelete = "DELETE FROM shop_item_prices WHERE shop_item_id = ?"
+	shopItemPricesInsert = "INSERT INTO shop_item_prices (shop_item_id, currency_id, amount) VALUES (?, ?, ?)"
+)
+
+// GetShopItemPrices retrieves a shop item's prices in group-defined currencies
+func (s *Store) GetShopItemPrices(itemID int64) ([]core.CurrencyAmount, error) {
+	return s.getCurrencyAmounts("SELECT currency_id, amount FROM shop_item_prices WHERE shop_item_id = ? ORDER BY currency_id", itemID)
+}
+
+// SetShopItemPrices replaces a shop item's prices in group-defined currencies
+func (s *Store) SetShopItemPrices(itemID int64, prices []core.CurrencyAmount) error {
+	return s.replaceCurrencyAmounts(shopItemPricesDelete, shopItemPricesInsert, itemID, prices)
+}
+
+// CreateExchangeRule creates a new exchange rule between two currencies
+func (s *Store) CreateExchangeRule(groupID, fromCurrencyID int64, fromAmount int, toCurrencyID int64, toAmount int) (*core.ExchangeRule, error) {
+	id, err := insertExchangeRule(s.DB, groupID, fromCurrencyID, fromAmount, toCurrencyID, toAmount)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetExchangeRuleByID(id)
+}
+
+// insertExchangeRule inserts an exchange rule and returns its ID
+func insertExchangeRule(q execer, groupID, fromCurrencyID int64, fromAmount int, toCurrencyID int64, toAmount int) (int64, error) {
+	result, err := q.Exec(
+		"INSERT INTO currency_exchange_rules (group_id, from_currency_id, from_amount, to_currency_id, to_amount) VALUES (?, ?, ?, ?, ?)",
+		groupID, fromCurrencyID, fromAmount, toCurrencyID, toAmount,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create exchange rule: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	return id, nil
+}
+
+// GetExchangeRuleByID retrieves an exchange rule by ID
+func (s *Store) GetExchangeRuleByID(id int64) (*core.ExchangeRule, error) {
+	rule := &core.ExchangeRule{}
+
+	err := s.DB.QueryRow(
+		"SELECT id, group_id, from_currency_id, from_amount, to_currency_id, to_amount, created_at FROM currency_exchange_rules WHERE id = ?",
+		id,
+	).Scan(&rule.ID, &rule.GroupID, &rule.FromCurrencyID, &rule.FromAmount, &rule.ToCurrencyID, &rule.ToAmount, &rule.CreatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("exchange rule not found")
+		}
+		return nil, fmt.Errorf("failed to get exchange rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// GetExchangeRulesByGroupID retrieves all exchange rules of a group
+func (s *Store) GetExchangeRulesByGroupID(groupID int64) ([]*core.ExchangeRule, error) {
+	rows, err := s.DB.Query(
+		"SELECT id, group_id, from_currency_id, from_amount, to_currency_id, to_amount, created_at FROM currency_exchange_rules WHERE group_id = ? ORDER BY id ASC",
+		groupID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exchange rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*core.ExchangeRule
+	for rows.Next() {
+		rule := &core.ExchangeRule{}
+		if err := rows.Scan(&rule.ID, &rule.GroupID, &rule.FromCurrencyID, &rule.FromAmount, &rule.ToCurrencyID, &rule.ToAmount, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// DeleteExchangeRule deletes an exchange rule
+func (s *Store) DeleteExchangeRule(id int64) error {
+	_, err := s.DB.Exec("DELETE FROM currency_exchange_rules WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete exchange rule: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"fmt"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// ApplyDefinitionImport writes an import in one transaction: either the whole
+// definition lands in the group or nothing changes. New currencies are
+// inserted first and their IDs filled in, so the amounts and exchange rules
+// that refer to them can be stored.
+func (s *Store) ApplyDefinitionImport(plan *core.DefinitionImport) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if plan.Targets != nil {
+		if err := saveEconomyTargets(tx, plan.Targets); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range plan.NewCurrencies {
+		id, err := insertCurrency(tx, plan.GroupID, c.Name, c.Emoji, c.Labels)
+		if err != nil {
+			return err
+		}
+		c.ID, c.GroupID = id, plan.GroupID
+	}
+	for _, c := range plan.UpdatedCurrencies {
+		if err := updateCurrency(tx, c.ID, c.Name, c.Emoji, c.Labels); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range plan.DeletedExchangeRules {
+		if _, err := tx.Exec("DELETE FROM currency_exchange_rules WHERE id = ?", id); err != nil {
+			return fmt.Errorf("failed to delete exchange rule: %w", err)
+		}
+	}
+	for _, rule := range plan.NewExchangeRules {
+		rule.FromCurrencyID, rule.ToCurrencyID = rule.FromCurrency.ID, rule.ToCurrency.ID
+		id, err := insertExchangeRule(tx, plan.GroupID, rule.FromCurrencyID, rule.FromAmount, rule.ToCurrencyID, rule.ToAmount)
+		if err != nil {
+			return err
+		}
+		rule.ID = id
+	}
+
+	for _, task := range plan.NewTasks {
+		id, err := insertTask(tx, plan.GroupID, task.Title, task.Description, task.TaskType, task.RewardValue, task.DefaultQuantity, task.IsOneTime, nil)
+		if err != nil {
+			return err
+		}
+		task.ID = id
+	}
+	for _, task := range plan.UpdatedTasks {
+		if err := updateTask(tx, task.ID, task.Title, task.Description, task.TaskType, task.RewardValue, task.DefaultQuantity, task.IsOneTime, task.DueAt); err != nil {
+			return err
+		}
+	}
+	for _, tasks := range [][]*core.Task{plan.NewTasks, plan.UpdatedTasks} {
+		for _, task := range tasks {
+			if err := setTaskDeadlinePolicy(tx, task.ID, task.Deadline); err != nil {
+				return err
+			}
+			if err := writeCurrencyAmounts(tx, taskRewardsDelete, taskRewardsInsert, task.ID, task.ExtraRewards); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, item := range plan.NewShopItems {
+		id, err := insertShopItem(tx, plan.GroupID, item.Title, item.Description, item.Cost, item.IsOneTime)
+		if err != nil {
+			return err
+		}
+		item.ID = id
+	}
+	for _, item := range plan.UpdatedShopItems {
+		if err := updateShopItem(tx, item.ID, item.Title, item.Description, item.Cost, item.IsOneTime); err != nil {
+			return err
+		}
+	}
+	for _, items := range [][]*core.ShopItem{plan.NewShopItems, plan.UpdatedShopItems} {
+		for _, item := range items {
+			if err := writeCurrencyAmounts(tx, shopItemPricesDelete, shopItemPricesInsert, item.ID, item.ExtraPrices); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit import: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// GetEconomyTargets retrieves a group's economy targets, or the defaults
+func (s *Store) GetEconomyTargets(groupID int64) (*core.EconomyTargets, error) {
+	targets := &core.EconomyTargets{GroupID: groupID}
+	err := s.DB.QueryRow(
+		"SELECT work_days, spend_percent FROM economy_targets WHERE group_id = ?", groupID,
+	).Scan(&targets.WorkDays, &targets.SpendPercent)
+	if err == sql.ErrNoRows {
+		return core.DefaultEconomyTargets(groupID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get economy targets: %w", err)
+	}
+	return targets, nil
+}
+
+// SaveEconomyTargets creates or updates a group's economy targets
+func (s *Store) SaveEconomyTargets(targets *core.EconomyTargets) error {
+	return saveEconomyTargets(s.DB, targets)
+}
+
+// saveEconomyTargets writes economy targets through db or a transaction
+func saveEconomyTargets(q execer, targets *core.EconomyTargets) error {
+	_, err := q.Exec(`
+		INSERT INTO economy_targets (group_id, work_days, spend_percent, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(group_id) DO UPDATE SET
+			work_days = excluded.work_days,
+			spend_percent = excluded.spend_percent,
+			updated_at = CURRENT_TIMESTAMP`,
+		targets.GroupID, targets.WorkDays, targets.SpendPercent,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save economy targets: %w", err)
+	}
+	return nil
+}
+
+// GetEconomyFlows sums the cheese each member got and spent in [since, until),
+// keyed by user ID. Undone entries are netted out like in the report queries.
+func (s *Store) GetEconomyFlows(groupID int64, since, until time.Time) (map[int64]*core.EconomyMember, error) {
+	rows, err := s.DB.Query(`
+		SELECT user_id,
+			COALESCE(SUM(CASE WHEN source_type = ? THEN amount END), 0),
+			COALESCE(SUM(CASE WHEN source_type IN (?, ?, ?) THEN amount END), 0),
+			COALESCE(-SUM(CASE WHEN source_type = ? THEN amount END), 0),
+			COALESCE(-SUM(CASE WHEN source_type = ? THEN amount END), 0)
+		FROM transactions
+		WHERE group_id = ? AND currency_id = ? AND parent_transaction_id IS NULL AND created_at >= ? AND created_at < ?
+		GROUP BY user_id`,
+		string(core.SourceTypeTask),
+		string(core.SourceTypeGrant), string(core.SourceTypeInterest), string(core.SourceTypeManual),
+		string(core.SourceTypeShopItem), string(core.SourceTypePenalty),
+		groupID, core.DefaultCurrencyID, timestampArg(since), timestampArg(until),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query economy flows: %w", err)
+	}
+	defer rows.Close()
+
+	members := make(map[int64]*core.EconomyMember)
+	for rows.Next() {
+		m := &core.EconomyMember{}
+		if err := rows.Scan(&m.UserID, &m.Earned, &m.Income, &m.Spent, &m.Penalties); err != nil {
+			return nil, fmt.Errorf("failed to scan economy flows: %w", err)
+		}
+		members[m.UserID] = m
+	}
+	return members, rows.Err()
+}
+
+// GetPurchaseCounts counts the purchases of each shop item in [since, until)
+// that were not undone, keyed by item ID
+func (s *Store) GetPurchaseCounts(groupID int64, since, until time.Time) (map[int64]int, error) {
+	rows, err := s.DB.Query(`
+		SELECT shop_item_id, COUNT(*) FROM purchases
+		WHERE group_id = ? AND cancelled_at IS NULL AND created_at >= ? AND created_at < ?
+		GROUP BY shop_item_id`,
+		groupID, timestampArg(since), timestampArg(until),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count purchases: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var itemID int64
+		var count int
+		if err := rows.Scan(&itemID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase counts: %w", err)
+		}
+		counts[itemID] = count
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// StreamTransactions calls fn for each transaction of a group, oldest first,
+// reading them one row at a time. userID 0 streams every member's.
+func (s *Store) StreamTransactions(groupID, userID int64, fn func(*core.Transaction) error) error {
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE group_id = ?"
+	args := []interface{}{groupID}
+	if userID != 0 {
+		query += " AND user_id = ?"
+		args = append(args, userID)
+	}
+
+	rows, err := s.DB.Query(query+" ORDER BY created_at, id", args...)
+	if err != nil {
+		return fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		tx, err := scanTransaction(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// StreamPurchases calls fn for each purchase of a group, oldest first. The
+// shop item carries the title and cost stored on the purchase's transaction,
+// so purchases of deleted or repriced items keep what was paid. userID 0
+// streams every member's.
+func (s *Store) StreamPurchases(groupID, userID int64, fn func(*core.PurchaseHistory) error) error {
+	query := `
+		SELECT
+			p.id, p.transaction_id, p.user_id, p.group_id, p.shop_item_id,
+			p.fulfilled, p.fulfilled_at, p.fulfilled_by, COALESCE(p.notes, ''), p.cancelled_at, p.created_at,
+			COALESCE(NULLIF(t.description, ''), si.title, '[Deleted Item]'), -t.amount
+		FROM purchases p
+		JOIN transactions t ON p.transaction_id = t.id
+		LEFT JOIN shop_items si ON p.shop_item_id = si.id
+		WHERE p.group_id = ?`
+	args := []interface{}{groupID}
+	if userID != 0 {
+		query += " AND p.user_id = ?"
+		args = append(args, userID)
+	}
+
+	rows, err := s.DB.Query(query+" ORDER BY p.created_at, p.id", args...)
+	if err != nil {
+		return fmt.Errorf("failed to query purchases: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		ph := &core.PurchaseHistory{Purchase: &core.Purchase{}, ShopItem: &core.ShopItem{}}
+		var fulfilledAt, cancelledAt sql.NullTime
+		var fulfilledBy sql.NullInt64
+		if err := rows.Scan(
+			&ph.Purchase.ID, &ph.Purchase.TransactionID, &ph.Purchase.UserID,
+			&ph.Purchase.GroupID, &ph.Purchase.ShopItemID, &ph.Purchase.Fulfilled,
+			&fulfilledAt, &fulfilledBy, &ph.Purchase.Notes, &cancelledAt, &ph.Purchase.CreatedAt,
+			&ph.ShopItem.Title, &ph.ShopItem.Cost,
+		); err != nil {
+			return fmt.Errorf("failed to scan purchase: %w", err)
+		}
+		ph.ShopItem.ID = ph.Purchase.ShopItemID
+		ph.ShopItem.GroupID = ph.Purchase.GroupID
+		if fulfilledAt.Valid {
+			ph.Purchase.FulfilledAt = &fulfilledAt.Time
+		}
+		if fulfilledBy.Valid {
+			ph.Purchase.FulfilledBy = &fulfilledBy.Int64
+		}
+		if cancelledAt.Valid {
+			ph.Purchase.CancelledAt = &cancelledAt.Time
+		}
+		if err := fn(ph); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// StreamTasks calls fn for each task of a group, oldest first
+func (s *Store) StreamTasks(groupID int64, fn func(*core.Task) error) error {
+	rows, err := s.DB.Query("SELECT "+taskColumns+" FROM tasks WHERE group_id = ? ORDER BY created_at, id", groupID)
+	if err != nil {
+		return fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan task: %w", err)
+		}
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
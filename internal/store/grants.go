@@ -0,0 +1,193 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// grantColumns is the column list used by scanScheduledGrant
+const grantColumns = "id, group_id, created_by, kind, currency_id, amount, rate_percent, schedule, description, member_ids, paused, last_run_at, created_at"
+
+// scanScheduledGrant scans a row selected with grantColumns
+func scanScheduledGrant(row rowScanner) (*core.ScheduledGrant, error) {
+	g := &core.ScheduledGrant{}
+	var kind, memberIDs string
+	var lastRunAt sql.NullTime
+
+	if err := row.Scan(&g.ID, &g.GroupID, &g.CreatedBy, &kind, &g.CurrencyID, &g.Amount, &g.RatePercent,
+		&g.Schedule, &g.Description, &memberIDs, &g.Paused, &lastRunAt, &g.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	g.Kind = core.GrantKind(kind)
+	if lastRunAt.Valid {
+		g.LastRunAt = &lastRunAt.Time
+	}
+	if memberIDs != "" {
+		if err := json.Unmarshal([]byte(memberIDs), &g.MemberIDs); err != nil {
+			return nil, fmt.Errorf("failed to decode grant members: %w", err)
+		}
+	}
+
+	return g, nil
+}
+
+// CreateScheduledGrant stores a new scheduled grant
+func (s *Store) CreateScheduledGrant(g *core.ScheduledGrant) (*core.ScheduledGrant, error) {
+	memberIDs, err := json.Marshal(g.MemberIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode grant members: %w", err)
+	}
+	if g.MemberIDs == nil {
+		memberIDs = []byte("[]")
+	}
+
+	result, err := s.DB.Exec(
+		`INSERT INTO scheduled_grants (group_id, created_by, kind, currency_id, amount, rate_percent, schedule, description, member_ids)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		g.GroupID, g.CreatedBy, string(g.Kind), g.CurrencyID, g.Amount, g.RatePercent, g.Schedule, g.Description, string(memberIDs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduled grant: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return s.GetScheduledGrantByID(id)
+}
+
+// GetScheduledGrantByID retrieves a scheduled grant by ID
+func (s *Store) GetScheduledGrantByID(id int64) (*core.ScheduledGrant, error) {
+	g, err := scanScheduledGrant(s.DB.QueryRow("SELECT "+grantColumns+" FROM scheduled_grants WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("scheduled grant not found")
+		}
+		return nil, fmt.Errorf("failed to get scheduled grant: %w", err)
+	}
+
+	return g, nil
+}
+
+// GetScheduledGrantsByGroupID retrieves all scheduled grants of a group
+func (s *Store) GetScheduledGrantsByGroupID(groupID int64) ([]*core.ScheduledGrant, error) {
+	return s.queryScheduledGrants("SELECT "+grantColumns+" FROM scheduled_grants WHERE group_id = ? ORDER BY id ASC", groupID)
+}
+
+// GetActiveScheduledGrants retrieves every grant that is not paused
+func (s *Store) GetActiveScheduledGrants() ([]*core.ScheduledGrant, error) {
+	return s.queryScheduledGrants("SELECT " + grantColumns + " FROM scheduled_grants WHERE paused = 0 ORDER BY id ASC")
+}
+
+// queryScheduledGrants runs a query selecting grantColumns and scans every row
+func (s *Store) queryScheduledGrants(query string, args ...interface{}) ([]*core.ScheduledGrant, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled grants: %w", err)
+	}
+	defer rows.Close()
+
+	var grants []*core.ScheduledGrant
+	for rows.Next() {
+		g, err := scanScheduledGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled grant: %w", err)
+		}
+		grants = append(grants, g)
+	}
+
+	return grants, nil
+}
+
+// SetScheduledGrantPaused pauses or resumes a grant. Resuming moves the last
+// run forward so slots missed while paused are not paid out.
+func (s *Store) SetScheduledGrantPaused(id int64, paused bool, lastRunAt time.Time) error {
+	_, err := s.DB.Exec(
+		"UPDATE scheduled_grants SET paused = ?, last_run_at = ? WHERE id = ?",
+		paused, lastRunAt.UTC(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled grant: %w", err)
+	}
+	return nil
+}
+
+// SetScheduledGrantLastRun records the latest executed schedule slot
+func (s *Store) SetScheduledGrantLastRun(id int64, runAt time.Time) error {
+	_, err := s.DB.Exec("UPDATE scheduled_grants SET last_run_at = ? WHERE id = ?", runAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled grant: %w", err)
+	}
+	return nil
+}
+
+// DeleteScheduledGrant deletes a grant; paid transactions stay in the ledger
+func (s *Store) DeleteScheduledGrant(id int64) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM grant_runs WHERE grant_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete grant runs: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM scheduled_grants WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete scheduled grant: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// PayScheduledGrant pays one member for one schedule slot. The run is recorded
+// in the same database transaction as the payment, so a slot is paid at most
+// once even if the server restarts mid-run. It reports whether a payment was made.
+func (s *Store) PayScheduledGrant(g *core.ScheduledGrant, userID int64, runAt time.Time, amount int, sourceType core.SourceType) (bool, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"INSERT OR IGNORE INTO grant_runs (grant_id, user_id, run_at) VALUES (?, ?, ?)",
+		g.ID, userID, runAt.UTC(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record grant run: %w", err)
+	}
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+		return false, err
+	}
+
+	result, err = tx.Exec(
+		"INSERT INTO transactions (user_id, group_id, amount, currency_id, source_type, source_id, quantity, description, notes) VALUES (?, ?, ?, ?, ?, ?, 1, ?, '')",
+		userID, g.GroupID, amount, g.CurrencyID, string(sourceType), g.ID, g.Description,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	transactionID, err := result.LastInsertId()
+	if err != nil {
+		return false, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	if _, err := tx.Exec(
+		"UPDATE grant_runs SET transaction_id = ? WHERE grant_id = ? AND user_id = ? AND run_at = ?",
+		transactionID, g.ID, userID, runAt.UTC(),
+	); err != nil {
+		return false, fmt.Errorf("failed to record grant run: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit grant payment: %w", err)
+	}
+	return true, nil
+}
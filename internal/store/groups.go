@@ -2,10 +2,46 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"small-rpg-adhd-monolith/internal/core"
+	"time"
 )
 
+// groupColumns lists the columns read for every group query
+const groupColumns = "g.id, g.name, g.invite_code, g.owner_id, g.archived_at, g.delete_after, g.retain_ledger, g.created_at"
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx, so writes can be shared
+// between single statements and transactions
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// scanGroup scans a single group row selected with groupColumns
+func scanGroup(row rowScanner) (*core.Group, error) {
+	group := &core.Group{}
+	var archivedAt sql.NullTime
+	var deleteAfter sql.NullTime
+
+	if err := row.Scan(&group.ID, &group.Name, &group.InviteCode, &group.OwnerID, &archivedAt, &deleteAfter, &group.RetainLedger, &group.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if archivedAt.Valid {
+		group.ArchivedAt = &archivedAt.Time
+	}
+	if deleteAfter.Valid {
+		group.DeleteAfter = &deleteAfter.Time
+	}
+
+	return group, nil
+}
+
 // CreateGroup creates a new group with an invite code
 func (s *Store) CreateGroup(name, inviteCode string, ownerID int64) (*core.Group, error) {
 	result, err := s.DB.Exec(
@@ -26,12 +62,10 @@ func (s *Store) CreateGroup(name, inviteCode string, ownerID int64) (*core.Group
 
 // GetGroupByID retrieves a group by ID
 func (s *Store) GetGroupByID(id int64) (*core.Group, error) {
-	group := &core.Group{}
-
-	err := s.DB.QueryRow(
-		"SELECT id, name, invite_code, owner_id, created_at FROM groups WHERE id = ?",
+	group, err := scanGroup(s.DB.QueryRow(
+		"SELECT "+groupColumns+" FROM groups g WHERE g.id = ?",
 		id,
-	).Scan(&group.ID, &group.Name, &group.InviteCode, &group.OwnerID, &group.CreatedAt)
+	))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -45,12 +79,10 @@ func (s *Store) GetGroupByID(id int64) (*core.Group, error) {
 
 // GetGroupByInviteCode retrieves a group by invite code
 func (s *Store) GetGroupByInviteCode(inviteCode string) (*core.Group, error) {
-	group := &core.Group{}
-
-	err := s.DB.QueryRow(
-		"SELECT id, name, invite_code, owner_id, created_at FROM groups WHERE invite_code = ?",
+	group, err := scanGroup(s.DB.QueryRow(
+		"SELECT "+groupColumns+" FROM groups g WHERE g.invite_code = ?",
 		inviteCode,
-	).Scan(&group.ID, &group.Name, &group.InviteCode, &group.OwnerID, &group.CreatedAt)
+	))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -62,14 +94,39 @@ func (s *Store) GetGroupByInviteCode(inviteCode string) (*core.Group, error) {
 	return group, nil
 }
 
-// GetGroupsByUserID retrieves all groups a user is a member of
+// GetGroupsByUserID retrieves all active groups a user is a member of
+// Archived groups and groups scheduled for deletion are excluded
 func (s *Store) GetGroupsByUserID(userID int64) ([]*core.Group, error) {
-	rows, err := s.DB.Query(`
-		SELECT g.id, g.name, g.invite_code, g.owner_id, g.created_at
+	return s.queryGroups(`
+		SELECT `+groupColumns+`
 		FROM groups g
 		INNER JOIN group_members gm ON g.id = gm.group_id
-		WHERE gm.user_id = ?
+		WHERE gm.user_id = ? AND g.archived_at IS NULL AND g.delete_after IS NULL
 	`, userID)
+}
+
+// GetArchivedGroupsByUserID retrieves archived or deletion-pending groups a user is a member of
+func (s *Store) GetArchivedGroupsByUserID(userID int64) ([]*core.Group, error) {
+	return s.queryGroups(`
+		SELECT `+groupColumns+`
+		FROM groups g
+		INNER JOIN group_members gm ON g.id = gm.group_id
+		WHERE gm.user_id = ? AND (g.archived_at IS NOT NULL OR g.delete_after IS NOT NULL)
+		ORDER BY g.archived_at DESC
+	`, userID)
+}
+
+// GetGroupsPendingDeletion retrieves groups whose deletion grace period has passed
+func (s *Store) GetGroupsPendingDeletion(now time.Time) ([]*core.Group, error) {
+	return s.queryGroups(
+		"SELECT "+groupColumns+" FROM groups g WHERE g.delete_after IS NOT NULL AND g.delete_after <= ?",
+		now.UTC(),
+	)
+}
+
+// queryGroups runs a query selecting groupColumns and scans all rows
+func (s *Store) queryGroups(query string, args ...interface{}) ([]*core.Group, error) {
+	rows, err := s.DB.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query groups: %w", err)
 	}
@@ -77,8 +134,8 @@ func (s *Store) GetGroupsByUserID(userID int64) ([]*core.Group, error) {
 
 	var groups []*core.Group
 	for rows.Next() {
-		group := &core.Group{}
-		if err := rows.Scan(&group.ID, &group.Name, &group.InviteCode, &group.OwnerID, &group.CreatedAt); err != nil {
+		group, err := scanGroup(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan group: %w", err)
 		}
 		groups = append(groups, group)
@@ -87,6 +144,173 @@ func (s *Store) GetGroupsByUserID(userID int64) ([]*core.Group, error) {
 	return groups, nil
 }
 
+// SetGroupArchived archives or unarchives a group
+func (s *Store) SetGroupArchived(groupID int64, archived bool) error {
+	var archivedAt interface{}
+	if archived {
+		archivedAt = time.Now().UTC()
+	}
+
+	_, err := s.DB.Exec(`UPDATE groups SET archived_at = ? WHERE id = ?`, archivedAt, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to update group archive state: %w", err)
+	}
+	return nil
+}
+
+// ScheduleGroupDeletion marks a group for deletion after the given time
+func (s *Store) ScheduleGroupDeletion(groupID int64, deleteAfter time.Time, retainLedger bool) error {
+	_, err := s.DB.Exec(
+		`UPDATE groups SET delete_after = ?, retain_ledger = ?, archived_at = COALESCE(archived_at, ?) WHERE id = ?`,
+		deleteAfter.UTC(), retainLedger, time.Now().UTC(), groupID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to schedule group deletion: %w", err)
+	}
+	return nil
+}
+
+// CancelGroupDeletion clears a pending deletion; the group stays archived
+func (s *Store) CancelGroupDeletion(groupID int64) error {
+	_, err := s.DB.Exec(`UPDATE groups SET delete_after = NULL, retain_ledger = 0 WHERE id = ?`, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel group deletion: %w", err)
+	}
+	return nil
+}
+
+// ledgerExport is the JSON layout of a retained group ledger
+type ledgerExport struct {
+	Group        *core.Group         `json:"group"`
+	Currencies   []*core.Currency    `json:"currencies"`
+	Transactions []*core.Transaction `json:"transactions"`
+	Purchases    []*core.Purchase    `json:"purchases"`
+}
+
+// DeleteGroupData permanently removes a group and everything that belongs to it.
+// When the group asked to retain its ledger, transactions and purchases are
+// exported to group_ledger_exports before the rows are removed.
+func (s *Store) DeleteGroupData(groupID int64) error {
+	group, err := s.GetGroupByID(groupID)
+	if err != nil {
+		return err
+	}
+
+	var export []byte
+	if group.RetainLedger {
+		transactions, err := s.GetTransactionsByGroupID(groupID)
+		if err != nil {
+			return err
+		}
+		purchases, err := s.GetPurchasesByGroupID(groupID)
+		if err != nil {
+			return err
+		}
+		currencies, err := s.GetCurrenciesByGroupID(groupID)
+		if err != nil {
+			return err
+		}
+		export, err = json.Marshal(ledgerExport{Group: group, Currencies: currencies, Transactions: transactions, Purchases: purchases})
+		if err != nil {
+			return fmt.Errorf("failed to encode ledger export: %w", err)
+		}
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if export != nil {
+		if _, err := tx.Exec(
+			"INSERT INTO group_ledger_exports (group_id, group_name, owner_id, data) VALUES (?, ?, ?, ?)",
+			group.ID, group.Name, group.OwnerID, string(export),
+		); err != nil {
+			return fmt.Errorf("failed to store ledger export: %w", err)
+		}
+	}
+
+	// Order matters: dependent rows first, the group itself last
+	cleanup := []string{
+		`DELETE FROM task_notifications WHERE task_id IN (SELECT id FROM tasks WHERE group_id = ?)`,
+		`DELETE FROM purchases WHERE group_id = ?`,
+		`DELETE FROM wishlist_items WHERE group_id = ?`,
+		`DELETE FROM grant_runs WHERE grant_id IN (SELECT id FROM scheduled_grants WHERE group_id = ?)`,
+		`DELETE FROM scheduled_grants WHERE group_id = ?`,
+		`DELETE FROM pauses WHERE group_id = ?`,
+		`DELETE FROM season_standings WHERE season_id IN (SELECT id FROM seasons WHERE group_id = ?)`,
+		`DELETE FROM seasons WHERE group_id = ?`,
+		`DELETE FROM economy_targets WHERE group_id = ?`,
+		`DELETE FROM transactions WHERE group_id = ?`,
+		`DELETE FROM task_rewards WHERE task_id IN (SELECT id FROM tasks WHERE group_id = ?)`,
+		`DELETE FROM task_penalties WHERE task_id IN (SELECT id FROM tasks WHERE group_id = ?)`,
+		`DELETE FROM task_reminder_optouts WHERE task_id IN (SELECT id FROM tasks WHERE group_id = ?)`,
+		`DELETE FROM task_snoozes WHERE task_id IN (SELECT id FROM tasks WHERE group_id = ?)`,
+		`DELETE FROM missed_deadlines WHERE group_id = ?`,
+		`DELETE FROM shop_item_prices WHERE shop_item_id IN (SELECT id FROM shop_items WHERE group_id = ?)`,
+		`DELETE FROM currency_exchange_rules WHERE group_id = ?`,
+		`DELETE FROM currencies WHERE group_id = ?`,
+		`DELETE FROM tasks WHERE group_id = ?`,
+		`DELETE FROM shop_items WHERE group_id = ?`,
+		`DELETE FROM group_members WHERE group_id = ?`,
+		`DELETE FROM groups WHERE id = ?`,
+	}
+	for _, query := range cleanup {
+		if _, err := tx.Exec(query, groupID); err != nil {
+			return fmt.Errorf("failed to delete group data: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit group deletion: %w", err)
+	}
+
+	return nil
+}
+
+// GetLedgerExportsByOwner retrieves retained ledgers of deleted groups owned by a user
+func (s *Store) GetLedgerExportsByOwner(ownerID int64) ([]*core.GroupLedgerExport, error) {
+	rows, err := s.DB.Query(
+		"SELECT id, group_id, group_name, owner_id, '', created_at FROM group_ledger_exports WHERE owner_id = ? ORDER BY created_at DESC",
+		ownerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ledger exports: %w", err)
+	}
+	defer rows.Close()
+
+	var exports []*core.GroupLedgerExport
+	for rows.Next() {
+		export := &core.GroupLedgerExport{}
+		if err := rows.Scan(&export.ID, &export.GroupID, &export.GroupName, &export.OwnerID, &export.Data, &export.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger export: %w", err)
+		}
+		exports = append(exports, export)
+	}
+
+	return exports, nil
+}
+
+// GetLedgerExportByID retrieves a retained ledger including its data
+func (s *Store) GetLedgerExportByID(id int64) (*core.GroupLedgerExport, error) {
+	export := &core.GroupLedgerExport{}
+
+	err := s.DB.QueryRow(
+		"SELECT id, group_id, group_name, owner_id, data, created_at FROM group_ledger_exports WHERE id = ?",
+		id,
+	).Scan(&export.ID, &export.GroupID, &export.GroupName, &export.OwnerID, &export.Data, &export.CreatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("ledger export not found")
+		}
+		return nil, fmt.Errorf("failed to get ledger export: %w", err)
+	}
+
+	return export, nil
+}
+
 // AddUserToGroup adds a user to a group
 func (s *Store) AddUserToGroup(userID, groupID int64) error {
 	_, err := s.DB.Exec(
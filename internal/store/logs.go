@@ -0,0 +1,40 @@
+package store
+
+import (
+	"strings"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// logFilterClause renders the member, source, date range and cursor parts of
+// a log filter as SQL conditions on table alias a of table, whose source
+// column is sourceColumn. Paging compares (created_at, id) with the cursor
+// row so entries created in the same second are neither skipped nor repeated.
+func logFilterClause(filter core.LogFilter, table, a, sourceColumn string) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if filter.UserID != 0 {
+		conds = append(conds, a+".user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.SourceID != 0 {
+		conds = append(conds, a+"."+sourceColumn+" = ?")
+		args = append(args, filter.SourceID)
+	}
+	if !filter.Since.IsZero() {
+		conds = append(conds, a+".created_at >= ?")
+		args = append(args, timestampArg(filter.Since))
+	}
+	if !filter.Until.IsZero() {
+		conds = append(conds, a+".created_at < ?")
+		args = append(args, timestampArg(filter.Until))
+	}
+	if filter.Before != 0 {
+		conds = append(conds, "("+a+".created_at, "+a+".id) < (SELECT created_at, id FROM "+table+" WHERE id = ?)")
+		args = append(args, filter.Before)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " AND " + strings.Join(conds, " AND "), args
+}
@@ -7,15 +7,49 @@ import (
 	"time"
 )
 
+// notificationSettingsColumns is the column list used by scanNotificationSettings
+const notificationSettingsColumns = `user_id, reminder_delta_minutes, snooze_default_minutes, quiet_start, quiet_end, quiet_days,
+	urgent_during_quiet, morning_digest, evening_digest, report_period, created_at, updated_at`
+
+// scanNotificationSettings scans a row selected with notificationSettingsColumns
+func scanNotificationSettings(row rowScanner) (*core.NotificationSettings, error) {
+	settings := &core.NotificationSettings{}
+	var quietDays string
+	var morningDigest, eveningDigest sql.NullInt64
+
+	if err := row.Scan(&settings.UserID, &settings.ReminderDeltaMinutes, &settings.SnoozeDefaultMinutes,
+		&settings.QuietHours.Start, &settings.QuietHours.End, &quietDays, &settings.UrgentDuringQuiet,
+		&morningDigest, &eveningDigest, &settings.ReportPeriod, &settings.CreatedAt, &settings.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	settings.QuietDays, err = core.ParseQuietDays(quietDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse quiet hours of user %d: %w", settings.UserID, err)
+	}
+	settings.MorningDigest = nullableMinutes(morningDigest)
+	settings.EveningDigest = nullableMinutes(eveningDigest)
+
+	return settings, nil
+}
+
+// nullableMinutes converts a nullable digest time to *int
+func nullableMinutes(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	minutes := int(v.Int64)
+	return &minutes
+}
+
 // GetNotificationSettings retrieves notification settings for a user
 // Returns default settings if none exist for the user
 func (s *Store) GetNotificationSettings(userID int64) (*core.NotificationSettings, error) {
-	settings := &core.NotificationSettings{}
-
-	err := s.DB.QueryRow(
-		"SELECT user_id, reminder_delta_minutes, snooze_default_minutes, created_at, updated_at FROM notification_settings WHERE user_id = ?",
+	settings, err := scanNotificationSettings(s.DB.QueryRow(
+		"SELECT "+notificationSettingsColumns+" FROM notification_settings WHERE user_id = ?",
 		userID,
-	).Scan(&settings.UserID, &settings.ReminderDeltaMinutes, &settings.SnoozeDefaultMinutes, &settings.CreatedAt, &settings.UpdatedAt)
+	))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -34,19 +68,51 @@ func (s *Store) GetNotificationSettings(userID int64) (*core.NotificationSetting
 	return settings, nil
 }
 
+// GetDigestSettings retrieves the settings of every user who gets a digest
+// or a scheduled report
+func (s *Store) GetDigestSettings() ([]*core.NotificationSettings, error) {
+	rows, err := s.DB.Query(
+		"SELECT " + notificationSettingsColumns + ` FROM notification_settings
+		WHERE morning_digest IS NOT NULL OR evening_digest IS NOT NULL OR report_period != ''`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query digest settings: %w", err)
+	}
+	defer rows.Close()
+
+	var all []*core.NotificationSettings
+	for rows.Next() {
+		settings, err := scanNotificationSettings(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan digest settings: %w", err)
+		}
+		all = append(all, settings)
+	}
+	return all, nil
+}
+
 // UpdateNotificationSettings updates or creates notification settings for a user
-// TODO: Add UI for users to configure their notification preferences
 func (s *Store) UpdateNotificationSettings(settings *core.NotificationSettings) error {
 	query := `
-		INSERT INTO notification_settings (user_id, reminder_delta_minutes, snooze_default_minutes, updated_at)
-		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		INSERT INTO notification_settings (user_id, reminder_delta_minutes, snooze_default_minutes,
+			quiet_start, quiet_end, quiet_days, urgent_during_quiet, morning_digest, evening_digest, report_period, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
 		ON CONFLICT(user_id) DO UPDATE SET
 			reminder_delta_minutes = excluded.reminder_delta_minutes,
 			snooze_default_minutes = excluded.snooze_default_minutes,
+			quiet_start = excluded.quiet_start,
+			quiet_end = excluded.quiet_end,
+			quiet_days = excluded.quiet_days,
+			urgent_during_quiet = excluded.urgent_during_quiet,
+			morning_digest = excluded.morning_digest,
+			evening_digest = excluded.evening_digest,
+			report_period = excluded.report_period,
 			updated_at = CURRENT_TIMESTAMP
 	`
 
-	_, err := s.DB.Exec(query, settings.UserID, settings.ReminderDeltaMinutes, settings.SnoozeDefaultMinutes)
+	_, err := s.DB.Exec(query, settings.UserID, settings.ReminderDeltaMinutes, settings.SnoozeDefaultMinutes,
+		settings.QuietHours.Start, settings.QuietHours.End, core.FormatQuietDays(settings.QuietDays),
+		settings.UrgentDuringQuiet, settings.MorningDigest, settings.EveningDigest, settings.ReportPeriod)
 	if err != nil {
 		return fmt.Errorf("failed to update notification settings: %w", err)
 	}
@@ -54,11 +120,40 @@ func (s *Store) UpdateNotificationSettings(settings *core.NotificationSettings)
 	return nil
 }
 
-// CreateNotification schedules a new task notification
+// ClaimDigest records that the user's digest of the given kind is being sent
+// for the day. It reports false if it was already sent.
+func (s *Store) ClaimDigest(userID int64, kind, day string) (bool, error) {
+	result, err := s.DB.Exec(
+		"INSERT OR IGNORE INTO digest_runs (user_id, kind, day) VALUES (?, ?, ?)",
+		userID, kind, day,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim digest: %w", err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim digest: %w", err)
+	}
+	return claimed > 0, nil
+}
+
+// ReleaseDigest forgets a claimed digest so it is sent again
+func (s *Store) ReleaseDigest(userID int64, kind, day string) error {
+	_, err := s.DB.Exec("DELETE FROM digest_runs WHERE user_id = ? AND kind = ? AND day = ?", userID, kind, day)
+	if err != nil {
+		return fmt.Errorf("failed to release digest: %w", err)
+	}
+	return nil
+}
+
+// CreateNotification schedules a new task notification. A notification with
+// SentAt set only records an event, such as a dismissed nag.
 func (s *Store) CreateNotification(notification *core.TaskNotification) error {
 	result, err := s.DB.Exec(
-		"INSERT INTO task_notifications (task_id, user_id, notification_type, scheduled_at) VALUES (?, ?, ?, ?)",
-		notification.TaskID, notification.UserID, notification.NotificationType, notification.ScheduledAt,
+		`INSERT INTO task_notifications (task_id, user_id, notification_type, scheduled_at, sent_at, stage, about_user_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		notification.TaskID, notification.UserID, notification.NotificationType, notification.ScheduledAt.UTC(),
+		nullableTime(notification.SentAt), notification.Stage, notification.AboutUserID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create notification: %w", err)
@@ -73,46 +168,110 @@ func (s *Store) CreateNotification(notification *core.TaskNotification) error {
 	return nil
 }
 
-// GetPendingNotifications retrieves all notifications that should be sent
-// (scheduled_at <= now and sent_at is NULL)
-func (s *Store) GetPendingNotifications(now time.Time) ([]*core.TaskNotification, error) {
-	rows, err := s.DB.Query(
-		`SELECT id, task_id, user_id, notification_type, scheduled_at, sent_at, created_at 
-		FROM task_notifications 
-		WHERE sent_at IS NULL AND scheduled_at <= ?
-		ORDER BY scheduled_at ASC`,
-		now,
-	)
+// notificationColumns is the column list used by scanNotification
+const notificationColumns = "n.id, n.task_id, n.user_id, n.notification_type, n.scheduled_at, n.sent_at, " +
+	"n.attempts, n.last_error, n.next_attempt_at, n.failed_at, n.stage, n.about_user_id, n.created_at"
+
+// scanNotification scans a row selected with notificationColumns
+func scanNotification(row rowScanner) (*core.TaskNotification, error) {
+	notification := &core.TaskNotification{}
+	var sentAt, nextAttemptAt, failedAt sql.NullTime
+	var aboutUserID sql.NullInt64
+
+	if err := row.Scan(&notification.ID, &notification.TaskID, &notification.UserID, &notification.NotificationType,
+		&notification.ScheduledAt, &sentAt, &notification.Attempts, &notification.LastError, &nextAttemptAt,
+		&failedAt, &notification.Stage, &aboutUserID, &notification.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if sentAt.Valid {
+		notification.SentAt = &sentAt.Time
+	}
+	if nextAttemptAt.Valid {
+		notification.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if failedAt.Valid {
+		notification.FailedAt = &failedAt.Time
+	}
+	if aboutUserID.Valid {
+		notification.AboutUserID = &aboutUserID.Int64
+	}
+	return notification, nil
+}
+
+// queryNotifications runs a query selecting notificationColumns
+func (s *Store) queryNotifications(query string, args ...interface{}) ([]*core.TaskNotification, error) {
+	rows, err := s.DB.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query pending notifications: %w", err)
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
 	}
 	defer rows.Close()
 
 	var notifications []*core.TaskNotification
 	for rows.Next() {
-		notification := &core.TaskNotification{}
-		var sentAt sql.NullTime
-
-		if err := rows.Scan(
-			&notification.ID,
-			&notification.TaskID,
-			&notification.UserID,
-			&notification.NotificationType,
-			&notification.ScheduledAt,
-			&sentAt,
-			&notification.CreatedAt,
-		); err != nil {
+		notification, err := scanNotification(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan notification: %w", err)
 		}
+		notifications = append(notifications, notification)
+	}
 
-		if sentAt.Valid {
-			notification.SentAt = &sentAt.Time
-		}
+	return notifications, rows.Err()
+}
 
-		notifications = append(notifications, notification)
+// GetPendingNotifications retrieves all notifications that should be sent
+// (scheduled_at, or next_attempt_at after a failed attempt, <= now and neither
+// sent nor failed). Notifications of tasks hidden
+// until a later start date wait until the task becomes available, and those
+// of tasks the recipient snoozed until the snooze ends.
+func (s *Store) GetPendingNotifications(now time.Time) ([]*core.TaskNotification, error) {
+	return s.queryNotifications(
+		`SELECT `+notificationColumns+`
+		FROM task_notifications n
+		JOIN tasks t ON t.id = n.task_id
+		WHERE n.sent_at IS NULL AND n.failed_at IS NULL AND COALESCE(n.next_attempt_at, n.scheduled_at) <= ?
+		AND (t.available_from IS NULL OR t.available_from <= ?)
+		AND NOT EXISTS (SELECT 1 FROM task_snoozes z WHERE z.task_id = n.task_id AND z.user_id = n.user_id AND z.until > ?)
+		ORDER BY n.scheduled_at ASC`,
+		now.UTC(), now.UTC(), now.UTC(),
+	)
+}
+
+// GetNextNotificationTime returns when the next notification becomes due:
+// its scheduled time (or retry time), but not before its task is available
+// and the recipient's snooze of it is over.
+// ok is false when nothing is waiting.
+func (s *Store) GetNextNotificationTime() (next time.Time, ok bool, err error) {
+	var scheduledAt time.Time
+	var nextAttemptAt, availableFrom, snoozedUntil sql.NullTime
+
+	err = s.DB.QueryRow(
+		`SELECT n.scheduled_at, n.next_attempt_at, t.available_from, z.until
+		FROM task_notifications n
+		JOIN tasks t ON t.id = n.task_id
+		LEFT JOIN task_snoozes z ON z.task_id = n.task_id AND z.user_id = n.user_id
+		WHERE n.sent_at IS NULL AND n.failed_at IS NULL
+		ORDER BY MAX(COALESCE(n.next_attempt_at, n.scheduled_at), COALESCE(t.available_from, ''), COALESCE(z.until, '')) ASC
+		LIMIT 1`,
+	).Scan(&scheduledAt, &nextAttemptAt, &availableFrom, &snoozedUntil)
+	if err == sql.ErrNoRows {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to get next notification time: %w", err)
 	}
 
-	return notifications, nil
+	next = scheduledAt
+	if nextAttemptAt.Valid {
+		next = nextAttemptAt.Time
+	}
+	if availableFrom.Valid && availableFrom.Time.After(next) {
+		next = availableFrom.Time
+	}
+	if snoozedUntil.Valid && snoozedUntil.Time.After(next) {
+		next = snoozedUntil.Time
+	}
+	return next, true, nil
 }
 
 // MarkNotificationSent marks a notification as sent with the current timestamp
@@ -136,6 +295,18 @@ func (s *Store) MarkNotificationSent(notificationID int64) error {
 	return nil
 }
 
+// DeferNotification moves a pending notification to a later time
+func (s *Store) DeferNotification(notificationID int64, until time.Time) error {
+	_, err := s.DB.Exec(
+		"UPDATE task_notifications SET scheduled_at = ?, next_attempt_at = NULL WHERE id = ? AND sent_at IS NULL",
+		until.UTC(), notificationID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to defer notification: %w", err)
+	}
+	return nil
+}
+
 // DeleteNotificationsByTask deletes all pending notifications for a task
 // This should be called when a task is completed or deleted
 func (s *Store) DeleteNotificationsByTask(taskID int64) error {
@@ -149,24 +320,118 @@ func (s *Store) DeleteNotificationsByTask(taskID int64) error {
 	return nil
 }
 
-// GetNotificationByID retrieves a notification by its ID
-func (s *Store) GetNotificationByID(id int64) (*core.TaskNotification, error) {
-	notification := &core.TaskNotification{}
-	var sentAt sql.NullTime
+// DeleteNotificationsByTaskAndUser deletes one member's pending notifications for a task,
+// e.g. after they completed it, including the alerts about them to their accountability partner
+func (s *Store) DeleteNotificationsByTaskAndUser(taskID, userID int64) error {
+	query := `DELETE FROM task_notifications WHERE task_id = ? AND (user_id = ? OR about_user_id = ?) AND sent_at IS NULL`
 
-	err := s.DB.QueryRow(
-		"SELECT id, task_id, user_id, notification_type, scheduled_at, sent_at, created_at FROM task_notifications WHERE id = ?",
-		id,
-	).Scan(
-		&notification.ID,
-		&notification.TaskID,
-		&notification.UserID,
-		&notification.NotificationType,
-		&notification.ScheduledAt,
-		&sentAt,
-		&notification.CreatedAt,
+	_, err := s.DB.Exec(query, taskID, userID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete notifications for task: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDeadlineReminders deletes the pending reminders before and at the
+// deadline of a task for one member, or for every member when userID is 0
+func (s *Store) DeleteDeadlineReminders(taskID, userID int64) error {
+	_, err := s.DB.Exec(
+		`DELETE FROM task_notifications WHERE task_id = ? AND (? = 0 OR user_id = ?)
+		AND notification_type IN ('before_deadline', 'on_deadline') AND sent_at IS NULL`,
+		taskID, userID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete deadline reminders: %w", err)
+	}
+	return nil
+}
+
+// SetTaskReminderOptOut records or clears a member's opt-out of a task's reminders
+func (s *Store) SetTaskReminderOptOut(taskID, userID int64, optOut bool) error {
+	query := `DELETE FROM task_reminder_optouts WHERE task_id = ? AND user_id = ?`
+	if optOut {
+		query = `INSERT OR IGNORE INTO task_reminder_optouts (task_id, user_id) VALUES (?, ?)`
+	}
+	if _, err := s.DB.Exec(query, taskID, userID); err != nil {
+		return fmt.Errorf("failed to update reminder opt-out: %w", err)
+	}
+	return nil
+}
+
+// GetTaskReminderOptOuts returns the members who opted out of a task's reminders
+func (s *Store) GetTaskReminderOptOuts(taskID int64) (map[int64]bool, error) {
+	return s.queryIDSet(`SELECT user_id FROM task_reminder_optouts WHERE task_id = ?`, taskID)
+}
+
+// GetReminderOptOuts returns the tasks a user opted out of reminders for
+func (s *Store) GetReminderOptOuts(userID int64) (map[int64]bool, error) {
+	return s.queryIDSet(`SELECT task_id FROM task_reminder_optouts WHERE user_id = ?`, userID)
+}
+
+// queryIDSet runs a query selecting one ID column and returns the IDs as a set
+func (s *Store) queryIDSet(query string, args ...interface{}) (map[int64]bool, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ids: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan id: %w", err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// DeleteNagNotifications deletes the pending nag reminders and partner alerts of a task
+func (s *Store) DeleteNagNotifications(taskID int64) error {
+	_, err := s.DB.Exec(
+		`DELETE FROM task_notifications WHERE task_id = ? AND notification_type IN ('nag', 'nag_partner') AND sent_at IS NULL`,
+		taskID,
 	)
+	if err != nil {
+		return fmt.Errorf("failed to delete nag notifications: %w", err)
+	}
+	return nil
+}
 
+// DeleteNagNotificationsForUser deletes the pending nag reminders of one member
+// and the partner alerts about them. It reports whether any were pending.
+func (s *Store) DeleteNagNotificationsForUser(taskID, userID int64) (bool, error) {
+	result, err := s.DB.Exec(
+		`DELETE FROM task_notifications WHERE task_id = ? AND sent_at IS NULL
+		AND ((notification_type = 'nag' AND user_id = ?) OR (notification_type = 'nag_partner' AND about_user_id = ?))`,
+		taskID, userID, userID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete nag notifications: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// GetNaggedTaskIDs returns the tasks the user has pending nag reminders for
+func (s *Store) GetNaggedTaskIDs(userID int64) (map[int64]bool, error) {
+	return s.queryIDSet(
+		`SELECT DISTINCT task_id FROM task_notifications WHERE user_id = ? AND notification_type = 'nag' AND sent_at IS NULL`,
+		userID,
+	)
+}
+
+// GetNotificationByID retrieves a notification by its ID
+func (s *Store) GetNotificationByID(id int64) (*core.TaskNotification, error) {
+	notification, err := scanNotification(s.DB.QueryRow(
+		"SELECT "+notificationColumns+" FROM task_notifications n WHERE n.id = ?",
+		id,
+	))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("notification not found")
@@ -174,9 +439,63 @@ func (s *Store) GetNotificationByID(id int64) (*core.TaskNotification, error) {
 		return nil, fmt.Errorf("failed to get notification: %w", err)
 	}
 
-	if sentAt.Valid {
-		notification.SentAt = &sentAt.Time
+	return notification, nil
+}
+
+// ScheduleNotificationRetry records a failed delivery attempt and when to try again
+func (s *Store) ScheduleNotificationRetry(notificationID int64, lastError string, nextAttemptAt time.Time) error {
+	_, err := s.DB.Exec(
+		"UPDATE task_notifications SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE id = ?",
+		lastError, nextAttemptAt.UTC(), notificationID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to schedule notification retry: %w", err)
 	}
+	return nil
+}
 
-	return notification, nil
+// MarkNotificationFailed records a failed delivery attempt and gives up on the notification
+func (s *Store) MarkNotificationFailed(notificationID int64, lastError string, failedAt time.Time) error {
+	_, err := s.DB.Exec(
+		"UPDATE task_notifications SET attempts = attempts + 1, last_error = ?, next_attempt_at = NULL, failed_at = ? WHERE id = ?",
+		lastError, failedAt.UTC(), notificationID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as failed: %w", err)
+	}
+	return nil
+}
+
+// GetFailedNotifications retrieves notifications that were given up on, newest first
+func (s *Store) GetFailedNotifications(limit int) ([]*core.TaskNotification, error) {
+	return s.queryNotifications(
+		"SELECT "+notificationColumns+" FROM task_notifications n WHERE n.failed_at IS NOT NULL ORDER BY n.failed_at DESC LIMIT ?",
+		limit,
+	)
+}
+
+// ResetNotificationDelivery clears the failed state and attempts of a
+// notification so it is delivered again at the given time
+func (s *Store) ResetNotificationDelivery(notificationID int64, at time.Time) error {
+	result, err := s.DB.Exec(
+		`UPDATE task_notifications SET attempts = 0, last_error = '', next_attempt_at = NULL, failed_at = NULL,
+		scheduled_at = ? WHERE id = ? AND sent_at IS NULL`,
+		at.UTC(), notificationID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reset notification: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("notification not found")
+	}
+	return nil
+}
+
+// DeleteNotification deletes a single notification
+func (s *Store) DeleteNotification(notificationID int64) error {
+	_, err := s.DB.Exec("DELETE FROM task_notifications WHERE id = ?", notificationID)
+	if err != nil {
+		return fmt.Errorf("failed to delete notification: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// pauseColumns is the column list used by scanPause
+const pauseColumns = "id, user_id, group_id, starts_at, ends_at, reason, resumed_at, created_by, created_at"
+
+// scanPause scans a row selected with pauseColumns
+func scanPause(row rowScanner) (*core.Pause, error) {
+	p := &core.Pause{}
+	var userID, groupID sql.NullInt64
+	var resumedAt sql.NullTime
+
+	if err := row.Scan(&p.ID, &userID, &groupID, &p.StartsAt, &p.EndsAt, &p.Reason, &resumedAt, &p.CreatedBy, &p.CreatedAt); err != nil {
+		return nil, err
+	}
+	if userID.Valid {
+		p.UserID = &userID.Int64
+	}
+	if groupID.Valid {
+		p.GroupID = &groupID.Int64
+	}
+	if resumedAt.Valid {
+		p.ResumedAt = &resumedAt.Time
+	}
+
+	return p, nil
+}
+
+// CreatePause stores a new user or group pause
+func (s *Store) CreatePause(p *core.Pause) (*core.Pause, error) {
+	result, err := s.DB.Exec(
+		"INSERT INTO pauses (user_id, group_id, starts_at, ends_at, reason, created_by) VALUES (?, ?, ?, ?, ?, ?)",
+		p.UserID, p.GroupID, p.StartsAt.UTC(), p.EndsAt.UTC(), p.Reason, p.CreatedBy,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pause: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return s.GetPauseByID(id)
+}
+
+// GetPauseByID retrieves a pause by ID
+func (s *Store) GetPauseByID(id int64) (*core.Pause, error) {
+	p, err := scanPause(s.DB.QueryRow("SELECT "+pauseColumns+" FROM pauses WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("pause not found")
+		}
+		return nil, fmt.Errorf("failed to get pause: %w", err)
+	}
+
+	return p, nil
+}
+
+// GetPausesByUserID retrieves a user's personal pauses, latest first
+func (s *Store) GetPausesByUserID(userID int64) ([]*core.Pause, error) {
+	return s.queryPauses("SELECT "+pauseColumns+" FROM pauses WHERE user_id = ? ORDER BY starts_at DESC", userID)
+}
+
+// GetPausesByGroupID retrieves the pauses of a whole group, latest first
+func (s *Store) GetPausesByGroupID(groupID int64) ([]*core.Pause, error) {
+	return s.queryPauses("SELECT "+pauseColumns+" FROM pauses WHERE group_id = ? ORDER BY starts_at DESC", groupID)
+}
+
+// GetUnresumedPauses retrieves every pause the scheduler has not resumed yet
+func (s *Store) GetUnresumedPauses() ([]*core.Pause, error) {
+	return s.queryPauses("SELECT " + pauseColumns + " FROM pauses WHERE resumed_at IS NULL ORDER BY ends_at ASC")
+}
+
+// queryPauses runs a query selecting pauseColumns and scans every row
+func (s *Store) queryPauses(query string, args ...interface{}) ([]*core.Pause, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pauses: %w", err)
+	}
+	defer rows.Close()
+
+	var pauses []*core.Pause
+	for rows.Next() {
+		p, err := scanPause(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pause: %w", err)
+		}
+		pauses = append(pauses, p)
+	}
+
+	return pauses, nil
+}
+
+// SetPauseEndsAt moves the end of a pause, e.g. to end it early
+func (s *Store) SetPauseEndsAt(id int64, endsAt time.Time) error {
+	_, err := s.DB.Exec("UPDATE pauses SET ends_at = ? WHERE id = ?", endsAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update pause: %w", err)
+	}
+	return nil
+}
+
+// MarkPauseResumed records that work held back by the pause has been resumed
+func (s *Store) MarkPauseResumed(id int64, resumedAt time.Time) error {
+	_, err := s.DB.Exec("UPDATE pauses SET resumed_at = ? WHERE id = ?", resumedAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update pause: %w", err)
+	}
+	return nil
+}
+
+// DeletePause removes a pause that has not started yet
+func (s *Store) DeletePause(id int64) error {
+	_, err := s.DB.Exec("DELETE FROM pauses WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete pause: %w", err)
+	}
+	return nil
+}
@@ -19,7 +19,7 @@ func (s *Store) CreateOrUpdateUserProfile(userID int64, telegramPhotoURL string,
 			notification_enabled = excluded.notification_enabled
 	`
 
-	now := time.Now()
+	now := time.Now().UTC()
 	_, err := s.DB.Exec(query, userID, telegramPhotoURL, now, notificationEnabled)
 	if err != nil {
 		return fmt.Errorf("failed to create/update user profile: %w", err)
@@ -37,10 +37,12 @@ func (s *Store) GetUserProfile(userID int64) (*core.UserProfile, error) {
 	`
 
 	var up core.UserProfile
+	var photoURL sql.NullString
 	var cachedAt sql.NullTime
 
+	// Rows created by SetNotificationEnabled have no photo yet
 	err := s.DB.QueryRow(query, userID).Scan(
-		&up.UserID, &up.TelegramPhotoURL, &cachedAt, &up.NotificationEnabled,
+		&up.UserID, &photoURL, &cachedAt, &up.NotificationEnabled,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -53,6 +55,7 @@ func (s *Store) GetUserProfile(userID int64) (*core.UserProfile, error) {
 		return nil, fmt.Errorf("failed to get user profile: %w", err)
 	}
 
+	up.TelegramPhotoURL = photoURL.String
 	if cachedAt.Valid {
 		up.TelegramPhotoCachedAt = &cachedAt.Time
 	}
@@ -70,7 +73,7 @@ func (s *Store) UpdateTelegramPhoto(userID int64, photoURL string) error {
 			telegram_photo_cached_at = excluded.telegram_photo_cached_at
 	`
 
-	now := time.Now()
+	now := time.Now().UTC()
 	_, err := s.DB.Exec(query, userID, photoURL, now)
 	if err != nil {
 		return fmt.Errorf("failed to update telegram photo: %w", err)
@@ -32,7 +32,7 @@ func (s *Store) CreatePurchase(transactionID, userID, groupID, shopItemID int64)
 func (s *Store) GetPurchaseByID(id int64) (*core.Purchase, error) {
 	query := `
 		SELECT id, transaction_id, user_id, group_id, shop_item_id,
-		       fulfilled, fulfilled_at, fulfilled_by, notes, created_at
+		       fulfilled, fulfilled_at, fulfilled_by, COALESCE(notes, '') as notes, created_at
 		FROM purchases
 		WHERE id = ?
 	`
@@ -154,7 +154,7 @@ func (s *Store) MarkPurchaseFulfilled(purchaseID, fulfilledByUserID int64, notes
 		WHERE id = ?
 	`
 
-	now := time.Now()
+	now := time.Now().UTC()
 	_, err := s.DB.Exec(query, now, fulfilledByUserID, notes, purchaseID)
 	if err != nil {
 		return fmt.Errorf("failed to mark purchase as fulfilled: %w", err)
@@ -171,7 +171,7 @@ func (s *Store) CancelPurchaseByTransactionID(transactionID int64) error {
 		WHERE transaction_id = ? AND cancelled_at IS NULL
 	`
 
-	now := time.Now()
+	now := time.Now().UTC()
 	result, err := s.DB.Exec(query, now, transactionID)
 	if err != nil {
 		return fmt.Errorf("failed to cancel purchase: %w", err)
@@ -188,12 +188,22 @@ func (s *Store) CancelPurchaseByTransactionID(transactionID int64) error {
 	return nil
 }
 
-// GetPurchaseHistoryByUserAndGroup retrieves detailed purchase history
-func (s *Store) GetPurchaseHistoryByUserAndGroup(userID, groupID int64) ([]*core.PurchaseHistory, error) {
+// GetPurchaseHistory retrieves a page of detailed purchase history, newest first
+func (s *Store) GetPurchaseHistory(groupID int64, filter core.LogFilter) ([]*core.PurchaseHistory, error) {
+	clause, args := logFilterClause(filter, "purchases", "p", "shop_item_id")
+	switch filter.Status {
+	case core.LogStatusPending:
+		clause += " AND p.fulfilled_at IS NULL AND p.cancelled_at IS NULL"
+	case core.LogStatusFulfilled:
+		clause += " AND p.fulfilled_at IS NOT NULL AND p.cancelled_at IS NULL"
+	case core.LogStatusCancelled:
+		clause += " AND p.cancelled_at IS NOT NULL"
+	}
+
 	query := `
 		SELECT
 			p.id, p.transaction_id, p.user_id, p.group_id, p.shop_item_id,
-			p.fulfilled, p.fulfilled_at, p.fulfilled_by, COALESCE(p.notes, '') as notes, p.created_at,
+			p.fulfilled, p.fulfilled_at, p.fulfilled_by, COALESCE(p.notes, '') as notes, p.cancelled_at, p.created_at,
 			t.description, t.notes,
 			si.id, si.group_id, si.title, si.description, si.cost, si.created_at,
 			u.id, u.telegram_id, u.username, u.created_at
@@ -201,11 +211,13 @@ func (s *Store) GetPurchaseHistoryByUserAndGroup(userID, groupID int64) ([]*core
 		JOIN transactions t ON p.transaction_id = t.id
 		LEFT JOIN shop_items si ON p.shop_item_id = si.id
 		JOIN users u ON p.user_id = u.id
-		WHERE p.user_id = ? AND p.group_id = ?
-		ORDER BY p.created_at DESC
+		WHERE p.group_id = ?` + clause + `
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT ?
 	`
 
-	rows, err := s.DB.Query(query, userID, groupID)
+	args = append([]interface{}{groupID}, args...)
+	rows, err := s.DB.Query(query, append(args, filter.Limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query purchase history: %w", err)
 	}
@@ -220,6 +232,7 @@ func (s *Store) GetPurchaseHistoryByUserAndGroup(userID, groupID int64) ([]*core
 
 		var fulfilledAt sql.NullTime
 		var fulfilledBy sql.NullInt64
+		var cancelledAt sql.NullTime
 		var telegramID sql.NullInt64
 		var transactionDescription sql.NullString
 		var transactionNotes sql.NullString
@@ -235,7 +248,7 @@ func (s *Store) GetPurchaseHistoryByUserAndGroup(userID, groupID int64) ([]*core
 		if err := rows.Scan(
 			&ph.Purchase.ID, &ph.Purchase.TransactionID, &ph.Purchase.UserID,
 			&ph.Purchase.GroupID, &ph.Purchase.ShopItemID, &ph.Purchase.Fulfilled,
-			&fulfilledAt, &fulfilledBy, &ph.Purchase.Notes, &ph.Purchase.CreatedAt,
+			&fulfilledAt, &fulfilledBy, &ph.Purchase.Notes, &cancelledAt, &ph.Purchase.CreatedAt,
 			&transactionDescription, &transactionNotes,
 			&shopItemID, &shopItemGroupID, &shopItemTitle,
 			&shopItemDescription, &shopItemCost, &shopItemCreatedAt,
@@ -250,6 +263,9 @@ func (s *Store) GetPurchaseHistoryByUserAndGroup(userID, groupID int64) ([]*core
 		if fulfilledBy.Valid {
 			ph.Purchase.FulfilledBy = &fulfilledBy.Int64
 		}
+		if cancelledAt.Valid {
+			ph.Purchase.CancelledAt = &cancelledAt.Time
+		}
 		if telegramID.Valid {
 			ph.User.TelegramID = &telegramID.Int64
 		}
@@ -0,0 +1,172 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"small-rpg-adhd-monolith/internal/core"
+	"time"
+)
+
+// The report queries take userID 0 to cover every member of the group. Undone
+// completions and purchases are reversed by a transaction with the opposite
+// amount, so sums come out net and reversals count -1.
+
+// GetTaskReport sums the completions of each task in [since, until)
+func (s *Store) GetTaskReport(groupID, userID int64, since, until time.Time) ([]*core.ReportTask, error) {
+	rows, err := s.DB.Query(`
+		SELECT source_id, MAX(description),
+			SUM(CASE WHEN amount < 0 THEN -1 ELSE 1 END) AS completions,
+			SUM(CASE WHEN amount < 0 THEN -quantity ELSE quantity END),
+			SUM(amount) AS earned
+		FROM transactions
+		WHERE group_id = ? AND (? = 0 OR user_id = ?) AND source_type = ? AND parent_transaction_id IS NULL
+			AND created_at >= ? AND created_at < ?
+		GROUP BY source_id
+		HAVING completions != 0
+		ORDER BY completions DESC, earned DESC`,
+		groupID, userID, userID, string(core.SourceTypeTask), timestampArg(since), timestampArg(until),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task report: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*core.ReportTask
+	for rows.Next() {
+		t := &core.ReportTask{}
+		var taskID sql.NullInt64
+		if err := rows.Scan(&taskID, &t.Title, &t.Completions, &t.Quantity, &t.Earned); err != nil {
+			return nil, fmt.Errorf("failed to scan task report: %w", err)
+		}
+		t.TaskID = taskID.Int64
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// GetMemberReport sums each member's cheese earned with tasks, spent in the
+// shop and lost to penalties in [since, until), keyed by user ID
+func (s *Store) GetMemberReport(groupID, userID int64, since, until time.Time) (map[int64]*core.ReportMember, error) {
+	rows, err := s.DB.Query(`
+		SELECT user_id,
+			COALESCE(SUM(CASE WHEN source_type = ? THEN (CASE WHEN amount < 0 THEN -1 ELSE 1 END) END), 0),
+			COALESCE(SUM(CASE WHEN source_type = ? THEN amount END), 0),
+			COALESCE(-SUM(CASE WHEN source_type = ? THEN amount END), 0),
+			COALESCE(-SUM(CASE WHEN source_type = ? THEN amount END), 0)
+		FROM transactions
+		WHERE group_id = ? AND (? = 0 OR user_id = ?) AND currency_id = ? AND parent_transaction_id IS NULL
+			AND created_at >= ? AND created_at < ?
+		GROUP BY user_id`,
+		string(core.SourceTypeTask), string(core.SourceTypeTask), string(core.SourceTypeShopItem), string(core.SourceTypePenalty),
+		groupID, userID, userID, core.DefaultCurrencyID, timestampArg(since), timestampArg(until),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query member report: %w", err)
+	}
+	defer rows.Close()
+
+	members := make(map[int64]*core.ReportMember)
+	for rows.Next() {
+		m := &core.ReportMember{}
+		if err := rows.Scan(&m.UserID, &m.Completions, &m.Earned, &m.Spent, &m.Penalties); err != nil {
+			return nil, fmt.Errorf("failed to scan member report: %w", err)
+		}
+		members[m.UserID] = m
+	}
+	return members, rows.Err()
+}
+
+// GetDailyReport sums completions and cheese earned with tasks per day in
+// [since, until). Days are cut at since's UTC offset, so they can be an hour
+// off around a daylight saving change.
+func (s *Store) GetDailyReport(groupID, userID int64, since, until time.Time) ([]*core.ReportDay, error) {
+	rows, err := s.DB.Query(`
+		SELECT date(created_at, ?) AS day,
+			SUM(CASE WHEN amount < 0 THEN -1 ELSE 1 END),
+			SUM(amount)
+		FROM transactions
+		WHERE group_id = ? AND (? = 0 OR user_id = ?) AND source_type = ? AND parent_transaction_id IS NULL
+			AND created_at >= ? AND created_at < ?
+		GROUP BY day
+		ORDER BY day`,
+		localDayModifier(since),
+		groupID, userID, userID, string(core.SourceTypeTask), timestampArg(since), timestampArg(until),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily report: %w", err)
+	}
+	defer rows.Close()
+
+	var days []*core.ReportDay
+	for rows.Next() {
+		d := &core.ReportDay{}
+		var date string
+		if err := rows.Scan(&date, &d.Completions, &d.Earned); err != nil {
+			return nil, fmt.Errorf("failed to scan daily report: %w", err)
+		}
+		d.Date, err = time.ParseInLocation("2006-01-02", date, since.Location())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse report day %q: %w", date, err)
+		}
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
+
+// CountPurchases counts the shop purchases made in [since, until) that were
+// not undone
+func (s *Store) CountPurchases(groupID, userID int64, since, until time.Time) (int, error) {
+	var count int
+	err := s.DB.QueryRow(`
+		SELECT COUNT(*) FROM purchases
+		WHERE group_id = ? AND (? = 0 OR user_id = ?) AND cancelled_at IS NULL AND created_at >= ? AND created_at < ?`,
+		groupID, userID, userID, timestampArg(since), timestampArg(until),
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count purchases: %w", err)
+	}
+	return count, nil
+}
+
+// RecordMissedDeadline notes that a member missed the task's current deadline
+func (s *Store) RecordMissedDeadline(task *core.Task, userID int64) error {
+	_, err := s.DB.Exec(
+		"INSERT OR IGNORE INTO missed_deadlines (task_id, user_id, group_id, due_at) VALUES (?, ?, ?, ?)",
+		task.ID, userID, task.GroupID, task.DueAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record missed deadline: %w", err)
+	}
+	return nil
+}
+
+// GetMissedDeadlineCounts counts the deadlines in [since, until) each member
+// missed, keyed by user ID
+func (s *Store) GetMissedDeadlineCounts(groupID int64, since, until time.Time) (map[int64]int, error) {
+	rows, err := s.DB.Query(
+		"SELECT user_id, COUNT(*) FROM missed_deadlines WHERE group_id = ? AND due_at >= ? AND due_at < ? GROUP BY user_id",
+		groupID, since.UTC(), until.UTC(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count missed deadlines: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var userID int64
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan missed deadlines: %w", err)
+		}
+		counts[userID] = count
+	}
+	return counts, rows.Err()
+}
+
+// localDayModifier is the SQLite date modifier moving UTC timestamps to t's
+// UTC offset, so date() returns local days
+func localDayModifier(t time.Time) string {
+	_, offset := t.Zone()
+	return fmt.Sprintf("%+d seconds", offset)
+}
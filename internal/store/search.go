@@ -0,0 +1,212 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// searchDocuments lists everything search covers as (kind, ref_id, group_id,
+// user_id, title, body) rows. It fills the FTS5 index and is scanned directly
+// with LIKE when SQLite was built without FTS5. Only main transactions with a
+// description or notes are covered, extra-currency legs repeat them.
+const searchDocuments = `
+	SELECT 'task' AS kind, id AS ref_id, group_id, 0 AS user_id, title, COALESCE(description, '') AS body FROM tasks
+	UNION ALL
+	SELECT 'item', id, group_id, 0, title, COALESCE(description, '') FROM shop_items
+	UNION ALL
+	SELECT 'transaction', id, group_id, user_id, COALESCE(description, ''), COALESCE(notes, '') FROM transactions
+	WHERE parent_transaction_id IS NULL AND (COALESCE(description, '') != '' OR COALESCE(notes, '') != '')`
+
+// Index rows are keyed by rowid = ref_id*4 + kind code, so the triggers can
+// replace a row without scanning the index
+const searchTriggers = `
+	CREATE TRIGGER search_tasks_insert AFTER INSERT ON tasks BEGIN
+		INSERT INTO search_index(rowid, kind, ref_id, group_id, user_id, title, body)
+		VALUES (new.id*4 + 1, 'task', new.id, new.group_id, 0, new.title, COALESCE(new.description, ''));
+	END;
+	CREATE TRIGGER search_tasks_update AFTER UPDATE OF title, description, group_id ON tasks BEGIN
+		DELETE FROM search_index WHERE rowid = old.id*4 + 1;
+		INSERT INTO search_index(rowid, kind, ref_id, group_id, user_id, title, body)
+		VALUES (new.id*4 + 1, 'task', new.id, new.group_id, 0, new.title, COALESCE(new.description, ''));
+	END;
+	CREATE TRIGGER search_tasks_delete AFTER DELETE ON tasks BEGIN
+		DELETE FROM search_index WHERE rowid = old.id*4 + 1;
+	END;
+
+	CREATE TRIGGER search_items_insert AFTER INSERT ON shop_items BEGIN
+		INSERT INTO search_index(rowid, kind, ref_id, group_id, user_id, title, body)
+		VALUES (new.id*4 + 2, 'item', new.id, new.group_id, 0, new.title, COALESCE(new.description, ''));
+	END;
+	CREATE TRIGGER search_items_update AFTER UPDATE OF title, description, group_id ON shop_items BEGIN
+		DELETE FROM search_index WHERE rowid = old.id*4 + 2;
+		INSERT INTO search_index(rowid, kind, ref_id, group_id, user_id, title, body)
+		VALUES (new.id*4 + 2, 'item', new.id, new.group_id, 0, new.title, COALESCE(new.description, ''));
+	END;
+	CREATE TRIGGER search_items_delete AFTER DELETE ON shop_items BEGIN
+		DELETE FROM search_index WHERE rowid = old.id*4 + 2;
+	END;
+
+	CREATE TRIGGER search_transactions_insert AFTER INSERT ON transactions
+	WHEN new.parent_transaction_id IS NULL AND (COALESCE(new.description, '') != '' OR COALESCE(new.notes, '') != '') BEGIN
+		INSERT INTO search_index(rowid, kind, ref_id, group_id, user_id, title, body)
+		VALUES (new.id*4 + 3, 'transaction', new.id, new.group_id, new.user_id, COALESCE(new.description, ''), COALESCE(new.notes, ''));
+	END;
+	CREATE TRIGGER search_transactions_delete AFTER DELETE ON transactions BEGIN
+		DELETE FROM search_index WHERE rowid = old.id*4 + 3;
+	END;`
+
+// searchTriggerNames are the triggers searchTriggers creates
+var searchTriggerNames = []string{
+	"search_tasks_insert", "search_tasks_update", "search_tasks_delete",
+	"search_items_insert", "search_items_update", "search_items_delete",
+	"search_transactions_insert", "search_transactions_delete",
+}
+
+// migrateSearch sets up the FTS5 search index, kept in sync by triggers. The
+// index is rebuilt whenever the triggers are missing: on the first start, and
+// after a start without FTS5, when the triggers are dropped so writes do not
+// fail on the unusable index and search falls back to LIKE.
+func (s *Store) migrateSearch() error {
+	_, err := s.DB.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+		kind UNINDEXED, ref_id UNINDEXED, group_id UNINDEXED, user_id UNINDEXED, title, body,
+		tokenize = 'unicode61 remove_diacritics 2'
+	)`)
+	if err == nil {
+		// The index may have been created by a build with FTS5
+		_, err = s.DB.Exec(`SELECT rowid FROM search_index LIMIT 0`)
+	}
+	if err != nil {
+		if !strings.Contains(err.Error(), "fts5") {
+			return fmt.Errorf("failed to create search index: %w", err)
+		}
+		log.Printf("⚠️ SQLite was built without FTS5 (build with -tags sqlite_fts5), search falls back to LIKE")
+		for _, name := range searchTriggerNames {
+			if _, err := s.DB.Exec(`DROP TRIGGER IF EXISTS ` + name); err != nil {
+				return fmt.Errorf("failed to drop search trigger: %w", err)
+			}
+		}
+		return nil
+	}
+	s.fts = true
+
+	var triggers int
+	err = s.DB.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'search_%'`).Scan(&triggers)
+	if err != nil {
+		return fmt.Errorf("failed to check search triggers: %w", err)
+	}
+	if triggers == len(searchTriggerNames) {
+		return nil
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, name := range searchTriggerNames {
+		if _, err := tx.Exec(`DROP TRIGGER IF EXISTS ` + name); err != nil {
+			return fmt.Errorf("failed to drop search trigger: %w", err)
+		}
+	}
+	stmts := []string{
+		`DELETE FROM search_index`,
+		`INSERT INTO search_index(rowid, kind, ref_id, group_id, user_id, title, body)
+		 SELECT ref_id*4 + CASE kind WHEN 'task' THEN 1 WHEN 'item' THEN 2 ELSE 3 END, * FROM (` + searchDocuments + `)`,
+		searchTriggers,
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to build search index: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Search finds tasks, shop items and history entries of the given groups
+// matching every term, best matches first. History entries are limited to
+// the user's own, except in groups the user owns.
+func (s *Store) Search(userID int64, groupIDs []int64, terms []string, limit int) ([]*core.SearchResult, error) {
+	if len(groupIDs) == 0 || len(terms) == 0 {
+		return nil, nil
+	}
+
+	var args []interface{}
+	var source, match, order string
+	if s.fts {
+		// Quote each term so user input cannot use FTS5 syntax; the last one
+		// matches as a prefix to find words while they are being typed
+		quoted := make([]string, len(terms))
+		for i, term := range terms {
+			quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		}
+		quoted[len(quoted)-1] += "*"
+		source = "search_index"
+		match = "d.search_index MATCH ?"
+		order = "d.rank"
+		args = append(args, strings.Join(quoted, " "))
+	} else {
+		source = "(" + searchDocuments + ")"
+		likes := make([]string, len(terms))
+		for i, term := range terms {
+			likes[i] = `(d.title || ' ' || d.body) LIKE ? ESCAPE '\'`
+			args = append(args, "%"+escapeLike(term)+"%")
+		}
+		match = strings.Join(likes, " AND ")
+		order = "d.kind != 'task', d.kind != 'item', d.ref_id DESC"
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(groupIDs)), ", ")
+	for _, id := range groupIDs {
+		args = append(args, id)
+	}
+	args = append(args, userID, userID, limit)
+
+	query := `
+		SELECT d.kind, d.ref_id, d.group_id, g.name, d.title, d.body,
+			tr.amount, tr.source_type, tr.source_id, tr.created_at
+		FROM ` + source + ` d
+		JOIN groups g ON g.id = d.group_id
+		LEFT JOIN transactions tr ON d.kind = 'transaction' AND tr.id = d.ref_id
+		WHERE ` + match + ` AND d.group_id IN (` + placeholders + `)
+			AND (d.kind != 'transaction' OR d.user_id = ? OR g.owner_id = ?)
+		ORDER BY ` + order + `
+		LIMIT ?`
+
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*core.SearchResult
+	for rows.Next() {
+		var r core.SearchResult
+		var amount, sourceID sql.NullInt64
+		var sourceType sql.NullString
+		var createdAt sql.NullTime
+		if err := rows.Scan(&r.Kind, &r.ID, &r.GroupID, &r.GroupName, &r.Title, &r.Body,
+			&amount, &sourceType, &sourceID, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		r.Amount = int(amount.Int64)
+		r.SourceType = core.SourceType(sourceType.String)
+		if sourceID.Valid {
+			r.SourceID = &sourceID.Int64
+		}
+		if createdAt.Valid {
+			r.CreatedAt = createdAt.Time
+		}
+		results = append(results, &r)
+	}
+	return results, rows.Err()
+}
+
+// escapeLike escapes the LIKE wildcards of a search term
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
@@ -0,0 +1,182 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// seasonColumns is the column list used by scanSeason
+const seasonColumns = "id, group_id, name, starts_at, ends_at, award_badges, closed_at, created_by, created_at"
+
+// scanSeason scans a row selected with seasonColumns
+func scanSeason(row rowScanner) (*core.Season, error) {
+	season := &core.Season{}
+	var closedAt sql.NullTime
+
+	if err := row.Scan(&season.ID, &season.GroupID, &season.Name, &season.StartsAt, &season.EndsAt, &season.AwardBadges, &closedAt, &season.CreatedBy, &season.CreatedAt); err != nil {
+		return nil, err
+	}
+	if closedAt.Valid {
+		season.ClosedAt = &closedAt.Time
+	}
+
+	return season, nil
+}
+
+// CreateSeason stores a new season
+func (s *Store) CreateSeason(season *core.Season) (*core.Season, error) {
+	result, err := s.DB.Exec(
+		"INSERT INTO seasons (group_id, name, starts_at, ends_at, award_badges, created_by) VALUES (?, ?, ?, ?, ?, ?)",
+		season.GroupID, season.Name, season.StartsAt.UTC(), season.EndsAt.UTC(), season.AwardBadges, season.CreatedBy,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create season: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return s.GetSeasonByID(id)
+}
+
+// GetSeasonByID retrieves a season by ID
+func (s *Store) GetSeasonByID(id int64) (*core.Season, error) {
+	season, err := scanSeason(s.DB.QueryRow("SELECT "+seasonColumns+" FROM seasons WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("season not found")
+		}
+		return nil, fmt.Errorf("failed to get season: %w", err)
+	}
+
+	return season, nil
+}
+
+// GetSeasonsByGroupID retrieves the seasons of a group, latest first
+func (s *Store) GetSeasonsByGroupID(groupID int64) ([]*core.Season, error) {
+	return s.querySeasons("SELECT "+seasonColumns+" FROM seasons WHERE group_id = ? ORDER BY starts_at DESC", groupID)
+}
+
+// GetSeasonsToClose retrieves the seasons that ended but were not closed yet
+func (s *Store) GetSeasonsToClose(now time.Time) ([]*core.Season, error) {
+	return s.querySeasons("SELECT "+seasonColumns+" FROM seasons WHERE closed_at IS NULL AND ends_at <= ? ORDER BY ends_at ASC", now.UTC())
+}
+
+// querySeasons runs a query selecting seasonColumns and scans every row
+func (s *Store) querySeasons(query string, args ...interface{}) ([]*core.Season, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seasons: %w", err)
+	}
+	defer rows.Close()
+
+	var seasons []*core.Season
+	for rows.Next() {
+		season, err := scanSeason(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan season: %w", err)
+		}
+		seasons = append(seasons, season)
+	}
+
+	return seasons, nil
+}
+
+// SetSeasonEnd moves the end of a season, e.g. to end it early
+func (s *Store) SetSeasonEnd(id int64, endsAt time.Time) error {
+	_, err := s.DB.Exec("UPDATE seasons SET ends_at = ? WHERE id = ?", endsAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update season: %w", err)
+	}
+	return nil
+}
+
+// DeleteSeason deletes a season and its standings
+func (s *Store) DeleteSeason(id int64) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM season_standings WHERE season_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete season standings: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM seasons WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete season: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// CloseSeason archives the final standings of a season. A season is closed
+// at most once, so a second call leaves the first standings in place.
+func (s *Store) CloseSeason(seasonID int64, standings []*core.SeasonStanding, closedAt time.Time) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE seasons SET closed_at = ? WHERE id = ? AND closed_at IS NULL", closedAt.UTC(), seasonID)
+	if err != nil {
+		return fmt.Errorf("failed to close season: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err != nil || updated == 0 {
+		return err
+	}
+
+	for _, standing := range standings {
+		_, err := tx.Exec(
+			"INSERT INTO season_standings (season_id, user_id, rank, earned, completions, badge) VALUES (?, ?, ?, ?, ?, ?)",
+			seasonID, standing.UserID, standing.Rank, standing.Earned, standing.Completions, standing.Badge,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store season standing: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// seasonStandingQuery selects standings with the season and member names
+const seasonStandingQuery = `
+	SELECT ss.season_id, se.name, ss.user_id, u.username, ss.rank, ss.earned, ss.completions, ss.badge
+	FROM season_standings ss
+	JOIN seasons se ON se.id = ss.season_id
+	JOIN users u ON u.id = ss.user_id`
+
+// GetSeasonStandings retrieves the final standings of a season, best first
+func (s *Store) GetSeasonStandings(seasonID int64) ([]*core.SeasonStanding, error) {
+	return s.querySeasonStandings(seasonStandingQuery+" WHERE ss.season_id = ? ORDER BY ss.rank, u.username", seasonID)
+}
+
+// GetSeasonBadges retrieves the badges won in a group's seasons, oldest first
+func (s *Store) GetSeasonBadges(groupID int64) ([]*core.SeasonStanding, error) {
+	return s.querySeasonStandings(seasonStandingQuery+" WHERE se.group_id = ? AND ss.badge != '' ORDER BY se.ends_at, ss.rank", groupID)
+}
+
+// querySeasonStandings runs a query selecting like seasonStandingQuery
+func (s *Store) querySeasonStandings(query string, args ...interface{}) ([]*core.SeasonStanding, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query season standings: %w", err)
+	}
+	defer rows.Close()
+
+	var standings []*core.SeasonStanding
+	for rows.Next() {
+		st := &core.SeasonStanding{}
+		if err := rows.Scan(&st.SeasonID, &st.SeasonName, &st.UserID, &st.Username, &st.Rank, &st.Earned, &st.Completions, &st.Badge); err != nil {
+			return nil, fmt.Errorf("failed to scan season standing: %w", err)
+		}
+		standings = append(standings, st)
+	}
+
+	return standings, nil
+}
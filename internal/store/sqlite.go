@@ -3,6 +3,8 @@ package store
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -11,11 +13,18 @@ import (
 type Store struct {
 	DB        *sql.DB
 	undoCache *undoCache
+	fts       bool // SQLite has FTS5, see migrateSearch
 }
 
 // NewStore creates a new Store and initializes the database
 func NewStore(dbPath string) (*Store, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	// Wait for locks instead of failing: the notification worker writes
+	// from several goroutines at once
+	dsn := dbPath
+	if !strings.Contains(dsn, "?") {
+		dsn += "?_busy_timeout=5000"
+	}
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -94,7 +103,9 @@ func (s *Store) migrate() error {
 		user_id INTEGER,
 		group_id INTEGER,
 		amount INTEGER NOT NULL,
-		source_type TEXT CHECK(source_type IN ('task', 'shop_item', 'manual')),
+		currency_id INTEGER DEFAULT 0,
+		parent_transaction_id INTEGER,
+		source_type TEXT NOT NULL,
 		source_id INTEGER,
 		quantity INTEGER DEFAULT 1,
 		description TEXT,
@@ -177,6 +188,91 @@ func (s *Store) migrate() error {
 		return fmt.Errorf("failed to migrate group owner column: %w", err)
 	}
 
+	if err := s.migrateGroupArchive(); err != nil {
+		return fmt.Errorf("failed to migrate group archive columns: %w", err)
+	}
+
+	if err := s.migrateCurrencies(); err != nil {
+		return fmt.Errorf("failed to migrate currencies: %w", err)
+	}
+
+	if err := s.migrateWishlist(); err != nil {
+		return fmt.Errorf("failed to migrate wishlist table: %w", err)
+	}
+
+	if err := s.migrateScheduledGrants(); err != nil {
+		return fmt.Errorf("failed to migrate scheduled grant tables: %w", err)
+	}
+
+	if err := s.migrateTaskPenalties(); err != nil {
+		return fmt.Errorf("failed to migrate task penalty columns: %w", err)
+	}
+
+	if err := s.migratePauses(); err != nil {
+		return fmt.Errorf("failed to migrate pauses table: %w", err)
+	}
+
+	if err := s.migrateAvailableFrom(); err != nil {
+		return fmt.Errorf("failed to migrate available_from column: %w", err)
+	}
+
+	if err := s.migrateUserTimezone(); err != nil {
+		return fmt.Errorf("failed to migrate user timezone column: %w", err)
+	}
+
+	if err := s.migrateQuietHours(); err != nil {
+		return fmt.Errorf("failed to migrate quiet hours: %w", err)
+	}
+
+	if err := s.migrateNotificationDelivery(); err != nil {
+		return fmt.Errorf("failed to migrate notification delivery columns: %w", err)
+	}
+
+	if err := s.migrateNotificationChannels(); err != nil {
+		return fmt.Errorf("failed to migrate notification channel tables: %w", err)
+	}
+
+	if err := s.migrateNagReminders(); err != nil {
+		return fmt.Errorf("failed to migrate nag reminders: %w", err)
+	}
+
+	if err := s.migrateReminderOffsets(); err != nil {
+		return fmt.Errorf("failed to migrate reminder offsets: %w", err)
+	}
+
+	if err := s.migrateDigests(); err != nil {
+		return fmt.Errorf("failed to migrate digests: %w", err)
+	}
+
+	if err := s.migrateReports(); err != nil {
+		return fmt.Errorf("failed to migrate reports: %w", err)
+	}
+
+	if err := s.migrateSeasons(); err != nil {
+		return fmt.Errorf("failed to migrate seasons: %w", err)
+	}
+
+	if err := s.migrateEconomyTargets(); err != nil {
+		return fmt.Errorf("failed to migrate economy targets: %w", err)
+	}
+
+	if err := s.migrateLogIndexes(); err != nil {
+		return fmt.Errorf("failed to migrate log indexes: %w", err)
+	}
+
+	if err := s.migrateSearch(); err != nil {
+		return fmt.Errorf("failed to migrate search: %w", err)
+	}
+
+	if err := s.migrateTaskSnoozes(); err != nil {
+		return fmt.Errorf("failed to migrate task snoozes: %w", err)
+	}
+
+	// Last, so it sees the columns added by every migration above
+	if err := s.migrateUTCTimes(); err != nil {
+		return fmt.Errorf("failed to migrate times to UTC: %w", err)
+	}
+
 	return nil
 }
 
@@ -198,6 +294,723 @@ func (s *Store) migrateGroupOwner() error {
 	return nil
 }
 
+// migrateGroupArchive adds archive/deletion columns to groups and the ledger export table
+func (s *Store) migrateGroupArchive() error {
+	columns := map[string]string{
+		"archived_at":   `ALTER TABLE groups ADD COLUMN archived_at DATETIME`,
+		"delete_after":  `ALTER TABLE groups ADD COLUMN delete_after DATETIME`,
+		"retain_ledger": `ALTER TABLE groups ADD COLUMN retain_ledger BOOLEAN DEFAULT 0`,
+	}
+	for column, stmt := range columns {
+		_, err := s.DB.Exec(stmt)
+		if err != nil && err.Error() != "duplicate column name: "+column {
+			return err
+		}
+	}
+
+	_, err := s.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS group_ledger_exports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		group_id INTEGER NOT NULL,
+		group_name TEXT NOT NULL,
+		owner_id INTEGER NOT NULL,
+		data TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create group_ledger_exports table: %w", err)
+	}
+
+	return nil
+}
+
+// migrateCurrencies adds currency columns to transactions and the currency tables
+func (s *Store) migrateCurrencies() error {
+	columns := map[string]string{
+		"currency_id":           `ALTER TABLE transactions ADD COLUMN currency_id INTEGER DEFAULT 0`,
+		"parent_transaction_id": `ALTER TABLE transactions ADD COLUMN parent_transaction_id INTEGER`,
+	}
+	for column, stmt := range columns {
+		_, err := s.DB.Exec(stmt)
+		if err != nil && err.Error() != "duplicate column name: "+column {
+			return err
+		}
+	}
+
+	if err := s.rebuildTransactionsWithoutSourceCheck(); err != nil {
+		return err
+	}
+
+	_, err := s.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS currencies (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		group_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		emoji TEXT NOT NULL,
+		labels TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(group_id) REFERENCES groups(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS task_rewards (
+		task_id INTEGER NOT NULL,
+		currency_id INTEGER NOT NULL,
+		amount INTEGER NOT NULL,
+		PRIMARY KEY (task_id, currency_id),
+		FOREIGN KEY(currency_id) REFERENCES currencies(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS shop_item_prices (
+		shop_item_id INTEGER NOT NULL,
+		currency_id INTEGER NOT NULL,
+		amount INTEGER NOT NULL,
+		PRIMARY KEY (shop_item_id, currency_id),
+		FOREIGN KEY(currency_id) REFERENCES currencies(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS currency_exchange_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		group_id INTEGER NOT NULL,
+		from_currency_id INTEGER NOT NULL,
+		from_amount INTEGER NOT NULL,
+		to_currency_id INTEGER NOT NULL,
+		to_amount INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(group_id) REFERENCES groups(id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_transactions_parent
+	ON transactions(parent_transaction_id)
+	WHERE parent_transaction_id IS NOT NULL;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create currency tables: %w", err)
+	}
+
+	return nil
+}
+
+// migrateWishlist creates the wishlist_items table if it doesn't exist
+func (s *Store) migrateWishlist() error {
+	_, err := s.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS wishlist_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		group_id INTEGER NOT NULL,
+		shop_item_id INTEGER NOT NULL,
+		reserved INTEGER DEFAULT 0,
+		notified_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(user_id, shop_item_id),
+		FOREIGN KEY(user_id) REFERENCES users(id),
+		FOREIGN KEY(group_id) REFERENCES groups(id),
+		FOREIGN KEY(shop_item_id) REFERENCES shop_items(id)
+	);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create wishlist_items table: %w", err)
+	}
+
+	return nil
+}
+
+// migrateScheduledGrants creates the scheduled_grants and grant_runs tables if they don't exist
+func (s *Store) migrateScheduledGrants() error {
+	_, err := s.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS scheduled_grants (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		group_id INTEGER NOT NULL,
+		created_by INTEGER NOT NULL,
+		kind TEXT NOT NULL CHECK(kind IN ('fixed', 'interest')),
+		currency_id INTEGER NOT NULL DEFAULT 0,
+		amount INTEGER NOT NULL DEFAULT 0,
+		rate_percent INTEGER NOT NULL DEFAULT 0,
+		schedule TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		member_ids TEXT NOT NULL DEFAULT '[]',
+		paused INTEGER NOT NULL DEFAULT 0,
+		last_run_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(group_id) REFERENCES groups(id),
+		FOREIGN KEY(created_by) REFERENCES users(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS grant_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		grant_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		run_at DATETIME NOT NULL,
+		transaction_id INTEGER,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(grant_id, user_id, run_at),
+		FOREIGN KEY(grant_id) REFERENCES scheduled_grants(id),
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create scheduled grant tables: %w", err)
+	}
+
+	return nil
+}
+
+// migrateTaskPenalties adds deadline policy columns to tasks and the task_penalties table
+func (s *Store) migrateTaskPenalties() error {
+	columns := map[string]string{
+		"penalty_amount":        `ALTER TABLE tasks ADD COLUMN penalty_amount INTEGER NOT NULL DEFAULT 0`,
+		"late_reward_percent":   `ALTER TABLE tasks ADD COLUMN late_reward_percent INTEGER NOT NULL DEFAULT 100`,
+		"penalty_grace_minutes": `ALTER TABLE tasks ADD COLUMN penalty_grace_minutes INTEGER NOT NULL DEFAULT 0`,
+		"deadline_evaluated_at": `ALTER TABLE tasks ADD COLUMN deadline_evaluated_at DATETIME`,
+	}
+	for column, stmt := range columns {
+		_, err := s.DB.Exec(stmt)
+		if err != nil && err.Error() != "duplicate column name: "+column {
+			return err
+		}
+	}
+
+	_, err := s.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS task_penalties (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		due_at DATETIME NOT NULL,
+		transaction_id INTEGER,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(task_id, user_id, due_at),
+		FOREIGN KEY(task_id) REFERENCES tasks(id),
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create task_penalties table: %w", err)
+	}
+
+	return nil
+}
+
+// migratePauses creates the pauses table if it doesn't exist
+func (s *Store) migratePauses() error {
+	_, err := s.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS pauses (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER,
+		group_id INTEGER,
+		starts_at DATETIME NOT NULL,
+		ends_at DATETIME NOT NULL,
+		reason TEXT DEFAULT '',
+		resumed_at DATETIME,
+		created_by INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		CHECK((user_id IS NULL) != (group_id IS NULL)),
+		FOREIGN KEY(user_id) REFERENCES users(id),
+		FOREIGN KEY(group_id) REFERENCES groups(id)
+	);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create pauses table: %w", err)
+	}
+
+	return nil
+}
+
+// migrateAvailableFrom adds the available_from column to tasks if it doesn't exist
+func (s *Store) migrateAvailableFrom() error {
+	_, err := s.DB.Exec(`ALTER TABLE tasks ADD COLUMN available_from DATETIME`)
+	if err != nil && err.Error() != "duplicate column name: available_from" {
+		return err
+	}
+	return nil
+}
+
+// migrateUserTimezone adds the timezone column to users if it doesn't exist
+func (s *Store) migrateUserTimezone() error {
+	_, err := s.DB.Exec(`ALTER TABLE users ADD COLUMN timezone TEXT NOT NULL DEFAULT ''`)
+	if err != nil && err.Error() != "duplicate column name: timezone" {
+		return err
+	}
+	return nil
+}
+
+// migrateUTCTimes rewrites times saved with a local offset, such as
+// "2025-12-02 05:13:22.630448+03:00", to UTC. Queries compare DATETIME
+// columns as text against UTC arguments, which only works when every stored
+// time is UTC. Times already in UTC and CURRENT_TIMESTAMP defaults are left
+// alone, so this is a no-op once done.
+func (s *Store) migrateUTCTimes() error {
+	type column struct{ table, name string }
+	var columns []column
+
+	tables, err := s.DB.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND sql NOT LIKE 'CREATE VIRTUAL%'`)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for tables.Next() {
+		var name string
+		if err := tables.Scan(&name); err != nil {
+			tables.Close()
+			return err
+		}
+		names = append(names, name)
+	}
+	tables.Close()
+	if err := tables.Err(); err != nil {
+		return err
+	}
+	for _, table := range names {
+		info, err := s.DB.Query(fmt.Sprintf(`SELECT name, type FROM pragma_table_info('%s')`, table))
+		if err != nil {
+			return err
+		}
+		for info.Next() {
+			var name, typ string
+			if err := info.Scan(&name, &typ); err != nil {
+				info.Close()
+				return err
+			}
+			if strings.EqualFold(typ, "DATETIME") {
+				columns = append(columns, column{table, name})
+			}
+		}
+		info.Close()
+		if err := info.Err(); err != nil {
+			return err
+		}
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, c := range columns {
+		type update struct {
+			rowid int64
+			at    time.Time
+		}
+		var updates []update
+		rows, err := tx.Query(fmt.Sprintf(`
+			SELECT rowid, %[2]s FROM %[1]s
+			WHERE typeof(%[2]s) = 'text' AND %[2]s GLOB '*[+-][0-9][0-9]:[0-9][0-9]'
+				AND %[2]s NOT LIKE '%%+00:00' AND %[2]s NOT LIKE '%%-00:00'`, c.table, c.name))
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var rowid int64
+			var value string
+			if err := rows.Scan(&rowid, &value); err != nil {
+				rows.Close()
+				return err
+			}
+			at, err := parseStoredTime(value)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("%s.%s of row %d: %w", c.table, c.name, rowid, err)
+			}
+			updates = append(updates, update{rowid, at.UTC()})
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, u := range updates {
+			if _, err := tx.Exec(fmt.Sprintf(`UPDATE %s SET %s = ? WHERE rowid = ?`, c.table, c.name), u.at, u.rowid); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// parseStoredTime reads a time with an offset as the SQLite driver writes it,
+// or in RFC 3339
+func parseStoredTime(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999-07:00", "2006-01-02T15:04:05.999999999-07:00"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", value)
+}
+
+// migrateQuietHours adds quiet hours to notification_settings and the urgent flag to tasks
+func (s *Store) migrateQuietHours() error {
+	columns := []struct{ name, stmt string }{
+		{"quiet_start", `ALTER TABLE notification_settings ADD COLUMN quiet_start INTEGER NOT NULL DEFAULT 0`},
+		{"quiet_end", `ALTER TABLE notification_settings ADD COLUMN quiet_end INTEGER NOT NULL DEFAULT 0`},
+		{"quiet_days", `ALTER TABLE notification_settings ADD COLUMN quiet_days TEXT NOT NULL DEFAULT ''`},
+		{"urgent_during_quiet", `ALTER TABLE notification_settings ADD COLUMN urgent_during_quiet BOOLEAN NOT NULL DEFAULT 0`},
+		{"is_urgent", `ALTER TABLE tasks ADD COLUMN is_urgent BOOLEAN NOT NULL DEFAULT 0`},
+	}
+	for _, column := range columns {
+		_, err := s.DB.Exec(column.stmt)
+		if err != nil && err.Error() != "duplicate column name: "+column.name {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateNotificationDelivery adds retry bookkeeping to task_notifications
+func (s *Store) migrateNotificationDelivery() error {
+	columns := []struct{ name, stmt string }{
+		{"attempts", `ALTER TABLE task_notifications ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0`},
+		{"last_error", `ALTER TABLE task_notifications ADD COLUMN last_error TEXT NOT NULL DEFAULT ''`},
+		{"next_attempt_at", `ALTER TABLE task_notifications ADD COLUMN next_attempt_at DATETIME`},
+		{"failed_at", `ALTER TABLE task_notifications ADD COLUMN failed_at DATETIME`},
+	}
+	for _, column := range columns {
+		_, err := s.DB.Exec(column.stmt)
+		if err != nil && err.Error() != "duplicate column name: "+column.name {
+			return err
+		}
+	}
+
+	_, err := s.DB.Exec(`CREATE INDEX IF NOT EXISTS idx_task_notifications_failed ON task_notifications(failed_at) WHERE failed_at IS NOT NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to create failed notifications index: %w", err)
+	}
+	return nil
+}
+
+// migrateNotificationChannels creates the per-user channel preferences and
+// the Web Push subscriptions
+func (s *Store) migrateNotificationChannels() error {
+	_, err := s.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS notification_channels (
+		user_id INTEGER NOT NULL,
+		channel TEXT NOT NULL,
+		enabled BOOLEAN NOT NULL DEFAULT 0,
+		address TEXT NOT NULL DEFAULT '',
+		priority INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(user_id, channel),
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS push_subscriptions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		endpoint TEXT NOT NULL UNIQUE,
+		p256dh TEXT NOT NULL,
+		auth TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_push_subscriptions_user ON push_subscriptions(user_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create notification channel tables: %w", err)
+	}
+	return nil
+}
+
+// migrateNagReminders adds the escalation policy to tasks and the stage of
+// escalating reminders to task_notifications
+func (s *Store) migrateNagReminders() error {
+	columns := []struct{ name, stmt string }{
+		{"nag_interval_minutes", `ALTER TABLE tasks ADD COLUMN nag_interval_minutes INTEGER NOT NULL DEFAULT 0`},
+		{"nag_escalate_after", `ALTER TABLE tasks ADD COLUMN nag_escalate_after INTEGER NOT NULL DEFAULT 0`},
+		{"nag_partner_id", `ALTER TABLE tasks ADD COLUMN nag_partner_id INTEGER REFERENCES users(id)`},
+		{"stage", `ALTER TABLE task_notifications ADD COLUMN stage INTEGER NOT NULL DEFAULT 0`},
+		{"about_user_id", `ALTER TABLE task_notifications ADD COLUMN about_user_id INTEGER REFERENCES users(id)`},
+	}
+	for _, column := range columns {
+		_, err := s.DB.Exec(column.stmt)
+		if err != nil && err.Error() != "duplicate column name: "+column.name {
+			return err
+		}
+	}
+
+	return s.rebuildTaskNotificationsWithoutTypeCheck()
+}
+
+// migrateReminderOffsets adds per-task reminder offsets and the table of
+// members who opted out of a task's reminders
+func (s *Store) migrateReminderOffsets() error {
+	_, err := s.DB.Exec(`ALTER TABLE tasks ADD COLUMN reminder_offsets TEXT NOT NULL DEFAULT ''`)
+	if err != nil && err.Error() != "duplicate column name: reminder_offsets" {
+		return err
+	}
+
+	_, err = s.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS task_reminder_optouts (
+		task_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(task_id, user_id),
+		FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_task_reminder_optouts_user ON task_reminder_optouts(user_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create task_reminder_optouts table: %w", err)
+	}
+	return nil
+}
+
+// migrateTaskSnoozes creates the table of tasks members hid from themselves
+// until later
+func (s *Store) migrateTaskSnoozes() error {
+	_, err := s.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS task_snoozes (
+		task_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		until DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(task_id, user_id),
+		FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_task_snoozes_user ON task_snoozes(user_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create task_snoozes table: %w", err)
+	}
+	return nil
+}
+
+// migrateDigests adds the digest times to notification_settings and the table
+// recording which digests were sent
+func (s *Store) migrateDigests() error {
+	columns := []struct{ name, stmt string }{
+		{"morning_digest", `ALTER TABLE notification_settings ADD COLUMN morning_digest INTEGER`},
+		{"evening_digest", `ALTER TABLE notification_settings ADD COLUMN evening_digest INTEGER`},
+	}
+	for _, column := range columns {
+		_, err := s.DB.Exec(column.stmt)
+		if err != nil && err.Error() != "duplicate column name: "+column.name {
+			return err
+		}
+	}
+
+	_, err := s.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS digest_runs (
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		day TEXT NOT NULL,
+		sent_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(user_id, kind, day),
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create digest_runs table: %w", err)
+	}
+	return nil
+}
+
+// migrateReports adds the report schedule to notification_settings, the record
+// of missed deadlines and the index the report queries use
+func (s *Store) migrateReports() error {
+	_, err := s.DB.Exec(`ALTER TABLE notification_settings ADD COLUMN report_period TEXT NOT NULL DEFAULT ''`)
+	if err != nil && err.Error() != "duplicate column name: report_period" {
+		return err
+	}
+
+	_, err = s.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS missed_deadlines (
+		task_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		group_id INTEGER NOT NULL,
+		due_at DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(task_id, user_id, due_at),
+		FOREIGN KEY(task_id) REFERENCES tasks(id),
+		FOREIGN KEY(user_id) REFERENCES users(id),
+		FOREIGN KEY(group_id) REFERENCES groups(id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_missed_deadlines_group ON missed_deadlines(group_id, due_at);
+	CREATE INDEX IF NOT EXISTS idx_transactions_group_created ON transactions(group_id, created_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create missed_deadlines table: %w", err)
+	}
+	return nil
+}
+
+// migrateSeasons creates the leaderboard seasons of groups and their archived
+// final standings
+func (s *Store) migrateSeasons() error {
+	_, err := s.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS seasons (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		group_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		starts_at DATETIME NOT NULL,
+		ends_at DATETIME NOT NULL,
+		award_badges BOOLEAN NOT NULL DEFAULT 0,
+		closed_at DATETIME,
+		created_by INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(group_id) REFERENCES groups(id)
+	);
+
+	CREATE TABLE IF NOT EXISTS season_standings (
+		season_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		rank INTEGER NOT NULL,
+		earned INTEGER NOT NULL,
+		completions INTEGER NOT NULL,
+		badge TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY(season_id, user_id),
+		FOREIGN KEY(season_id) REFERENCES seasons(id),
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create seasons tables: %w", err)
+	}
+	return nil
+}
+
+// migrateEconomyTargets creates the table of owners' economy goals per group
+func (s *Store) migrateEconomyTargets() error {
+	_, err := s.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS economy_targets (
+		group_id INTEGER PRIMARY KEY,
+		work_days INTEGER NOT NULL,
+		spend_percent INTEGER NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(group_id) REFERENCES groups(id)
+	);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create economy_targets table: %w", err)
+	}
+	return nil
+}
+
+// migrateLogIndexes indexes transactions and purchases for paging through a
+// member's or a whole group's log
+func (s *Store) migrateLogIndexes() error {
+	_, err := s.DB.Exec(`
+	CREATE INDEX IF NOT EXISTS idx_transactions_group_user_created ON transactions(group_id, user_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_purchases_group_user_created ON purchases(group_id, user_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_purchases_group_created ON purchases(group_id, created_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create log indexes: %w", err)
+	}
+	return nil
+}
+
+// rebuildTaskNotificationsWithoutTypeCheck recreates task_notifications without
+// the CHECK on notification_type, like rebuildTransactionsWithoutSourceCheck does
+// for transactions. Notification types are validated by the service layer.
+func (s *Store) rebuildTaskNotificationsWithoutTypeCheck() error {
+	var tableSQL string
+	err := s.DB.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'task_notifications'`).Scan(&tableSQL)
+	if err != nil {
+		return fmt.Errorf("failed to read task_notifications schema: %w", err)
+	}
+	if !strings.Contains(tableSQL, "CHECK(notification_type") {
+		return nil
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	steps := []string{
+		`CREATE TABLE task_notifications_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			notification_type TEXT NOT NULL,
+			scheduled_at DATETIME NOT NULL,
+			sent_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			next_attempt_at DATETIME,
+			failed_at DATETIME,
+			stage INTEGER NOT NULL DEFAULT 0,
+			about_user_id INTEGER,
+			FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+			FOREIGN KEY(user_id) REFERENCES users(id),
+			FOREIGN KEY(about_user_id) REFERENCES users(id)
+		)`,
+		`INSERT INTO task_notifications_new (id, task_id, user_id, notification_type, scheduled_at, sent_at, created_at,
+			attempts, last_error, next_attempt_at, failed_at, stage, about_user_id)
+		 SELECT id, task_id, user_id, notification_type, scheduled_at, sent_at, created_at,
+			attempts, last_error, next_attempt_at, failed_at, stage, about_user_id FROM task_notifications`,
+		`DROP TABLE task_notifications`,
+		`ALTER TABLE task_notifications_new RENAME TO task_notifications`,
+		`CREATE INDEX IF NOT EXISTS idx_task_notifications_scheduled_pending ON task_notifications(scheduled_at) WHERE sent_at IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_task_notifications_failed ON task_notifications(failed_at) WHERE failed_at IS NOT NULL`,
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(step); err != nil {
+			return fmt.Errorf("failed to rebuild task_notifications table: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// rebuildTransactionsWithoutSourceCheck recreates the transactions table without the
+// old CHECK on source_type, which SQLite cannot drop with ALTER TABLE. Validation of
+// source types lives in the service layer, so new sources don't need a rebuild.
+func (s *Store) rebuildTransactionsWithoutSourceCheck() error {
+	var tableSQL string
+	err := s.DB.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'transactions'`).Scan(&tableSQL)
+	if err != nil {
+		return fmt.Errorf("failed to read transactions schema: %w", err)
+	}
+	if !strings.Contains(tableSQL, "CHECK(source_type") {
+		return nil
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	steps := []string{
+		`CREATE TABLE transactions_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			group_id INTEGER,
+			amount INTEGER NOT NULL,
+			currency_id INTEGER DEFAULT 0,
+			parent_transaction_id INTEGER,
+			source_type TEXT NOT NULL,
+			source_id INTEGER,
+			quantity INTEGER DEFAULT 1,
+			description TEXT,
+			notes TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY(user_id) REFERENCES users(id),
+			FOREIGN KEY(group_id) REFERENCES groups(id)
+		)`,
+		`INSERT INTO transactions_new (id, user_id, group_id, amount, currency_id, parent_transaction_id, source_type, source_id, quantity, description, notes, created_at)
+		 SELECT id, user_id, group_id, amount, COALESCE(currency_id, 0), parent_transaction_id, source_type, source_id, quantity, description, notes, created_at FROM transactions`,
+		`DROP TABLE transactions`,
+		`ALTER TABLE transactions_new RENAME TO transactions`,
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(step); err != nil {
+			return fmt.Errorf("failed to rebuild transactions table: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
 // migrateDefaultQuantity adds default_quantity column to tasks table if it doesn't exist
 func (s *Store) migrateDefaultQuantity() error {
 	// Try to add default_quantity column to tasks table
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"fmt"
+	"small-rpg-adhd-monolith/internal/core"
+	"time"
+)
+
+// statsBucketColumn is the SQL expression of the local day or week (from
+// Monday) a timestamp column falls in. It takes the localDayModifier argument.
+func statsBucketColumn(interval, column string) string {
+	if interval == core.StatsWeekly {
+		return "date(" + column + ", ?, 'weekday 0', '-6 days')"
+	}
+	return "date(" + column + ", ?)"
+}
+
+// GetStatsBuckets sums each member's completions, cheese earned with tasks and
+// spent in the shop per bucket in [since, until)
+func (s *Store) GetStatsBuckets(groupID int64, interval string, since, until time.Time) ([]*core.StatsBucket, error) {
+	rows, err := s.DB.Query(`
+		SELECT user_id, `+statsBucketColumn(interval, "created_at")+` AS bucket,
+			COALESCE(SUM(CASE WHEN source_type = ? THEN (CASE WHEN amount < 0 THEN -1 ELSE 1 END) END), 0),
+			COALESCE(SUM(CASE WHEN source_type = ? THEN amount END), 0),
+			COALESCE(-SUM(CASE WHEN source_type = ? THEN amount END), 0)
+		FROM transactions
+		WHERE group_id = ? AND currency_id = ? AND parent_transaction_id IS NULL
+			AND created_at >= ? AND created_at < ?
+		GROUP BY user_id, bucket
+		ORDER BY bucket`,
+		localDayModifier(since), string(core.SourceTypeTask), string(core.SourceTypeTask), string(core.SourceTypeShopItem),
+		groupID, core.DefaultCurrencyID, timestampArg(since), timestampArg(until),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stats: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []*core.StatsBucket
+	for rows.Next() {
+		b := &core.StatsBucket{}
+		var start string
+		if err := rows.Scan(&b.UserID, &start, &b.Completions, &b.Earned, &b.Spent); err != nil {
+			return nil, fmt.Errorf("failed to scan stats: %w", err)
+		}
+		b.Start, err = time.ParseInLocation("2006-01-02", start, since.Location())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse stats bucket %q: %w", start, err)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, nil
+}
+
+// GetQuantityStats sums the units done of each integer task per bucket in
+// [since, until)
+func (s *Store) GetQuantityStats(groupID int64, interval string, since, until time.Time) ([]*core.StatsQuantity, error) {
+	rows, err := s.DB.Query(`
+		SELECT t.id, t.title, `+statsBucketColumn(interval, "tr.created_at")+` AS bucket,
+			SUM(CASE WHEN tr.amount < 0 THEN -tr.quantity ELSE tr.quantity END)
+		FROM transactions tr
+		JOIN tasks t ON t.id = tr.source_id
+		WHERE tr.group_id = ? AND tr.source_type = ? AND tr.parent_transaction_id IS NULL AND t.task_type = ?
+			AND tr.created_at >= ? AND tr.created_at < ?
+		GROUP BY t.id, bucket
+		ORDER BY bucket`,
+		localDayModifier(since), groupID, string(core.SourceTypeTask), string(core.TaskTypeInteger),
+		timestampArg(since), timestampArg(until),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quantity stats: %w", err)
+	}
+	defer rows.Close()
+
+	var quantities []*core.StatsQuantity
+	for rows.Next() {
+		q := &core.StatsQuantity{}
+		var start string
+		if err := rows.Scan(&q.TaskID, &q.Title, &start, &q.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan quantity stats: %w", err)
+		}
+		q.Start, err = time.ParseInLocation("2006-01-02", start, since.Location())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse stats bucket %q: %w", start, err)
+		}
+		quantities = append(quantities, q)
+	}
+	return quantities, nil
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"small-rpg-adhd-monolith/internal/core"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -139,34 +141,101 @@ func (c *undoCache) removeShopItem(id int64) {
 	delete(c.shopItems, id)
 }
 
-// CreateTask creates a new task in a group
-func (s *Store) CreateTask(groupID int64, title, description string, taskType core.TaskType, rewardValue int, defaultQuantity int, isOneTime bool) (*core.Task, error) {
-	result, err := s.DB.Exec(
+// taskColumns is the column list used by scanTask
+const taskColumns = "id, group_id, title, description, task_type, reward_value, default_quantity, is_one_time, due_at, " +
+	"penalty_amount, late_reward_percent, penalty_grace_minutes, deadline_evaluated_at, available_from, is_urgent, " +
+	"nag_interval_minutes, nag_escalate_after, nag_partner_id, reminder_offsets, created_at"
+
+// scanTask scans a row selected with taskColumns
+func scanTask(row rowScanner) (*core.Task, error) {
+	task := &core.Task{}
+	var taskType string
+	var dueAt, evaluatedAt, availableFrom sql.NullTime
+	var nagPartnerID sql.NullInt64
+	var reminderOffsets string
+
+	if err := row.Scan(&task.ID, &task.GroupID, &task.Title, &task.Description, &taskType, &task.RewardValue,
+		&task.DefaultQuantity, &task.IsOneTime, &dueAt, &task.Deadline.PenaltyAmount, &task.Deadline.LateRewardPercent,
+		&task.Deadline.GraceMinutes, &evaluatedAt, &availableFrom, &task.Deadline.Urgent,
+		&task.Deadline.NagIntervalMinutes, &task.Deadline.NagEscalateAfter, &nagPartnerID, &reminderOffsets,
+		&task.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	task.TaskType = core.TaskType(taskType)
+	if dueAt.Valid {
+		task.DueAt = &dueAt.Time
+	}
+	if evaluatedAt.Valid {
+		task.DeadlineEvaluatedAt = &evaluatedAt.Time
+	}
+	if availableFrom.Valid {
+		task.AvailableFrom = &availableFrom.Time
+	}
+	if nagPartnerID.Valid {
+		task.Deadline.NagPartnerID = &nagPartnerID.Int64
+	}
+	task.Deadline.ReminderOffsets = decodeReminderOffsets(reminderOffsets)
+	return task, nil
+}
+
+// encodeReminderOffsets stores reminder offsets as comma-separated minutes
+func encodeReminderOffsets(offsets []int) string {
+	parts := make([]string, len(offsets))
+	for i, minutes := range offsets {
+		parts[i] = strconv.Itoa(minutes)
+	}
+	return strings.Join(parts, ",")
+}
+
+// decodeReminderOffsets reads offsets written by encodeReminderOffsets
+func decodeReminderOffsets(value string) []int {
+	var offsets []int
+	for _, part := range strings.Split(value, ",") {
+		if minutes, err := strconv.Atoi(part); err == nil {
+			offsets = append(offsets, minutes)
+		}
+	}
+	return offsets
+}
+
+// nullableTime converts an optional time to a UTC column value
+func nullableTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// CreateTask creates a new task in a group; dueAt may be nil for tasks without a deadline
+func (s *Store) CreateTask(groupID int64, title, description string, taskType core.TaskType, rewardValue int, defaultQuantity int, isOneTime bool, dueAt *time.Time) (*core.Task, error) {
+	id, err := insertTask(s.DB, groupID, title, description, taskType, rewardValue, defaultQuantity, isOneTime, dueAt)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTaskByID(id)
+}
+
+// insertTask inserts a task and returns its ID
+func insertTask(q execer, groupID int64, title, description string, taskType core.TaskType, rewardValue int, defaultQuantity int, isOneTime bool, dueAt *time.Time) (int64, error) {
+	result, err := q.Exec(
 		"INSERT INTO tasks (group_id, title, description, task_type, reward_value, default_quantity, is_one_time, due_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
-		groupID, title, description, string(taskType), rewardValue, defaultQuantity, isOneTime, nil,
+		groupID, title, description, string(taskType), rewardValue, defaultQuantity, isOneTime, nullableTime(dueAt),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create task: %w", err)
+		return 0, fmt.Errorf("failed to create task: %w", err)
 	}
 
 	id, err := result.LastInsertId()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
 	}
-
-	return s.GetTaskByID(id)
+	return id, nil
 }
 
 // GetTaskByID retrieves a task by ID
 func (s *Store) GetTaskByID(id int64) (*core.Task, error) {
-	task := &core.Task{}
-	var taskType string
-
-	err := s.DB.QueryRow(
-		"SELECT id, group_id, title, description, task_type, reward_value, default_quantity, is_one_time, created_at FROM tasks WHERE id = ?",
-		id,
-	).Scan(&task.ID, &task.GroupID, &task.Title, &task.Description, &taskType, &task.RewardValue, &task.DefaultQuantity, &task.IsOneTime, &task.CreatedAt)
-
+	task, err := scanTask(s.DB.QueryRow("SELECT "+taskColumns+" FROM tasks WHERE id = ?", id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("task not found")
@@ -174,16 +243,17 @@ func (s *Store) GetTaskByID(id int64) (*core.Task, error) {
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
-	task.TaskType = core.TaskType(taskType)
 	return task, nil
 }
 
 // GetTasksByGroupID retrieves all tasks for a group
 func (s *Store) GetTasksByGroupID(groupID int64) ([]*core.Task, error) {
-	rows, err := s.DB.Query(
-		"SELECT id, group_id, title, description, task_type, reward_value, default_quantity, is_one_time, created_at FROM tasks WHERE group_id = ?",
-		groupID,
-	)
+	return s.queryTasks("SELECT "+taskColumns+" FROM tasks WHERE group_id = ?", groupID)
+}
+
+// queryTasks runs a query selecting taskColumns and scans every row
+func (s *Store) queryTasks(query string, args ...interface{}) ([]*core.Task, error) {
+	rows, err := s.DB.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query tasks: %w", err)
 	}
@@ -191,36 +261,179 @@ func (s *Store) GetTasksByGroupID(groupID int64) ([]*core.Task, error) {
 
 	var tasks []*core.Task
 	for rows.Next() {
-		task := &core.Task{}
-		var taskType string
-
-		if err := rows.Scan(&task.ID, &task.GroupID, &task.Title, &task.Description, &taskType, &task.RewardValue, &task.DefaultQuantity, &task.IsOneTime, &task.CreatedAt); err != nil {
+		task, err := scanTask(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan task: %w", err)
 		}
-
-		task.TaskType = core.TaskType(taskType)
 		tasks = append(tasks, task)
 	}
 
 	return tasks, nil
 }
 
+// SetTaskDeadlinePolicy updates the penalty settings of a task
+func (s *Store) SetTaskDeadlinePolicy(taskID int64, policy core.DeadlinePolicy) error {
+	return setTaskDeadlinePolicy(s.DB, taskID, policy)
+}
+
+// setTaskDeadlinePolicy saves a deadline policy through db or a transaction
+func setTaskDeadlinePolicy(q execer, taskID int64, policy core.DeadlinePolicy) error {
+	_, err := q.Exec(
+		`UPDATE tasks SET penalty_amount = ?, late_reward_percent = ?, penalty_grace_minutes = ?, is_urgent = ?,
+		nag_interval_minutes = ?, nag_escalate_after = ?, nag_partner_id = ?, reminder_offsets = ? WHERE id = ?`,
+		policy.PenaltyAmount, policy.LateRewardPercent, policy.GraceMinutes, policy.Urgent,
+		policy.NagIntervalMinutes, policy.NagEscalateAfter, policy.NagPartnerID,
+		encodeReminderOffsets(policy.ReminderOffsets), taskID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update deadline policy: %w", err)
+	}
+	return nil
+}
+
+// SetTaskDueAt moves or clears the deadline of a task
+func (s *Store) SetTaskDueAt(taskID int64, dueAt *time.Time) error {
+	_, err := s.DB.Exec("UPDATE tasks SET due_at = ? WHERE id = ?", nullableTime(dueAt), taskID)
+	if err != nil {
+		return fmt.Errorf("failed to update due date: %w", err)
+	}
+	return nil
+}
+
+// SetTaskAvailableFrom hides a task until the given time; nil shows it right away
+func (s *Store) SetTaskAvailableFrom(taskID int64, availableFrom *time.Time) error {
+	_, err := s.DB.Exec("UPDATE tasks SET available_from = ? WHERE id = ?", nullableTime(availableFrom), taskID)
+	if err != nil {
+		return fmt.Errorf("failed to update start date: %w", err)
+	}
+	return nil
+}
+
+// SetTaskSnooze hides a task from one member until the given time; nil shows
+// it to them again
+func (s *Store) SetTaskSnooze(taskID, userID int64, until *time.Time) error {
+	var err error
+	if until == nil {
+		_, err = s.DB.Exec(`DELETE FROM task_snoozes WHERE task_id = ? AND user_id = ?`, taskID, userID)
+	} else {
+		_, err = s.DB.Exec(`INSERT OR REPLACE INTO task_snoozes (task_id, user_id, until) VALUES (?, ?, ?)`,
+			taskID, userID, until.UTC())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update snooze: %w", err)
+	}
+	return nil
+}
+
+// GetTaskSnoozes returns until when the user hid each task they snoozed and
+// that is still hidden at now, keyed by task ID
+func (s *Store) GetTaskSnoozes(userID int64, now time.Time) (map[int64]time.Time, error) {
+	rows, err := s.DB.Query(`SELECT task_id, until FROM task_snoozes WHERE user_id = ? AND until > ?`, userID, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snoozes: %w", err)
+	}
+	defer rows.Close()
+
+	snoozes := make(map[int64]time.Time)
+	for rows.Next() {
+		var taskID int64
+		var until time.Time
+		if err := rows.Scan(&taskID, &until); err != nil {
+			return nil, fmt.Errorf("failed to scan snooze: %w", err)
+		}
+		snoozes[taskID] = until
+	}
+	return snoozes, rows.Err()
+}
+
+// GetTasksWithUnevaluatedDeadline retrieves tasks whose current deadline the
+// penalty evaluator has not processed yet
+func (s *Store) GetTasksWithUnevaluatedDeadline() ([]*core.Task, error) {
+	return s.queryTasks(
+		"SELECT " + taskColumns + " FROM tasks WHERE due_at IS NOT NULL AND (deadline_evaluated_at IS NULL OR deadline_evaluated_at != due_at)",
+	)
+}
+
+// SetTaskDeadlineEvaluated records that the task's current deadline has been processed
+func (s *Store) SetTaskDeadlineEvaluated(taskID int64) error {
+	// Copy the column itself so the != comparison above matches exactly
+	_, err := s.DB.Exec("UPDATE tasks SET deadline_evaluated_at = due_at WHERE id = ?", taskID)
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	return nil
+}
+
+// ApplyTaskPenalty deducts a missed-deadline penalty from one member. The
+// penalty is recorded in the same database transaction, so each deadline is
+// penalized at most once per member. It reports whether a penalty was applied.
+func (s *Store) ApplyTaskPenalty(task *core.Task, userID int64, amount int, description string) (bool, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"INSERT OR IGNORE INTO task_penalties (task_id, user_id, due_at) VALUES (?, ?, ?)",
+		task.ID, userID, task.DueAt.UTC(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record penalty: %w", err)
+	}
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+		return false, err
+	}
+
+	result, err = tx.Exec(
+		"INSERT INTO transactions (user_id, group_id, amount, currency_id, source_type, source_id, quantity, description, notes) VALUES (?, ?, ?, ?, ?, ?, 1, ?, '')",
+		userID, task.GroupID, -amount, core.DefaultCurrencyID, string(core.SourceTypePenalty), task.ID, description,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	transactionID, err := result.LastInsertId()
+	if err != nil {
+		return false, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	if _, err := tx.Exec(
+		"UPDATE task_penalties SET transaction_id = ? WHERE task_id = ? AND user_id = ? AND due_at = ?",
+		transactionID, task.ID, userID, task.DueAt.UTC(),
+	); err != nil {
+		return false, fmt.Errorf("failed to record penalty: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit penalty: %w", err)
+	}
+	return true, nil
+}
+
 // CreateShopItem creates a new shop item in a group
 func (s *Store) CreateShopItem(groupID int64, title, description string, cost int, isOneTime bool) (*core.ShopItem, error) {
-	result, err := s.DB.Exec(
+	id, err := insertShopItem(s.DB, groupID, title, description, cost, isOneTime)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetShopItemByID(id)
+}
+
+// insertShopItem inserts a shop item and returns its ID
+func insertShopItem(q execer, groupID int64, title, description string, cost int, isOneTime bool) (int64, error) {
+	result, err := q.Exec(
 		"INSERT INTO shop_items (group_id, title, description, cost, is_one_time) VALUES (?, ?, ?, ?, ?)",
 		groupID, title, description, cost, isOneTime,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create shop item: %w", err)
+		return 0, fmt.Errorf("failed to create shop item: %w", err)
 	}
 
 	id, err := result.LastInsertId()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
 	}
-
-	return s.GetShopItemByID(id)
+	return id, nil
 }
 
 // GetShopItemByID retrieves a shop item by ID
@@ -265,17 +478,20 @@ func (s *Store) GetShopItemsByGroupID(groupID int64) ([]*core.ShopItem, error) {
 	return items, nil
 }
 
-// UpdateTask updates a task's details
-// Note: This method currently doesn't update due_at field
-// TODO: Add UpdateTaskWithDueDate method or extend this to handle due_at when UI is implemented
-func (s *Store) UpdateTask(id int64, title, description string, taskType core.TaskType, rewardValue int, defaultQuantity int, isOneTime bool) error {
+// UpdateTask updates a task's details, including its deadline (nil clears it)
+func (s *Store) UpdateTask(id int64, title, description string, taskType core.TaskType, rewardValue int, defaultQuantity int, isOneTime bool, dueAt *time.Time) error {
+	return updateTask(s.DB, id, title, description, taskType, rewardValue, defaultQuantity, isOneTime, dueAt)
+}
+
+// updateTask updates a task through db or a transaction
+func updateTask(q execer, id int64, title, description string, taskType core.TaskType, rewardValue int, defaultQuantity int, isOneTime bool, dueAt *time.Time) error {
 	query := `
 		UPDATE tasks
-		SET title = ?, description = ?, task_type = ?, reward_value = ?, default_quantity = ?, is_one_time = ?
+		SET title = ?, description = ?, task_type = ?, reward_value = ?, default_quantity = ?, is_one_time = ?, due_at = ?
 		WHERE id = ?
 	`
 
-	_, err := s.DB.Exec(query, title, description, string(taskType), rewardValue, defaultQuantity, isOneTime, id)
+	_, err := q.Exec(query, title, description, string(taskType), rewardValue, defaultQuantity, isOneTime, nullableTime(dueAt), id)
 	if err != nil {
 		return fmt.Errorf("failed to update task: %w", err)
 	}
@@ -313,11 +529,16 @@ func (s *Store) UndoTaskDeletion(id int64) (*core.Task, error) {
 	}
 
 	// Re-insert the task with the same ID
-	query := `INSERT INTO tasks (id, group_id, title, description, task_type, reward_value, default_quantity, is_one_time, due_at, created_at)
-	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO tasks (id, group_id, title, description, task_type, reward_value, default_quantity, is_one_time, due_at,
+	          penalty_amount, late_reward_percent, penalty_grace_minutes, deadline_evaluated_at, available_from, is_urgent,
+	          nag_interval_minutes, nag_escalate_after, nag_partner_id, reminder_offsets, created_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err = s.DB.Exec(query, task.ID, task.GroupID, task.Title, task.Description, string(task.TaskType),
-		task.RewardValue, task.DefaultQuantity, task.IsOneTime, task.DueAt, task.CreatedAt)
+		task.RewardValue, task.DefaultQuantity, task.IsOneTime, task.DueAt, task.Deadline.PenaltyAmount,
+		task.Deadline.LateRewardPercent, task.Deadline.GraceMinutes, task.DeadlineEvaluatedAt, task.AvailableFrom, task.Deadline.Urgent,
+		task.Deadline.NagIntervalMinutes, task.Deadline.NagEscalateAfter, task.Deadline.NagPartnerID,
+		encodeReminderOffsets(task.Deadline.ReminderOffsets), task.CreatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to restore task: %w", err)
 	}
@@ -330,13 +551,18 @@ func (s *Store) UndoTaskDeletion(id int64) (*core.Task, error) {
 
 // UpdateShopItem updates a shop item's details
 func (s *Store) UpdateShopItem(id int64, title, description string, cost int, isOneTime bool) error {
+	return updateShopItem(s.DB, id, title, description, cost, isOneTime)
+}
+
+// updateShopItem updates a shop item through db or a transaction
+func updateShopItem(q execer, id int64, title, description string, cost int, isOneTime bool) error {
 	query := `
 		UPDATE shop_items
 		SET title = ?, description = ?, cost = ?, is_one_time = ?
 		WHERE id = ?
 	`
 
-	_, err := s.DB.Exec(query, title, description, cost, isOneTime, id)
+	_, err := q.Exec(query, title, description, cost, isOneTime, id)
 	if err != nil {
 		return fmt.Errorf("failed to update shop item: %w", err)
 	}
@@ -4,25 +4,46 @@ import (
 	"database/sql"
 	"fmt"
 	"small-rpg-adhd-monolith/internal/core"
+	"time"
 )
 
-// CreateTransaction creates a new transaction
-func (s *Store) CreateTransaction(userID, groupID int64, amount int, sourceType core.SourceType, sourceID *int64, quantity int, description, notes string) (*core.Transaction, error) {
-	var result sql.Result
-	var err error
-
-	if sourceID != nil {
-		result, err = s.DB.Exec(
-			"INSERT INTO transactions (user_id, group_id, amount, source_type, source_id, quantity, description, notes) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
-			userID, groupID, amount, string(sourceType), *sourceID, quantity, description, notes,
-		)
-	} else {
-		result, err = s.DB.Exec(
-			"INSERT INTO transactions (user_id, group_id, amount, source_type, quantity, description, notes) VALUES (?, ?, ?, ?, ?, ?, ?)",
-			userID, groupID, amount, string(sourceType), quantity, description, notes,
-		)
+// transactionColumns is the column list used by scanTransaction
+const transactionColumns = "id, user_id, group_id, amount, currency_id, parent_transaction_id, source_type, source_id, quantity, COALESCE(description, ''), COALESCE(notes, ''), created_at"
+
+// scanTransaction scans a row selected with transactionColumns
+func scanTransaction(row rowScanner) (*core.Transaction, error) {
+	tx := &core.Transaction{}
+	var sourceType string
+	var sourceID sql.NullInt64
+	var parentID sql.NullInt64
+
+	if err := row.Scan(&tx.ID, &tx.UserID, &tx.GroupID, &tx.Amount, &tx.CurrencyID, &parentID, &sourceType, &sourceID, &tx.Quantity, &tx.Description, &tx.Notes, &tx.CreatedAt); err != nil {
+		return nil, err
 	}
 
+	tx.SourceType = core.SourceType(sourceType)
+	if sourceID.Valid {
+		tx.SourceID = &sourceID.Int64
+	}
+	if parentID.Valid {
+		tx.ParentID = &parentID.Int64
+	}
+
+	return tx, nil
+}
+
+// CreateTransaction creates a new transaction in the default currency
+func (s *Store) CreateTransaction(userID, groupID int64, amount int, sourceType core.SourceType, sourceID *int64, quantity int, description, notes string) (*core.Transaction, error) {
+	return s.CreateCurrencyTransaction(userID, groupID, core.DefaultCurrencyID, amount, sourceType, sourceID, nil, quantity, description, notes)
+}
+
+// CreateCurrencyTransaction creates a new transaction in the given currency.
+// parentID links extra-currency legs to the main transaction so they can be undone together.
+func (s *Store) CreateCurrencyTransaction(userID, groupID, currencyID int64, amount int, sourceType core.SourceType, sourceID, parentID *int64, quantity int, description, notes string) (*core.Transaction, error) {
+	result, err := s.DB.Exec(
+		"INSERT INTO transactions (user_id, group_id, amount, currency_id, parent_transaction_id, source_type, source_id, quantity, description, notes) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		userID, groupID, amount, currencyID, parentID, string(sourceType), sourceID, quantity, description, notes,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
@@ -37,17 +58,7 @@ func (s *Store) CreateTransaction(userID, groupID int64, amount int, sourceType
 
 // GetTransactionByID retrieves a transaction by ID
 func (s *Store) GetTransactionByID(id int64) (*core.Transaction, error) {
-	tx := &core.Transaction{}
-	var sourceType string
-	var sourceID sql.NullInt64
-	var description sql.NullString
-	var notes sql.NullString
-
-	err := s.DB.QueryRow(
-		"SELECT id, user_id, group_id, amount, source_type, source_id, quantity, COALESCE(description, ''), COALESCE(notes, ''), created_at FROM transactions WHERE id = ?",
-		id,
-	).Scan(&tx.ID, &tx.UserID, &tx.GroupID, &tx.Amount, &sourceType, &sourceID, &tx.Quantity, &description, &notes, &tx.CreatedAt)
-
+	tx, err := scanTransaction(s.DB.QueryRow("SELECT "+transactionColumns+" FROM transactions WHERE id = ?", id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("transaction not found")
@@ -55,26 +66,59 @@ func (s *Store) GetTransactionByID(id int64) (*core.Transaction, error) {
 		return nil, fmt.Errorf("failed to get transaction: %w", err)
 	}
 
-	tx.SourceType = core.SourceType(sourceType)
-	if sourceID.Valid {
-		tx.SourceID = &sourceID.Int64
-	}
-	if description.Valid {
-		tx.Description = description.String
-	}
-	if notes.Valid {
-		tx.Notes = notes.String
-	}
-
 	return tx, nil
 }
 
 // GetTransactionsByUserAndGroup retrieves all transactions for a user in a group
 func (s *Store) GetTransactionsByUserAndGroup(userID, groupID int64) ([]*core.Transaction, error) {
-	rows, err := s.DB.Query(
-		"SELECT id, user_id, group_id, amount, source_type, source_id, quantity, COALESCE(description, ''), COALESCE(notes, ''), created_at FROM transactions WHERE user_id = ? AND group_id = ? ORDER BY created_at DESC",
+	return s.queryTransactions(
+		"SELECT "+transactionColumns+" FROM transactions WHERE user_id = ? AND group_id = ? ORDER BY created_at DESC",
 		userID, groupID,
 	)
+}
+
+// GetTransactionsByGroupID retrieves all transactions recorded in a group
+func (s *Store) GetTransactionsByGroupID(groupID int64) ([]*core.Transaction, error) {
+	return s.queryTransactions(
+		"SELECT "+transactionColumns+" FROM transactions WHERE group_id = ? ORDER BY created_at ASC",
+		groupID,
+	)
+}
+
+// GetTransactionsByUserSince retrieves a user's transactions in all groups
+// recorded at or after since
+func (s *Store) GetTransactionsByUserSince(userID int64, since time.Time) ([]*core.Transaction, error) {
+	return s.queryTransactions(
+		"SELECT "+transactionColumns+" FROM transactions WHERE user_id = ? AND created_at >= ? ORDER BY created_at ASC",
+		userID, timestampArg(since),
+	)
+}
+
+// timestampArg formats a time for comparison with created_at columns, which
+// SQLite writes as UTC "YYYY-MM-DD HH:MM:SS"
+func timestampArg(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// GetTaskTransactionsByUser retrieves a member's completions of a task, including undo reversals
+func (s *Store) GetTaskTransactionsByUser(taskID, userID int64) ([]*core.Transaction, error) {
+	return s.queryTransactions(
+		"SELECT "+transactionColumns+" FROM transactions WHERE source_type = ? AND source_id = ? AND user_id = ? AND parent_transaction_id IS NULL ORDER BY created_at ASC",
+		string(core.SourceTypeTask), taskID, userID,
+	)
+}
+
+// GetChildTransactions retrieves the extra-currency legs of a transaction
+func (s *Store) GetChildTransactions(parentID int64) ([]*core.Transaction, error) {
+	return s.queryTransactions(
+		"SELECT "+transactionColumns+" FROM transactions WHERE parent_transaction_id = ? ORDER BY id ASC",
+		parentID,
+	)
+}
+
+// queryTransactions runs a query selecting transactionColumns and scans every row
+func (s *Store) queryTransactions(query string, args ...interface{}) ([]*core.Transaction, error) {
+	rows, err := s.DB.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query transactions: %w", err)
 	}
@@ -82,35 +126,27 @@ func (s *Store) GetTransactionsByUserAndGroup(userID, groupID int64) ([]*core.Tr
 
 	var transactions []*core.Transaction
 	for rows.Next() {
-		tx := &core.Transaction{}
-		var sourceType string
-		var sourceID sql.NullInt64
-		var description sql.NullString
-		var notes sql.NullString
-
-		if err := rows.Scan(&tx.ID, &tx.UserID, &tx.GroupID, &tx.Amount, &sourceType, &sourceID, &tx.Quantity, &description, &notes, &tx.CreatedAt); err != nil {
+		tx, err := scanTransaction(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan transaction: %w", err)
 		}
-
-		tx.SourceType = core.SourceType(sourceType)
-		if sourceID.Valid {
-			tx.SourceID = &sourceID.Int64
-		}
-		if description.Valid {
-			tx.Description = description.String
-		}
-		if notes.Valid {
-			tx.Notes = notes.String
-		}
-
 		transactions = append(transactions, tx)
 	}
 
 	return transactions, nil
 }
 
-// GetTaskCompletionHistory retrieves detailed task completion history
-func (s *Store) GetTaskCompletionHistory(userID, groupID int64) ([]*core.TaskCompletionHistory, error) {
+// GetTaskCompletionHistory retrieves a page of detailed task completion
+// history, undo reversals included, newest first
+func (s *Store) GetTaskCompletionHistory(groupID int64, filter core.LogFilter) ([]*core.TaskCompletionHistory, error) {
+	clause, args := logFilterClause(filter, "transactions", "t", "source_id")
+	switch filter.Status {
+	case core.LogStatusCompleted:
+		clause += " AND t.amount >= 0"
+	case core.LogStatusUndone:
+		clause += " AND t.amount < 0"
+	}
+
 	query := `
 		SELECT
 			t.id, t.user_id, t.group_id, t.amount, t.source_type, t.source_id, t.quantity,
@@ -120,11 +156,13 @@ func (s *Store) GetTaskCompletionHistory(userID, groupID int64) ([]*core.TaskCom
 		FROM transactions t
 		LEFT JOIN tasks task ON t.source_id = task.id
 		JOIN users u ON t.user_id = u.id
-		WHERE t.user_id = ? AND t.group_id = ? AND t.source_type = 'task'
-		ORDER BY t.created_at DESC
+		WHERE t.group_id = ? AND t.source_type = 'task' AND t.parent_transaction_id IS NULL` + clause + `
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT ?
 	`
 
-	rows, err := s.DB.Query(query, userID, groupID)
+	args = append([]interface{}{groupID}, args...)
+	rows, err := s.DB.Query(query, append(args, filter.Limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query task completion history: %w", err)
 	}
@@ -202,13 +240,18 @@ func (s *Store) GetTaskCompletionHistory(userID, groupID int64) ([]*core.TaskCom
 	return history, nil
 }
 
-// GetBalance calculates the total balance for a user in a group
+// GetBalance calculates the default currency balance for a user in a group
 func (s *Store) GetBalance(userID, groupID int64) (int, error) {
+	return s.GetCurrencyBalance(userID, groupID, core.DefaultCurrencyID)
+}
+
+// GetCurrencyBalance calculates the balance in one currency for a user in a group
+func (s *Store) GetCurrencyBalance(userID, groupID, currencyID int64) (int, error) {
 	var balance sql.NullInt64
 
 	err := s.DB.QueryRow(
-		"SELECT SUM(amount) FROM transactions WHERE user_id = ? AND group_id = ?",
-		userID, groupID,
+		"SELECT SUM(amount) FROM transactions WHERE user_id = ? AND group_id = ? AND currency_id = ?",
+		userID, groupID, currencyID,
 	).Scan(&balance)
 
 	if err != nil {
@@ -221,3 +264,27 @@ func (s *Store) GetBalance(userID, groupID int64) (int, error) {
 
 	return int(balance.Int64), nil
 }
+
+// GetBalances calculates the balance in every currency a user holds in a group
+func (s *Store) GetBalances(userID, groupID int64) (map[int64]int, error) {
+	rows, err := s.DB.Query(
+		"SELECT currency_id, SUM(amount) FROM transactions WHERE user_id = ? AND group_id = ? GROUP BY currency_id",
+		userID, groupID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := make(map[int64]int)
+	for rows.Next() {
+		var currencyID int64
+		var amount int
+		if err := rows.Scan(&currencyID, &amount); err != nil {
+			return nil, fmt.Errorf("failed to scan balance: %w", err)
+		}
+		balances[currencyID] = amount
+	}
+
+	return balances, nil
+}
@@ -44,9 +44,9 @@ func (s *Store) GetUserByID(id int64) (*core.User, error) {
 	var telegramID sql.NullInt64
 
 	err := s.DB.QueryRow(
-		"SELECT id, telegram_id, username, language, created_at FROM users WHERE id = ?",
+		"SELECT id, telegram_id, username, language, timezone, created_at FROM users WHERE id = ?",
 		id,
-	).Scan(&user.ID, &telegramID, &user.Username, &user.Language, &user.CreatedAt)
+	).Scan(&user.ID, &telegramID, &user.Username, &user.Language, &user.Timezone, &user.CreatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -71,15 +71,24 @@ func (s *Store) UpdateUserLanguage(userID int64, language string) error {
 	return err
 }
 
+// UpdateUserTimezone sets the user's IANA timezone; empty falls back to server time
+func (s *Store) UpdateUserTimezone(userID int64, timezone string) error {
+	_, err := s.DB.Exec(`UPDATE users SET timezone = ? WHERE id = ?`, timezone, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user timezone: %w", err)
+	}
+	return nil
+}
+
 // GetUserByTelegramID retrieves a user by Telegram ID
 func (s *Store) GetUserByTelegramID(telegramID int64) (*core.User, error) {
 	user := &core.User{}
 	var tgID sql.NullInt64
 
 	err := s.DB.QueryRow(
-		"SELECT id, telegram_id, username, language, created_at FROM users WHERE telegram_id = ?",
+		"SELECT id, telegram_id, username, language, timezone, created_at FROM users WHERE telegram_id = ?",
 		telegramID,
-	).Scan(&user.ID, &tgID, &user.Username, &user.Language, &user.CreatedAt)
+	).Scan(&user.ID, &tgID, &user.Username, &user.Language, &user.Timezone, &user.CreatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -101,9 +110,9 @@ func (s *Store) GetUserByUsername(username string) (*core.User, error) {
 	var telegramID sql.NullInt64
 
 	err := s.DB.QueryRow(
-		"SELECT id, telegram_id, username, language, created_at FROM users WHERE username = ?",
+		"SELECT id, telegram_id, username, language, timezone, created_at FROM users WHERE username = ?",
 		username,
-	).Scan(&user.ID, &telegramID, &user.Username, &user.Language, &user.CreatedAt)
+	).Scan(&user.ID, &telegramID, &user.Username, &user.Language, &user.Timezone, &user.CreatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -122,7 +131,7 @@ func (s *Store) GetUserByUsername(username string) (*core.User, error) {
 // GetUsersByGroupID retrieves all users in a group
 func (s *Store) GetUsersByGroupID(groupID int64) ([]*core.User, error) {
 	rows, err := s.DB.Query(`
-		SELECT u.id, u.telegram_id, u.username, u.language, u.created_at
+		SELECT u.id, u.telegram_id, u.username, u.language, u.timezone, u.created_at
 		FROM users u
 		INNER JOIN group_members gm ON u.id = gm.user_id
 		WHERE gm.group_id = ?
@@ -137,7 +146,7 @@ func (s *Store) GetUsersByGroupID(groupID int64) ([]*core.User, error) {
 		user := &core.User{}
 		var telegramID sql.NullInt64
 
-		if err := rows.Scan(&user.ID, &telegramID, &user.Username, &user.Language, &user.CreatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &telegramID, &user.Username, &user.Language, &user.Timezone, &user.CreatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
 
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// wishlistColumns is the column list used by scanWishlistItem
+const wishlistColumns = "id, user_id, group_id, shop_item_id, reserved, notified_at, created_at"
+
+// scanWishlistItem scans a row selected with wishlistColumns
+func scanWishlistItem(row rowScanner) (*core.WishlistItem, error) {
+	w := &core.WishlistItem{}
+	var notifiedAt sql.NullTime
+
+	if err := row.Scan(&w.ID, &w.UserID, &w.GroupID, &w.ShopItemID, &w.Reserved, &notifiedAt, &w.CreatedAt); err != nil {
+		return nil, err
+	}
+	if notifiedAt.Valid {
+		w.NotifiedAt = &notifiedAt.Time
+	}
+
+	return w, nil
+}
+
+// AddWishlistItem pins a shop item to a user's wishlist; pinning twice is a no-op
+func (s *Store) AddWishlistItem(userID, groupID, shopItemID int64) (*core.WishlistItem, error) {
+	_, err := s.DB.Exec(
+		"INSERT OR IGNORE INTO wishlist_items (user_id, group_id, shop_item_id) VALUES (?, ?, ?)",
+		userID, groupID, shopItemID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add wishlist item: %w", err)
+	}
+
+	w, err := scanWishlistItem(s.DB.QueryRow(
+		"SELECT "+wishlistColumns+" FROM wishlist_items WHERE user_id = ? AND shop_item_id = ?",
+		userID, shopItemID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wishlist item: %w", err)
+	}
+
+	return w, nil
+}
+
+// GetWishlistItemByID retrieves a wishlist entry by ID
+func (s *Store) GetWishlistItemByID(id int64) (*core.WishlistItem, error) {
+	w, err := scanWishlistItem(s.DB.QueryRow("SELECT "+wishlistColumns+" FROM wishlist_items WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("wishlist item not found")
+		}
+		return nil, fmt.Errorf("failed to get wishlist item: %w", err)
+	}
+
+	return w, nil
+}
+
+// GetWishlistByUser retrieves all wishlist entries of a user
+func (s *Store) GetWishlistByUser(userID int64) ([]*core.WishlistItem, error) {
+	return s.queryWishlist("SELECT "+wishlistColumns+" FROM wishlist_items WHERE user_id = ? ORDER BY created_at ASC", userID)
+}
+
+// GetWishlistByUserAndGroup retrieves a user's wishlist entries in one group
+func (s *Store) GetWishlistByUserAndGroup(userID, groupID int64) ([]*core.WishlistItem, error) {
+	return s.queryWishlist("SELECT "+wishlistColumns+" FROM wishlist_items WHERE user_id = ? AND group_id = ? ORDER BY created_at ASC", userID, groupID)
+}
+
+// GetAllWishlistItems retrieves every wishlist entry, used for affordability checks
+func (s *Store) GetAllWishlistItems() ([]*core.WishlistItem, error) {
+	return s.queryWishlist("SELECT " + wishlistColumns + " FROM wishlist_items ORDER BY user_id, group_id")
+}
+
+// queryWishlist runs a query selecting wishlistColumns and scans every row
+func (s *Store) queryWishlist(query string, args ...interface{}) ([]*core.WishlistItem, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wishlist: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*core.WishlistItem
+	for rows.Next() {
+		w, err := scanWishlistItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan wishlist item: %w", err)
+		}
+		items = append(items, w)
+	}
+
+	return items, nil
+}
+
+// DeleteWishlistItem removes a wishlist entry and releases its reservation
+func (s *Store) DeleteWishlistItem(id int64) error {
+	_, err := s.DB.Exec("DELETE FROM wishlist_items WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete wishlist item: %w", err)
+	}
+	return nil
+}
+
+// SetWishlistReserved sets how much cheese is locked toward a wishlist entry
+func (s *Store) SetWishlistReserved(id int64, reserved int) error {
+	_, err := s.DB.Exec("UPDATE wishlist_items SET reserved = ? WHERE id = ?", reserved, id)
+	if err != nil {
+		return fmt.Errorf("failed to update reservation: %w", err)
+	}
+	return nil
+}
+
+// SetWishlistNotified records when the affordability notification was sent; nil clears it
+func (s *Store) SetWishlistNotified(id int64, notifiedAt *time.Time) error {
+	_, err := s.DB.Exec("UPDATE wishlist_items SET notified_at = ? WHERE id = ?", nullableTime(notifiedAt), id)
+	if err != nil {
+		return fmt.Errorf("failed to update wishlist notification: %w", err)
+	}
+	return nil
+}
+
+// GetReservedTotal sums the cheese a user locked toward wishlist goals in a group
+func (s *Store) GetReservedTotal(userID, groupID int64) (int, error) {
+	var total sql.NullInt64
+
+	err := s.DB.QueryRow(
+		"SELECT SUM(reserved) FROM wishlist_items WHERE user_id = ? AND group_id = ?",
+		userID, groupID,
+	).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to calculate reserved balance: %w", err)
+	}
+
+	return int(total.Int64), nil
+}
@@ -0,0 +1,121 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"small-rpg-adhd-monolith/internal/core"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// failedNotificationsLimit caps how many failed notifications the admin view lists
+const failedNotificationsLimit = 200
+
+type failedNotificationsData struct {
+	basePageData
+	Failed      []*core.FailedNotification
+	MaxAttempts int
+	Error       string
+	Success     string
+}
+
+// parseAdminTelegramIDs reads a comma-separated list of Telegram user IDs
+// such as "12345, 67890". Usernames are not accepted: they are chosen by the
+// user and not unique, so anyone could claim one.
+func parseAdminTelegramIDs(list string) map[int64]bool {
+	admins := make(map[int64]bool)
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			log.Printf("⚠️ Ignoring invalid Telegram ID %q in ADMIN_TELEGRAM_IDS", field)
+			continue
+		}
+		admins[id] = true
+	}
+	return admins
+}
+
+// isAdmin reports whether the user may see the admin pages
+func (s *Server) isAdmin(user *core.User) bool {
+	return user != nil && user.TelegramID != nil && s.admins[*user.TelegramID]
+}
+
+// requireAdmin is middleware that limits a route to ADMIN_TELEGRAM_IDS
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := s.getUserID(r)
+		user, err := s.service.GetUserByID(userID)
+		if err != nil || !s.isAdmin(user) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleFailedNotifications lists notifications that could not be delivered
+func (s *Server) handleFailedNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+	user, err := s.service.GetUserByID(userID)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	failed, err := s.service.GetFailedNotifications(failedNotificationsLimit)
+	if err != nil {
+		log.Printf("Failed to load failed notifications: %v", err)
+		http.Error(w, "Failed to load notifications", http.StatusInternalServerError)
+		return
+	}
+
+	data := failedNotificationsData{
+		basePageData: s.buildBasePageData(user, s.detectLocale(r)),
+		Failed:       failed,
+		MaxAttempts:  core.NotificationMaxAttempts,
+		Error:        r.URL.Query().Get("error"),
+		Success:      r.URL.Query().Get("success"),
+	}
+
+	s.renderTemplate(w, "admin_notifications.html", data)
+}
+
+// handleRetryNotification queues a failed notification for another delivery
+func (s *Server) handleRetryNotification(w http.ResponseWriter, r *http.Request) {
+	notificationID, err := strconv.ParseInt(chi.URLParam(r, "notificationID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.service.RetryFailedNotification(notificationID); err != nil {
+		http.Redirect(w, r, "/admin/notifications?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/admin/notifications?success=Notification queued for delivery", http.StatusSeeOther)
+}
+
+// handleDismissNotification deletes a failed notification
+func (s *Server) handleDismissNotification(w http.ResponseWriter, r *http.Request) {
+	notificationID, err := strconv.ParseInt(chi.URLParam(r, "notificationID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.service.DismissFailedNotification(notificationID); err != nil {
+		http.Redirect(w, r, "/admin/notifications?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/admin/notifications?success=Notification dismissed", http.StatusSeeOther)
+}
@@ -0,0 +1,139 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// channelRow is one delivery channel of the dashboard form
+type channelRow struct {
+	*core.ChannelPreference
+	Position int // 1-based place in the order channels are tried in
+}
+
+// channelRows lists the user's channels in the order they are tried
+func channelRows(prefs []*core.ChannelPreference) ([]channelRow, []int) {
+	rows := make([]channelRow, len(prefs))
+	positions := make([]int, len(prefs))
+	for i, pref := range prefs {
+		rows[i] = channelRow{ChannelPreference: pref, Position: i + 1}
+		positions[i] = i + 1
+	}
+	return rows, positions
+}
+
+// handleSetChannels saves the notification channels form of the dashboard
+func (s *Server) handleSetChannels(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	current, err := s.service.GetChannelPreferences(userID)
+	if err != nil {
+		http.Redirect(w, r, "/dashboard?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+
+	type move struct {
+		pref     *core.ChannelPreference
+		position int
+		moved    bool
+	}
+	moves := make([]move, len(current))
+	for i, pref := range current {
+		position, err := strconv.Atoi(r.FormValue("position_" + pref.Channel))
+		if err != nil {
+			position = i + 1
+		}
+		moves[i] = move{
+			pref: &core.ChannelPreference{
+				Channel: pref.Channel,
+				Enabled: r.FormValue("channel_"+pref.Channel) == "on",
+				Address: r.FormValue("address_" + pref.Channel),
+			},
+			position: position,
+			moved:    position != i+1,
+		}
+	}
+	// A channel moved onto a taken place goes before the one already there
+	sort.SliceStable(moves, func(i, j int) bool {
+		if moves[i].position != moves[j].position {
+			return moves[i].position < moves[j].position
+		}
+		return moves[i].moved && !moves[j].moved
+	})
+
+	prefs := make([]*core.ChannelPreference, len(moves))
+	for i, m := range moves {
+		m.pref.Priority = i
+		prefs[i] = m.pref
+	}
+
+	if err := s.service.UpdateChannelPreferences(userID, prefs); err != nil {
+		http.Redirect(w, r, "/dashboard?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/dashboard?success=Notification channels saved", http.StatusSeeOther)
+}
+
+// pushSubscriptionRequest is the JSON of a browser PushSubscription
+type pushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// handlePushSubscribe stores the push subscription of the user's browser
+func (s *Server) handlePushSubscribe(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	var req pushSubscriptionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+		http.Error(w, "Invalid subscription", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.service.SubscribeWebPush(userID, req.Endpoint, req.Keys.P256dh, req.Keys.Auth); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePushUnsubscribe forgets the push subscription of the user's browser
+func (s *Server) handlePushUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	var req pushSubscriptionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil || req.Endpoint == "" {
+		http.Error(w, "Invalid subscription", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.service.UnsubscribeWebPush(req.Endpoint); err != nil {
+		log.Printf("Failed to remove push subscription: %v", err)
+		http.Error(w, "Failed to unsubscribe", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleServiceWorker serves the Web Push service worker. It lives at the
+// root so that it may control every page of the site.
+func (s *Server) handleServiceWorker(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/javascript")
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeFile(w, r, "static/sw.js")
+}
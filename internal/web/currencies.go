@@ -0,0 +1,177 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"small-rpg-adhd-monolith/internal/core"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// parseCurrencyAmounts reads per-currency amounts from form fields named prefix+currencyID
+func parseCurrencyAmounts(r *http.Request, prefix string) []core.CurrencyAmount {
+	var amounts []core.CurrencyAmount
+	for key, values := range r.Form {
+		if !strings.HasPrefix(key, prefix) || len(values) == 0 || values[0] == "" {
+			continue
+		}
+		currencyID, err := strconv.ParseInt(strings.TrimPrefix(key, prefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		amount, err := strconv.Atoi(values[0])
+		if err != nil || amount <= 0 {
+			continue
+		}
+		amounts = append(amounts, core.CurrencyAmount{CurrencyID: currencyID, Amount: amount})
+	}
+	return amounts
+}
+
+// parseCurrencyLabels reads localized currency labels from label_<locale> form fields
+func parseCurrencyLabels(r *http.Request) map[string]string {
+	return map[string]string{
+		"en": r.FormValue("label_en"),
+		"ru": r.FormValue("label_ru"),
+	}
+}
+
+// handleCreateCurrency adds a group-defined currency (owner only)
+func (s *Server) handleCreateCurrency(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	groupIDStr := chi.URLParam(r, "groupID")
+	groupID, err := strconv.ParseInt(groupIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	_, err = s.service.CreateCurrency(userID, groupID, r.FormValue("name"), r.FormValue("emoji"), parseCurrencyLabels(r))
+	if err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/groups/"+groupIDStr+"?success=Currency created", http.StatusSeeOther)
+}
+
+// handleUpdateCurrency renames a group-defined currency (owner only)
+func (s *Server) handleUpdateCurrency(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	currencyIDStr := chi.URLParam(r, "currencyID")
+	currencyID, err := strconv.ParseInt(currencyIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid currency ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	groupIDStr := r.FormValue("group_id")
+
+	err = s.service.UpdateCurrency(userID, currencyID, r.FormValue("name"), r.FormValue("emoji"), parseCurrencyLabels(r))
+	if err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/groups/"+groupIDStr+"?success=Currency updated", http.StatusSeeOther)
+}
+
+// handleCreateExchangeRule adds an exchange rule between two currencies (owner only)
+func (s *Server) handleCreateExchangeRule(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	groupIDStr := chi.URLParam(r, "groupID")
+	groupID, err := strconv.ParseInt(groupIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	fromCurrencyID, err1 := strconv.ParseInt(r.FormValue("from_currency_id"), 10, 64)
+	toCurrencyID, err2 := strconv.ParseInt(r.FormValue("to_currency_id"), 10, 64)
+	fromAmount, err3 := strconv.Atoi(r.FormValue("from_amount"))
+	toAmount, err4 := strconv.Atoi(r.FormValue("to_amount"))
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"?error=Invalid exchange rule", http.StatusSeeOther)
+		return
+	}
+
+	_, err = s.service.CreateExchangeRule(userID, groupID, fromCurrencyID, fromAmount, toCurrencyID, toAmount)
+	if err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/groups/"+groupIDStr+"?success=Exchange rule created", http.StatusSeeOther)
+}
+
+// handleDeleteExchangeRule removes an exchange rule (owner only)
+func (s *Server) handleDeleteExchangeRule(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	ruleIDStr := chi.URLParam(r, "ruleID")
+	ruleID, err := strconv.ParseInt(ruleIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	groupIDStr := r.FormValue("group_id")
+
+	if err := s.service.DeleteExchangeRule(userID, ruleID); err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/groups/"+groupIDStr+"?success=Exchange rule deleted", http.StatusSeeOther)
+}
+
+// handleExchangeCurrency trades currency once according to an exchange rule
+func (s *Server) handleExchangeCurrency(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	ruleIDStr := chi.URLParam(r, "ruleID")
+	ruleID, err := strconv.ParseInt(ruleIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	groupIDStr := r.FormValue("group_id")
+
+	if _, err := s.service.ExchangeCurrency(userID, ruleID); err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/groups/"+groupIDStr+"?success=Exchange complete", http.StatusSeeOther)
+}
@@ -0,0 +1,65 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// dateTimeLayout is the format of datetime-local inputs
+const dateTimeLayout = "2006-01-02T15:04"
+
+// parseDateTimeField reads a date field in the user's timezone. Text typed
+// into the "<name>_text" companion field, such as "tomorrow 9am", wins over
+// the datetime-local picker. An empty field gives nil.
+func parseDateTimeField(r *http.Request, name string, loc *time.Location) (*time.Time, error) {
+	if text := strings.TrimSpace(r.FormValue(name + "_text")); text != "" {
+		t, err := core.ParseNaturalTime(text, time.Now(), loc)
+		if err != nil {
+			return nil, err
+		}
+		return &t, nil
+	}
+
+	v := r.FormValue(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(dateTimeLayout, v, loc)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// datePreview is how a typed date was understood, for the preview under date fields
+type datePreview struct {
+	Value string `json:"value,omitempty"` // datetime-local value
+	Label string `json:"label,omitempty"`
+	Past  bool   `json:"past,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// handleDatePreview interprets a typed date such as "next friday" in the
+// user's timezone so forms can show it before saving
+func (s *Server) handleDatePreview(w http.ResponseWriter, r *http.Request) {
+	loc := s.userLocation(r)
+	now := time.Now()
+
+	var preview datePreview
+	t, err := core.ParseNaturalTime(r.URL.Query().Get("q"), now, loc)
+	if err != nil {
+		preview.Error = err.Error()
+	} else {
+		t = t.In(loc)
+		preview.Value = t.Format(dateTimeLayout)
+		preview.Label = t.Format("Mon, Jan 2 2006 at 15:04 MST")
+		preview.Past = !t.After(now)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
@@ -0,0 +1,151 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// maxDefinitionUpload caps the size of an uploaded group definition
+const maxDefinitionUpload = 1 << 20
+
+// starterPackOption is a starter pack as offered on the dashboard
+type starterPackOption struct {
+	ID          string
+	Name        string
+	Description string
+	Tasks       int
+	Rewards     int
+}
+
+// starterPackOptions lists the bundled starter packs in the user's language
+func (s *Server) starterPackOptions(locale string) []starterPackOption {
+	var options []starterPackOption
+	for _, pack := range s.packs {
+		def := pack.Definition(locale)
+		options = append(options, starterPackOption{
+			ID:          pack.ID,
+			Name:        def.Name,
+			Description: def.Description,
+			Tasks:       len(def.Tasks),
+			Rewards:     len(def.Rewards),
+		})
+	}
+	return options
+}
+
+// readDefinition reads the group definition of a form: an uploaded file in
+// "definition", or the bundled starter pack named by "pack". It returns nil
+// when the form has neither.
+func (s *Server) readDefinition(r *http.Request, locale string) (*core.GroupDefinition, error) {
+	file, _, err := r.FormFile("definition")
+	if err == nil {
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the file")
+		}
+		return core.ParseGroupDefinition(data)
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, fmt.Errorf("the file is too large")
+	}
+	if err != http.ErrMissingFile && err != http.ErrNotMultipart {
+		return nil, fmt.Errorf("failed to read the file")
+	}
+
+	if id := r.FormValue("pack"); id != "" {
+		for _, pack := range s.packs {
+			if pack.ID == id {
+				return pack.Definition(locale), nil
+			}
+		}
+		return nil, fmt.Errorf("unknown starter pack")
+	}
+	return nil, nil
+}
+
+// importSummary describes the result of an import for the success message
+func importSummary(result *core.ImportResult) string {
+	return fmt.Sprintf("Imported: %d added, %d updated, %d skipped", result.Created, result.Updated, result.Skipped)
+}
+
+// handleGroupDefinition downloads a group's tasks, shop items, currencies and
+// settings as a definition in YAML (default) or JSON (?format=json)
+func (s *Server) handleGroupDefinition(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "groupID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "yaml"
+	}
+	if format != "yaml" && format != "json" {
+		http.Error(w, "Invalid format", http.StatusBadRequest)
+		return
+	}
+
+	def, err := s.service.ExportGroupDefinition(userID, groupID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	data, err := def.Encode(format)
+	if err != nil {
+		http.Error(w, "Failed to export group", http.StatusInternalServerError)
+		return
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"group-%d.%s\"", groupID, format))
+	w.Write(data)
+}
+
+// handleImportGroupDefinition adds an uploaded definition or a starter pack
+// to an existing group (owner only), treating what the group already has as
+// the "conflicts" field says
+func (s *Server) handleImportGroupDefinition(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+	locale := s.detectLocale(r)
+
+	groupIDStr := chi.URLParam(r, "groupID")
+	groupID, err := strconv.ParseInt(groupIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	target := "/groups/" + groupIDStr
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxDefinitionUpload)
+	def, err := s.readDefinition(r, locale)
+	if err != nil {
+		http.Redirect(w, r, withMessage(target, "error", err.Error()), http.StatusSeeOther)
+		return
+	}
+	if def == nil {
+		http.Redirect(w, r, withMessage(target, "error", "Choose a file or a starter pack"), http.StatusSeeOther)
+		return
+	}
+
+	result, err := s.service.ImportGroupDefinition(userID, groupID, def, r.FormValue("conflicts"))
+	if err != nil {
+		http.Redirect(w, r, withMessage(target, "error", err.Error()), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, withMessage(target, "success", importSummary(result)), http.StatusSeeOther)
+}
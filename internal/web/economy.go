@@ -0,0 +1,89 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type economyData struct {
+	basePageData
+	Economy *core.Economy
+	Balance int
+	Error   string
+	Success string
+}
+
+// handleGroupEconomy shows the owner how cheese flows through the group
+func (s *Server) handleGroupEconomy(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+	locale := s.detectLocale(r)
+
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "groupID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	economy, err := s.service.GetEconomy(userID, groupID, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	user, err := s.service.GetUserByID(userID)
+	if err != nil {
+		http.Error(w, "Failed to load user", http.StatusInternalServerError)
+		return
+	}
+	balance, err := s.service.GetBalance(userID, groupID)
+	if err != nil {
+		http.Error(w, "Failed to load balance", http.StatusInternalServerError)
+		return
+	}
+
+	data := economyData{
+		basePageData: s.buildBasePageData(user, locale),
+		Economy:      economy,
+		Balance:      balance,
+		Error:        r.URL.Query().Get("error"),
+		Success:      r.URL.Query().Get("success"),
+	}
+	data.basePageData.Group = economy.Group
+
+	s.renderTemplate(w, "economy.html", data)
+}
+
+// handleSetEconomyTargets saves the goals of the economy page (owner only)
+func (s *Server) handleSetEconomyTargets(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	groupIDStr := chi.URLParam(r, "groupID")
+	groupID, err := strconv.ParseInt(groupIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	workDays, err := strconv.Atoi(r.FormValue("work_days"))
+	if err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"/economy?error=Invalid days of work", http.StatusSeeOther)
+		return
+	}
+	spendPercent, err := strconv.Atoi(r.FormValue("spend_percent"))
+	if err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"/economy?error=Invalid spending target", http.StatusSeeOther)
+		return
+	}
+
+	if err := s.service.UpdateEconomyTargets(userID, groupID, workDays, spendPercent); err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"/economy?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/groups/"+groupIDStr+"/economy?success=Targets saved", http.StatusSeeOther)
+}
@@ -0,0 +1,250 @@
+package web
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// exportFlushEvery is how many rows are written between flushes, so large
+// exports reach the browser while they are still being read
+const exportFlushEvery = 200
+
+// exportWriteTimeout replaces the server's write timeout for exports. It is
+// renewed on every flush, so a large export can take as long as it needs as
+// long as the client keeps reading.
+const exportWriteTimeout = 30 * time.Second
+
+// exportWriter streams the rows of an export to the response. Nothing is
+// written until the first row, so errors found before it still get a status.
+type exportWriter struct {
+	w       http.ResponseWriter
+	buf     *bufio.Writer
+	csv     *csv.Writer // nil for JSON
+	columns []string
+	header  []string // CSV header, the columns or their translations
+	rows    int
+}
+
+// begin sends the headers of the response and the start of the document
+func (e *exportWriter) begin() error {
+	if e.csv != nil {
+		return e.csv.Write(e.header)
+	}
+	_, err := e.buf.WriteString("[")
+	return err
+}
+
+// row writes one row, beginning the document on the first
+func (e *exportWriter) row(values core.ExportRow) error {
+	if e.rows == 0 {
+		if err := e.begin(); err != nil {
+			return err
+		}
+	}
+	e.rows++
+
+	if e.csv != nil {
+		record := make([]string, len(values))
+		for i, v := range values {
+			record[i] = exportCSVValue(v)
+		}
+		if err := e.csv.Write(record); err != nil {
+			return err
+		}
+	} else {
+		if e.rows > 1 {
+			e.buf.WriteString(",")
+		}
+		e.buf.WriteString("\n  {")
+		for i, v := range values {
+			if i > 0 {
+				e.buf.WriteString(", ")
+			}
+			key, _ := json.Marshal(e.columns[i])
+			value, err := json.Marshal(exportJSONValue(v))
+			if err != nil {
+				return err
+			}
+			e.buf.Write(key)
+			e.buf.WriteString(": ")
+			e.buf.Write(value)
+		}
+		e.buf.WriteString("}")
+	}
+
+	if e.rows%exportFlushEvery == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+// finish ends the document, which is just a header or [] when empty
+func (e *exportWriter) finish() error {
+	if e.rows == 0 {
+		if err := e.begin(); err != nil {
+			return err
+		}
+	}
+	if e.csv == nil {
+		if e.rows > 0 {
+			e.buf.WriteString("\n")
+		}
+		e.buf.WriteString("]\n")
+	}
+	return e.flush()
+}
+
+// flush pushes what was written so far to the client
+func (e *exportWriter) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := e.buf.Flush(); err != nil {
+		return err
+	}
+	if f, ok := e.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	e.extendDeadline()
+	return nil
+}
+
+// extendDeadline gives the export another exportWriteTimeout to send its
+// next rows
+func (e *exportWriter) extendDeadline() {
+	err := http.NewResponseController(e.w).SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Failed to extend export write deadline: %v", err)
+	}
+}
+
+// exportCSVValue formats an export value as a CSV field. Text starting like
+// a spreadsheet formula gets a leading ' so titles and notes written by
+// members are shown, not run, when the file is opened.
+func exportCSVValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// exportJSONValue prepares an export value for JSON, times as RFC 3339 in UTC
+func exportJSONValue(v interface{}) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return v
+}
+
+// ExportDatasets are the datasets the dashboard offers for download
+func (d dashboardData) ExportDatasets() []string {
+	return core.ExportDatasets
+}
+
+// handleExport downloads transactions, purchases or tasks as CSV (default)
+// or JSON (?format=json): of one group, or of all the user's groups without
+// a group ID. Owners export every member's rows with ?member=all, and CSV
+// headers are translated with ?headers=localized.
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+	locale := s.detectLocale(r)
+
+	var groupID int64
+	if g := chi.URLParam(r, "groupID"); g != "" {
+		var err error
+		if groupID, err = strconv.ParseInt(g, 10, 64); err != nil {
+			http.Error(w, "Invalid group ID", http.StatusBadRequest)
+			return
+		}
+	}
+	dataset := chi.URLParam(r, "dataset")
+	columns, ok := core.ExportColumns[dataset]
+	if !ok {
+		http.Error(w, "Unknown export", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		http.Error(w, "Invalid format", http.StatusBadRequest)
+		return
+	}
+
+	filename := dataset + "-" + time.Now().Format("2006-01-02") + "." + format
+	if groupID != 0 {
+		filename = fmt.Sprintf("group-%d-%s", groupID, filename)
+	}
+	e := &exportWriter{w: w, buf: bufio.NewWriter(w), columns: columns, header: columns}
+	if format == "csv" {
+		e.csv = csv.NewWriter(e.buf)
+		if query.Get("headers") == "localized" && s.translator != nil {
+			e.header = make([]string, len(columns))
+			for i, column := range columns {
+				e.header[i] = s.translator.T(locale, "export.col."+column)
+			}
+		}
+	}
+
+	// The server's write timeout counts from the start of the request and
+	// would cut large exports short
+	e.extendDeadline()
+
+	start := func() {
+		if format == "csv" {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	}
+	err := s.service.Export(userID, groupID, query.Get("member") == "all", dataset, func(row core.ExportRow) error {
+		if e.rows == 0 {
+			start()
+		}
+		return e.row(row)
+	})
+	if err != nil {
+		if e.rows == 0 {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		// The status is already sent, a cut-off file is all that can be done
+		log.Printf("Error exporting %s for user %d: %v", dataset, userID, err)
+		e.flush()
+		return
+	}
+	if e.rows == 0 {
+		start()
+	}
+	if err := e.finish(); err != nil {
+		log.Printf("Error exporting %s for user %d: %v", dataset, userID, err)
+	}
+}
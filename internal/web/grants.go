@@ -0,0 +1,111 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"small-rpg-adhd-monolith/internal/core"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// handleCreateScheduledGrant sets up an allowance or interest payment (owner only)
+func (s *Server) handleCreateScheduledGrant(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	groupIDStr := chi.URLParam(r, "groupID")
+	groupID, err := strconv.ParseInt(groupIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	grant := &core.ScheduledGrant{
+		GroupID:     groupID,
+		Kind:        core.GrantKind(r.FormValue("kind")),
+		Schedule:    r.FormValue("schedule"),
+		Description: r.FormValue("description"),
+	}
+	grant.CurrencyID, _ = strconv.ParseInt(r.FormValue("currency_id"), 10, 64)
+	if v := r.FormValue("amount"); v != "" {
+		if grant.Amount, err = strconv.Atoi(v); err != nil {
+			http.Redirect(w, r, "/groups/"+groupIDStr+"?error=Invalid amount", http.StatusSeeOther)
+			return
+		}
+	}
+	if v := r.FormValue("rate_percent"); v != "" {
+		if grant.RatePercent, err = strconv.Atoi(v); err != nil {
+			http.Redirect(w, r, "/groups/"+groupIDStr+"?error=Invalid interest rate", http.StatusSeeOther)
+			return
+		}
+	}
+	for _, v := range r.Form["member_ids"] {
+		if memberID, err := strconv.ParseInt(v, 10, 64); err == nil {
+			grant.MemberIDs = append(grant.MemberIDs, memberID)
+		}
+	}
+
+	if _, err := s.service.CreateScheduledGrant(userID, grant); err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/groups/"+groupIDStr+"?success=Scheduled grant created", http.StatusSeeOther)
+}
+
+// handlePauseScheduledGrant pauses a grant (owner only)
+func (s *Server) handlePauseScheduledGrant(w http.ResponseWriter, r *http.Request) {
+	s.setScheduledGrantPaused(w, r, true)
+}
+
+// handleResumeScheduledGrant resumes a paused grant (owner only)
+func (s *Server) handleResumeScheduledGrant(w http.ResponseWriter, r *http.Request) {
+	s.setScheduledGrantPaused(w, r, false)
+}
+
+// setScheduledGrantPaused implements the pause and resume handlers
+func (s *Server) setScheduledGrantPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	userID, _ := s.getUserID(r)
+
+	grantID, err := strconv.ParseInt(chi.URLParam(r, "grantID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid grant ID", http.StatusBadRequest)
+		return
+	}
+
+	grant, err := s.service.SetScheduledGrantPaused(userID, grantID, paused)
+	if err != nil {
+		http.Redirect(w, r, "/dashboard?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	message := "Scheduled grant resumed"
+	if paused {
+		message = "Scheduled grant paused"
+	}
+	http.Redirect(w, r, "/groups/"+strconv.FormatInt(grant.GroupID, 10)+"?success="+message, http.StatusSeeOther)
+}
+
+// handleDeleteScheduledGrant removes a grant (owner only)
+func (s *Server) handleDeleteScheduledGrant(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	grantID, err := strconv.ParseInt(chi.URLParam(r, "grantID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid grant ID", http.StatusBadRequest)
+		return
+	}
+
+	grant, err := s.service.DeleteScheduledGrant(userID, grantID)
+	if err != nil {
+		http.Redirect(w, r, "/dashboard?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/groups/"+strconv.FormatInt(grant.GroupID, 10)+"?success=Scheduled grant deleted", http.StatusSeeOther)
+}
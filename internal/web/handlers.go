@@ -3,7 +3,9 @@ package web
 import (
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"small-rpg-adhd-monolith/internal/core"
 
@@ -11,33 +13,77 @@ import (
 )
 
 type basePageData struct {
+	UserID       int64
 	Username     string
 	UserPhotoURL string
 	Group        *core.Group
 	Locale       string
+	// Timezone is the user's IANA zone name, empty until they pick one
+	Timezone string
+	TZ       *time.Location // Zone all times on the page are shown in
+	IsAdmin  bool           // Listed in ADMIN_TELEGRAM_IDS
 }
 
 type dashboardData struct {
 	basePageData
-	Groups []*core.Group
-	Error  string
+	Groups         []*core.Group
+	ArchivedGroups []*core.Group
+	LedgerExports  []*core.GroupLedgerExport
+	StarterPacks   []starterPackOption
+	Wishlist       []*core.WishlistProgress
+	Pauses         []*core.Pause
+	Quiet          *core.NotificationSettings
+	QuietDays      []quietDayRow
+	Channels       []channelRow
+	// ChannelPositions are the places a channel can be moved to
+	ChannelPositions []int
+	// PushKey is the VAPID public key, empty when Web Push is not set up
+	PushKey           string
+	PushSubscriptions int
+	Now               time.Time
+	Error             string
+	Success           string
 }
 
 type groupViewData struct {
 	basePageData
-	Group     *core.Group
-	Tasks     []*core.Task
-	ShopItems []*core.ShopItem
-	Members   []*core.User
-	Balance   int
-	Error     string
-	Success   string
+	Group *core.Group
+	Tasks []*core.Task
+	// HiddenTasks are snoozed until a later start date
+	HiddenTasks []*core.Task
+	ShopItems   []*core.ShopItem
+	Members     []*core.User
+	Balance     int
+	Balances    []*core.CurrencyBalance
+	// Currencies are the group-defined currencies, without cheese
+	Currencies    []*core.Currency
+	ExchangeRules []*core.ExchangeRule
+	Grants        []*core.ScheduledGrant
+	Pauses        []*core.Pause
+	// Resting marks members who are on a personal or group pause
+	Resting map[int64]bool
+	Now     time.Time // Reference time for overdue badges
+	// Wishlist maps shop item IDs to the user's wishlist entries
+	Wishlist map[int64]*core.WishlistItem
+	Reserved int
+	// Nagged marks tasks the user gets escalating reminders for
+	Nagged map[int64]bool
+	// MutedReminders marks tasks the user turned reminders off for
+	MutedReminders map[int64]bool
+	// StarterPacks can be imported into the group by its owner
+	StarterPacks []starterPackOption
+	Error        string
+	Success      string
 }
 
 func (s *Server) buildBasePageData(user *core.User, locale string) basePageData {
 	data := basePageData{
+		UserID:   user.ID,
 		Username: user.Username,
 		Locale:   locale,
+		Timezone: user.Timezone,
+		TZ:       user.Location(),
+		IsAdmin:  s.isAdmin(user),
 	}
 
 	profile, err := s.service.GetUserProfile(user.ID)
@@ -70,26 +116,92 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	archivedGroups, err := s.service.GetArchivedGroupsByUserID(userID)
+	if err != nil {
+		http.Error(w, "Failed to load groups", http.StatusInternalServerError)
+		return
+	}
+
+	ledgerExports, err := s.service.GetLedgerExportsByOwner(userID)
+	if err != nil {
+		log.Printf("Failed to load ledger exports for %d: %v", userID, err)
+	}
+
+	wishlist, err := s.service.GetWishlistProgress(userID)
+	if err != nil {
+		log.Printf("Failed to load wishlist for %d: %v", userID, err)
+	}
+
+	pauses, err := s.service.GetUserPauses(userID)
+	if err != nil {
+		log.Printf("Failed to load pauses for %d: %v", userID, err)
+	}
+
+	quiet, err := s.service.GetNotificationSettings(userID)
+	if err != nil {
+		log.Printf("Failed to load notification settings for %d: %v", userID, err)
+		quiet = &core.NotificationSettings{UserID: userID}
+	}
+
+	prefs, err := s.service.GetChannelPreferences(userID)
+	if err != nil {
+		log.Printf("Failed to load notification channels for %d: %v", userID, err)
+	}
+	channels, positions := channelRows(prefs)
+
+	pushSubscriptions, err := s.service.GetPushSubscriptions(userID)
+	if err != nil {
+		log.Printf("Failed to load push subscriptions for %d: %v", userID, err)
+	}
+
 	data := dashboardData{
-		basePageData: s.buildBasePageData(user, locale),
-		Groups:       groups,
+		basePageData:      s.buildBasePageData(user, locale),
+		Groups:            groups,
+		ArchivedGroups:    archivedGroups,
+		LedgerExports:     ledgerExports,
+		StarterPacks:      s.starterPackOptions(locale),
+		Wishlist:          wishlist,
+		Pauses:            pauses,
+		Quiet:             quiet,
+		QuietDays:         quietDayRows(quiet),
+		Channels:          channels,
+		ChannelPositions:  positions,
+		PushKey:           s.service.WebPushKey(),
+		PushSubscriptions: len(pushSubscriptions),
+		Now:               time.Now(),
+		Error:             r.URL.Query().Get("error"),
+		Success:           r.URL.Query().Get("success"),
 	}
 
 	s.renderTemplate(w, "dashboard.html", data)
 }
 
-// handleCreateGroup creates a new group
+// handleCreateGroup creates a new group, empty or set up from an uploaded
+// definition or a starter pack
 func (s *Server) handleCreateGroup(w http.ResponseWriter, r *http.Request) {
 	userID, _ := s.getUserID(r)
+	locale := s.detectLocale(r)
 
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Invalid form data", http.StatusBadRequest)
+	r.Body = http.MaxBytesReader(w, r.Body, maxDefinitionUpload)
+	def, err := s.readDefinition(r, locale)
+	if err != nil {
+		http.Redirect(w, r, withMessage("/dashboard", "error", err.Error()), http.StatusSeeOther)
 		return
 	}
 
 	groupName := r.FormValue("name")
+	if def != nil {
+		group, result, err := s.service.CreateGroupFromDefinition(userID, groupName, def)
+		if err != nil {
+			http.Redirect(w, r, withMessage("/dashboard", "error", err.Error()), http.StatusSeeOther)
+			return
+		}
+		http.Redirect(w, r, withMessage("/groups/"+strconv.FormatInt(group.ID, 10), "success", importSummary(result)), http.StatusSeeOther)
+		return
+	}
+
 	if groupName == "" {
-		http.Error(w, "Group name is required", http.StatusBadRequest)
+		http.Redirect(w, r, withMessage("/dashboard", "error", "Group name is required"), http.StatusSeeOther)
 		return
 	}
 
@@ -159,6 +271,12 @@ func (s *Server) handleGroupView(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to load tasks", http.StatusInternalServerError)
 		return
 	}
+	if err := s.service.ApplyTaskSnoozes(userID, tasks); err != nil {
+		http.Error(w, "Failed to load tasks", http.StatusInternalServerError)
+		return
+	}
+	tasks, hiddenTasks := core.SplitAvailableTasks(tasks, time.Now())
+	core.SortTasksByDueDate(tasks)
 
 	// Get shop items
 	shopItems, err := s.service.GetShopItemsByGroupID(groupID)
@@ -181,19 +299,275 @@ func (s *Server) handleGroupView(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	balances, err := s.service.GetBalances(userID, groupID)
+	if err != nil {
+		http.Error(w, "Failed to load balance", http.StatusInternalServerError)
+		return
+	}
+
+	currencies, err := s.service.GetCurrencies(groupID)
+	if err != nil {
+		http.Error(w, "Failed to load currencies", http.StatusInternalServerError)
+		return
+	}
+
+	exchangeRules, err := s.service.GetExchangeRules(groupID)
+	if err != nil {
+		http.Error(w, "Failed to load exchange rules", http.StatusInternalServerError)
+		return
+	}
+
+	grants, err := s.service.GetScheduledGrants(groupID)
+	if err != nil {
+		http.Error(w, "Failed to load scheduled grants", http.StatusInternalServerError)
+		return
+	}
+
+	pauses, err := s.service.GetGroupPauses(groupID)
+	if err != nil {
+		http.Error(w, "Failed to load pauses", http.StatusInternalServerError)
+		return
+	}
+
+	resting, err := s.service.GetRestingMembers(groupID)
+	if err != nil {
+		http.Error(w, "Failed to load pauses", http.StatusInternalServerError)
+		return
+	}
+
+	wishlist, err := s.service.GetWishlistByGroup(userID, groupID)
+	if err != nil {
+		http.Error(w, "Failed to load wishlist", http.StatusInternalServerError)
+		return
+	}
+
+	reserved, err := s.service.GetReservedBalance(userID, groupID)
+	if err != nil {
+		http.Error(w, "Failed to load wishlist", http.StatusInternalServerError)
+		return
+	}
+
+	nagged, err := s.service.GetNaggedTaskIDs(userID)
+	if err != nil {
+		http.Error(w, "Failed to load reminders", http.StatusInternalServerError)
+		return
+	}
+
+	mutedReminders, err := s.service.GetReminderOptOuts(userID)
+	if err != nil {
+		http.Error(w, "Failed to load reminders", http.StatusInternalServerError)
+		return
+	}
+
 	data := groupViewData{
+		basePageData:   s.buildBasePageData(user, locale),
+		Group:          group,
+		Tasks:          tasks,
+		HiddenTasks:    hiddenTasks,
+		ShopItems:      shopItems,
+		Members:        members,
+		Balance:        balance,
+		Balances:       balances,
+		Currencies:     currencies,
+		ExchangeRules:  exchangeRules,
+		Grants:         grants,
+		Pauses:         pauses,
+		Resting:        resting,
+		Now:            time.Now(),
+		Wishlist:       wishlist,
+		Reserved:       reserved,
+		Nagged:         nagged,
+		MutedReminders: mutedReminders,
+		StarterPacks:   s.starterPackOptions(locale),
+		Success:        r.URL.Query().Get("success"),
+		Error:          r.URL.Query().Get("error"),
+	}
+	data.basePageData.Group = group
+
+	s.renderTemplate(w, "group.html", data)
+}
+
+// handleArchiveGroup archives a group (owner only)
+func (s *Server) handleArchiveGroup(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	groupIDStr := chi.URLParam(r, "groupID")
+	groupID, err := strconv.ParseInt(groupIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.service.ArchiveGroup(userID, groupID); err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/dashboard?success=Party archived", http.StatusSeeOther)
+}
+
+// handleUnarchiveGroup restores an archived group (owner only)
+func (s *Server) handleUnarchiveGroup(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	groupIDStr := chi.URLParam(r, "groupID")
+	groupID, err := strconv.ParseInt(groupIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.service.UnarchiveGroup(userID, groupID); err != nil {
+		http.Redirect(w, r, "/dashboard?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/groups/"+groupIDStr+"?success=Party restored", http.StatusSeeOther)
+}
+
+type groupDeleteData struct {
+	basePageData
+	Group       *core.Group
+	GracePeriod int // days
+	Error       string
+}
+
+// handleGroupDeletePage shows the deletion confirmation step (owner only)
+func (s *Server) handleGroupDeletePage(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+	locale := s.detectLocale(r)
+
+	groupIDStr := chi.URLParam(r, "groupID")
+	groupID, err := strconv.ParseInt(groupIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.service.GetUserByID(userID)
+	if err != nil {
+		http.Error(w, "Failed to load user", http.StatusInternalServerError)
+		return
+	}
+
+	group, err := s.service.GetGroupByID(groupID)
+	if err != nil {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+	if group.OwnerID != userID {
+		http.Error(w, "Only the party founder can delete it", http.StatusForbidden)
+		return
+	}
+
+	data := groupDeleteData{
 		basePageData: s.buildBasePageData(user, locale),
 		Group:        group,
-		Tasks:        tasks,
-		ShopItems:    shopItems,
-		Members:      members,
-		Balance:      balance,
-		Success:      r.URL.Query().Get("success"),
+		GracePeriod:  int(core.GroupDeletionGracePeriod.Hours() / 24),
 		Error:        r.URL.Query().Get("error"),
 	}
 	data.basePageData.Group = group
 
-	s.renderTemplate(w, "group.html", data)
+	s.renderTemplate(w, "group_delete.html", data)
+}
+
+// handleDeleteGroup schedules a group for deletion after the confirmation step
+func (s *Server) handleDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	groupIDStr := chi.URLParam(r, "groupID")
+	groupID, err := strconv.ParseInt(groupIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	confirmName := r.FormValue("confirm_name")
+	retainLedger := r.FormValue("retain_ledger") == "on"
+
+	group, err := s.service.RequestGroupDeletion(userID, groupID, confirmName, retainLedger)
+	if err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"/delete?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/dashboard?success=Party "+group.Name+" will be deleted on "+group.DeleteAfter.In(s.userLocation(r)).Format("Jan 2, 15:04"), http.StatusSeeOther)
+}
+
+// handleCancelGroupDeletion cancels a pending group deletion
+func (s *Server) handleCancelGroupDeletion(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	groupIDStr := chi.URLParam(r, "groupID")
+	groupID, err := strconv.ParseInt(groupIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.service.CancelGroupDeletion(userID, groupID); err != nil {
+		http.Redirect(w, r, "/dashboard?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/dashboard?success=Deletion cancelled, party stays archived", http.StatusSeeOther)
+}
+
+// handleDownloadLedgerExport serves a retained ledger of a deleted group as JSON
+func (s *Server) handleDownloadLedgerExport(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	exportIDStr := chi.URLParam(r, "exportID")
+	exportID, err := strconv.ParseInt(exportIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid export ID", http.StatusBadRequest)
+		return
+	}
+
+	export, err := s.service.GetLedgerExport(userID, exportID)
+	if err != nil {
+		http.Error(w, "Ledger not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"ledger-"+exportIDStr+".json\"")
+	w.Write([]byte(export.Data))
+}
+
+// parseDeadlinePolicy reads the deadline settings of the task form; empty fields keep the defaults
+func parseDeadlinePolicy(r *http.Request) (core.DeadlinePolicy, error) {
+	policy := core.DefaultDeadlinePolicy()
+	if v, err := strconv.Atoi(r.FormValue("penalty_amount")); err == nil {
+		policy.PenaltyAmount = v
+	}
+	if v, err := strconv.Atoi(r.FormValue("late_reward_percent")); err == nil {
+		policy.LateRewardPercent = v
+	}
+	if v, err := strconv.Atoi(r.FormValue("penalty_grace_minutes")); err == nil {
+		policy.GraceMinutes = v
+	}
+	policy.Urgent = r.FormValue("is_urgent") == "on"
+	if v, err := strconv.Atoi(r.FormValue("nag_interval_minutes")); err == nil {
+		policy.NagIntervalMinutes = v
+	}
+	if v, err := strconv.Atoi(r.FormValue("nag_escalate_after")); err == nil {
+		policy.NagEscalateAfter = v
+	}
+	if v, err := strconv.ParseInt(r.FormValue("nag_partner_id"), 10, 64); err == nil && v > 0 {
+		policy.NagPartnerID = &v
+	}
+	offsets, err := core.ParseReminderOffsets(r.FormValue("reminder_offsets"))
+	if err != nil {
+		return policy, err
+	}
+	policy.ReminderOffsets = offsets
+	return policy, nil
 }
 
 // handleCreateTask creates a new task in a group
@@ -238,7 +612,25 @@ func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	_, err = s.service.CreateTask(groupID, title, description, taskType, rewardValue, defaultQuantity, isOneTime)
+	extraRewards := parseCurrencyAmounts(r, "extra_reward_")
+	policy, err := parseDeadlinePolicy(r)
+	if err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	loc := s.userLocation(r)
+	availableFrom, err := parseDateTimeField(r, "available_from", loc)
+	if err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"?error=Invalid start date: "+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	dueAt, err := parseDateTimeField(r, "due_at", loc)
+	if err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"?error=Invalid due date: "+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+
+	_, err = s.service.CreateTask(groupID, title, description, taskType, rewardValue, defaultQuantity, isOneTime, extraRewards, policy, availableFrom, dueAt)
 	if err != nil {
 		http.Redirect(w, r, "/groups/"+groupIDStr+"?error="+err.Error(), http.StatusSeeOther)
 		return
@@ -312,18 +704,24 @@ func (s *Server) handleCreateShopItem(w http.ResponseWriter, r *http.Request) {
 	costStr := r.FormValue("cost")
 	isOneTime := r.FormValue("is_one_time") == "on"
 
-	if title == "" || costStr == "" {
+	if title == "" {
 		http.Error(w, "Missing required fields", http.StatusBadRequest)
 		return
 	}
 
-	cost, err := strconv.Atoi(costStr)
-	if err != nil || cost <= 0 {
-		http.Error(w, "Invalid cost", http.StatusBadRequest)
-		return
+	// Cheese cost may be left empty when the item is priced in other currencies
+	cost := 0
+	if costStr != "" {
+		cost, err = strconv.Atoi(costStr)
+		if err != nil || cost < 0 {
+			http.Error(w, "Invalid cost", http.StatusBadRequest)
+			return
+		}
 	}
 
-	_, err = s.service.CreateShopItem(groupID, title, description, cost, isOneTime)
+	extraPrices := parseCurrencyAmounts(r, "extra_price_")
+
+	_, err = s.service.CreateShopItem(groupID, title, description, cost, isOneTime, extraPrices)
 	if err != nil {
 		http.Redirect(w, r, "/groups/"+groupIDStr+"?error="+err.Error(), http.StatusSeeOther)
 		return
@@ -408,7 +806,25 @@ func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	err = s.service.UpdateTask(taskID, title, description, taskType, rewardValue, defaultQuantity, isOneTime)
+	extraRewards := parseCurrencyAmounts(r, "extra_reward_")
+	policy, err := parseDeadlinePolicy(r)
+	if err != nil {
+		http.Redirect(w, r, "/groups/"+strconv.FormatInt(task.GroupID, 10)+"?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	loc := s.userLocation(r)
+	availableFrom, err := parseDateTimeField(r, "available_from", loc)
+	if err != nil {
+		http.Redirect(w, r, "/groups/"+strconv.FormatInt(task.GroupID, 10)+"?error=Invalid start date: "+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	dueAt, err := parseDateTimeField(r, "due_at", loc)
+	if err != nil {
+		http.Redirect(w, r, "/groups/"+strconv.FormatInt(task.GroupID, 10)+"?error=Invalid due date: "+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+
+	err = s.service.UpdateTask(taskID, title, description, taskType, rewardValue, defaultQuantity, isOneTime, extraRewards, policy, availableFrom, dueAt)
 	if err != nil {
 		http.Redirect(w, r, "/groups/"+strconv.FormatInt(task.GroupID, 10)+"?error="+err.Error(), http.StatusSeeOther)
 		return
@@ -462,6 +878,132 @@ func (s *Server) handleUndoDeleteTask(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Task restored: " + task.Title))
 }
 
+// handleSnoozeTask hides a task until a preset number of hours or a chosen time
+func (s *Server) handleSnoozeTask(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	taskID, err := strconv.ParseInt(chi.URLParam(r, "taskID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	task, err := s.service.GetTaskByID(taskID)
+	if err != nil {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+	redirect := "/groups/" + strconv.FormatInt(task.GroupID, 10)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	loc := s.userLocation(r)
+	var until time.Time
+	if hours, err := strconv.Atoi(r.FormValue("hours")); err == nil && hours > 0 {
+		until = time.Now().Add(time.Duration(hours) * time.Hour)
+	} else {
+		chosen, err := parseDateTimeField(r, "until", loc)
+		if err != nil {
+			http.Redirect(w, r, redirect+"?error=Invalid snooze time: "+url.QueryEscape(err.Error()), http.StatusSeeOther)
+			return
+		}
+		if chosen == nil {
+			http.Redirect(w, r, redirect+"?error=Invalid snooze time", http.StatusSeeOther)
+			return
+		}
+		until = *chosen
+	}
+
+	if _, err := s.service.SnoozeTask(userID, taskID, until); err != nil {
+		http.Redirect(w, r, redirect+"?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, redirect+"?success=Task hidden until "+until.In(loc).Format("Jan 2, 15:04"), http.StatusSeeOther)
+}
+
+// handleTaskReminders turns the user's reminders of a task off or back on
+func (s *Server) handleTaskReminders(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	taskID, err := strconv.ParseInt(chi.URLParam(r, "taskID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	task, err := s.service.GetTaskByID(taskID)
+	if err != nil {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+	redirect := "/groups/" + strconv.FormatInt(task.GroupID, 10)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	optOut := r.FormValue("reminders") == "off"
+	if err := s.service.SetTaskReminderOptOut(userID, taskID, optOut); err != nil {
+		http.Redirect(w, r, redirect+"?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+
+	message := "Reminders turned on for "
+	if optOut {
+		message = "Reminders turned off for "
+	}
+	http.Redirect(w, r, redirect+"?success="+url.QueryEscape(message+task.Title), http.StatusSeeOther)
+}
+
+// handleDismissNag stops the user's escalating reminders of a task
+func (s *Server) handleDismissNag(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	taskID, err := strconv.ParseInt(chi.URLParam(r, "taskID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	task, err := s.service.GetTaskByID(taskID)
+	if err != nil {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+	redirect := "/groups/" + strconv.FormatInt(task.GroupID, 10)
+
+	if err := s.service.DismissNag(userID, taskID); err != nil {
+		http.Redirect(w, r, redirect+"?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, redirect+"?success=Stopped reminding you about "+url.QueryEscape(task.Title), http.StatusSeeOther)
+}
+
+// handleShowTaskNow brings a snoozed task back right away
+func (s *Server) handleShowTaskNow(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	taskID, err := strconv.ParseInt(chi.URLParam(r, "taskID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	task, err := s.service.ShowTaskNow(userID, taskID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/groups/"+strconv.FormatInt(task.GroupID, 10)+"?success=Task is visible again", http.StatusSeeOther)
+}
+
 // handleUpdateShopItem updates an existing shop item
 func (s *Server) handleUpdateShopItem(w http.ResponseWriter, r *http.Request) {
 	itemIDStr := chi.URLParam(r, "itemID")
@@ -488,18 +1030,24 @@ func (s *Server) handleUpdateShopItem(w http.ResponseWriter, r *http.Request) {
 	costStr := r.FormValue("cost")
 	isOneTime := r.FormValue("is_one_time") == "on"
 
-	if title == "" || costStr == "" {
+	if title == "" {
 		http.Redirect(w, r, "/groups/"+strconv.FormatInt(item.GroupID, 10)+"?error=Missing required fields", http.StatusSeeOther)
 		return
 	}
 
-	cost, err := strconv.Atoi(costStr)
-	if err != nil || cost <= 0 {
-		http.Redirect(w, r, "/groups/"+strconv.FormatInt(item.GroupID, 10)+"?error=Invalid cost", http.StatusSeeOther)
-		return
+	// Cheese cost may be left empty when the item is priced in other currencies
+	cost := 0
+	if costStr != "" {
+		cost, err = strconv.Atoi(costStr)
+		if err != nil || cost < 0 {
+			http.Redirect(w, r, "/groups/"+strconv.FormatInt(item.GroupID, 10)+"?error=Invalid cost", http.StatusSeeOther)
+			return
+		}
 	}
 
-	err = s.service.UpdateShopItem(itemID, title, description, cost, isOneTime)
+	extraPrices := parseCurrencyAmounts(r, "extra_price_")
+
+	err = s.service.UpdateShopItem(itemID, title, description, cost, isOneTime, extraPrices)
 	if err != nil {
 		http.Redirect(w, r, "/groups/"+strconv.FormatInt(item.GroupID, 10)+"?error="+err.Error(), http.StatusSeeOther)
 		return
@@ -558,9 +1106,16 @@ type taskLogData struct {
 	Group   *core.Group
 	Log     []*core.TaskCompletionHistory
 	Balance int
+	Query   logQuery
+	Tasks   []*core.Task
+	Members []*core.User // Only listed for the owner, who may view everyone's log
+	NextURL string       // Empty on the last page
+	Error   string
+	Success string
 }
 
-// handleTaskLog displays task completion log
+// handleTaskLog displays a page of the task completion log, filtered by
+// member, task, date range and status
 func (s *Server) handleTaskLog(w http.ResponseWriter, r *http.Request) {
 	userID, _ := s.getUserID(r)
 	locale := s.detectLocale(r)
@@ -584,10 +1139,20 @@ func (s *Server) handleTaskLog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	taskLog, err := s.service.GetTaskCompletionHistory(userID, groupID)
+	query, filter, err := parseLogQuery(r, userID, user.Location())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	taskLog, next, err := s.service.GetTaskLog(userID, groupID, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	tasks, err := s.service.GetTasksByGroupID(groupID)
 	if err != nil {
-		log.Printf("Error loading task completion history: %v", err)
-		http.Error(w, "Failed to load log", http.StatusInternalServerError)
+		http.Error(w, "Failed to load tasks", http.StatusInternalServerError)
 		return
 	}
 
@@ -603,8 +1168,21 @@ func (s *Server) handleTaskLog(w http.ResponseWriter, r *http.Request) {
 		Group:        group,
 		Log:          taskLog,
 		Balance:      balance,
+		Query:        query,
+		Tasks:        tasks,
+		Error:        r.URL.Query().Get("error"),
+		Success:      r.URL.Query().Get("success"),
 	}
 	data.basePageData.Group = group
+	if group.OwnerID == userID {
+		if data.Members, err = s.service.GetUsersByGroupID(groupID); err != nil {
+			http.Error(w, "Failed to load members", http.StatusInternalServerError)
+			return
+		}
+	}
+	if next != 0 {
+		data.NextURL = query.URL(r.URL.Path, next)
+	}
 
 	s.renderTemplate(w, "task_log.html", data)
 }
@@ -614,9 +1192,16 @@ type purchaseLogData struct {
 	Group   *core.Group
 	Log     []*core.PurchaseHistory
 	Balance int
+	Query   logQuery
+	Items   []*core.ShopItem
+	Members []*core.User // Only listed for the owner, who may view everyone's log
+	NextURL string       // Empty on the last page
+	Error   string
+	Success string
 }
 
-// handlePurchaseLog displays purchase log
+// handlePurchaseLog displays a page of the purchase log, filtered by member,
+// shop item, date range and status
 func (s *Server) handlePurchaseLog(w http.ResponseWriter, r *http.Request) {
 	userID, _ := s.getUserID(r)
 	locale := s.detectLocale(r)
@@ -640,9 +1225,20 @@ func (s *Server) handlePurchaseLog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log, err := s.service.GetPurchaseHistory(userID, groupID)
+	query, filter, err := parseLogQuery(r, userID, user.Location())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log, next, err := s.service.GetPurchaseLog(userID, groupID, filter)
 	if err != nil {
-		http.Error(w, "Failed to load log", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	items, err := s.service.GetShopItemsByGroupID(groupID)
+	if err != nil {
+		http.Error(w, "Failed to load shop items", http.StatusInternalServerError)
 		return
 	}
 
@@ -658,8 +1254,21 @@ func (s *Server) handlePurchaseLog(w http.ResponseWriter, r *http.Request) {
 		Group:        group,
 		Log:          log,
 		Balance:      balance,
+		Query:        query,
+		Items:        items,
+		Error:        r.URL.Query().Get("error"),
+		Success:      r.URL.Query().Get("success"),
 	}
 	data.basePageData.Group = group
+	if group.OwnerID == userID {
+		if data.Members, err = s.service.GetUsersByGroupID(groupID); err != nil {
+			http.Error(w, "Failed to load members", http.StatusInternalServerError)
+			return
+		}
+	}
+	if next != 0 {
+		data.NextURL = query.URL(r.URL.Path, next)
+	}
 
 	s.renderTemplate(w, "purchase_log.html", data)
 }
@@ -725,7 +1334,7 @@ func (s *Server) handleUndoTransaction(w http.ResponseWriter, r *http.Request) {
 			// Try to redirect based on source type
 			referer := r.Header.Get("Referer")
 			if referer != "" {
-				http.Redirect(w, r, referer+"?error="+err.Error(), http.StatusSeeOther)
+				http.Redirect(w, r, withMessage(referer, "error", err.Error()), http.StatusSeeOther)
 			} else {
 				http.Redirect(w, r, "/groups/"+groupIDStr+"?error="+err.Error(), http.StatusSeeOther)
 			}
@@ -739,7 +1348,7 @@ func (s *Server) handleUndoTransaction(w http.ResponseWriter, r *http.Request) {
 	if groupIDStr != "" {
 		referer := r.Header.Get("Referer")
 		if referer != "" {
-			http.Redirect(w, r, referer+"?success=Transaction undone successfully", http.StatusSeeOther)
+			http.Redirect(w, r, withMessage(referer, "success", "Transaction undone successfully"), http.StatusSeeOther)
 		} else {
 			http.Redirect(w, r, "/groups/"+groupIDStr+"?success=Transaction undone successfully", http.StatusSeeOther)
 		}
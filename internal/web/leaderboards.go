@@ -0,0 +1,147 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type leaderboardData struct {
+	basePageData
+	Leaderboard *core.Leaderboard
+	Seasons     []*core.Season
+	Balance     int
+	Now         time.Time
+	Error       string
+	Success     string
+}
+
+// leaderboardURL is where the season forms return to
+func leaderboardURL(groupID int64) string {
+	return "/groups/" + strconv.FormatInt(groupID, 10) + "/leaderboard?period=season"
+}
+
+// handleGroupLeaderboard ranks the group's members by cheese earned this week,
+// this month, of all time or in the running season
+func (s *Server) handleGroupLeaderboard(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+	locale := s.detectLocale(r)
+
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "groupID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	period, ok := core.ParseLeaderboardPeriod(r.URL.Query().Get("period"))
+	if !ok {
+		http.Error(w, "Invalid leaderboard period", http.StatusBadRequest)
+		return
+	}
+
+	board, err := s.service.BuildLeaderboard(userID, groupID, period, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	user, err := s.service.GetUserByID(userID)
+	if err != nil {
+		http.Error(w, "Failed to load user", http.StatusInternalServerError)
+		return
+	}
+	seasons, err := s.service.GetSeasons(groupID)
+	if err != nil {
+		http.Error(w, "Failed to load seasons", http.StatusInternalServerError)
+		return
+	}
+	balance, err := s.service.GetBalance(userID, groupID)
+	if err != nil {
+		http.Error(w, "Failed to load balance", http.StatusInternalServerError)
+		return
+	}
+
+	data := leaderboardData{
+		basePageData: s.buildBasePageData(user, locale),
+		Leaderboard:  board,
+		Seasons:      seasons,
+		Balance:      balance,
+		Now:          time.Now(),
+		Error:        r.URL.Query().Get("error"),
+		Success:      r.URL.Query().Get("success"),
+	}
+	data.basePageData.Group = board.Group
+
+	s.renderTemplate(w, "leaderboard.html", data)
+}
+
+// handleCreateSeason schedules a leaderboard season (owner only)
+func (s *Server) handleCreateSeason(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "groupID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	startsAt, endsAt, err := parsePauseDates(r, s.userLocation(r))
+	if err != nil {
+		http.Redirect(w, r, leaderboardURL(groupID)+"&error=Invalid date", http.StatusSeeOther)
+		return
+	}
+
+	_, err = s.service.CreateSeason(userID, groupID, r.FormValue("name"), startsAt, endsAt, r.FormValue("award_badges") == "on")
+	if err != nil {
+		http.Redirect(w, r, leaderboardURL(groupID)+"&error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, leaderboardURL(groupID)+"&success=Season created", http.StatusSeeOther)
+}
+
+// handleEndSeason ends a running season early (owner only)
+func (s *Server) handleEndSeason(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	seasonID, err := strconv.ParseInt(chi.URLParam(r, "seasonID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid season ID", http.StatusBadRequest)
+		return
+	}
+
+	season, err := s.service.EndSeason(userID, seasonID)
+	if err != nil {
+		http.Redirect(w, r, "/dashboard?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, leaderboardURL(season.GroupID)+"&success=Season ended", http.StatusSeeOther)
+}
+
+// handleDeleteSeason removes a season that has not started (owner only)
+func (s *Server) handleDeleteSeason(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	seasonID, err := strconv.ParseInt(chi.URLParam(r, "seasonID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid season ID", http.StatusBadRequest)
+		return
+	}
+
+	season, err := s.service.DeleteSeason(userID, seasonID)
+	if err != nil {
+		http.Redirect(w, r, "/dashboard?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, leaderboardURL(season.GroupID)+"&success=Season deleted", http.StatusSeeOther)
+}
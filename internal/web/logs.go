@@ -0,0 +1,109 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// logDateLayout is the format of the date inputs of the log filters
+const logDateLayout = "2006-01-02"
+
+// logQuery is the filter form of a task or purchase log page as submitted,
+// kept to fill the form back in and to link the next page
+type logQuery struct {
+	Member string // "" for yourself, "all" or a member ID (owner only)
+	Source string // Task or shop item ID
+	From   string
+	To     string // Inclusive day
+	Status string
+	Before int64
+}
+
+// parseLogQuery reads the log filters of a request, dates in the user's timezone
+func parseLogQuery(r *http.Request, userID int64, loc *time.Location) (logQuery, core.LogFilter, error) {
+	values := r.URL.Query()
+	q := logQuery{
+		Member: values.Get("member"),
+		Source: values.Get("source"),
+		From:   values.Get("from"),
+		To:     values.Get("to"),
+		Status: values.Get("status"),
+	}
+	filter := core.LogFilter{UserID: userID, Status: q.Status}
+
+	var err error
+	switch q.Member {
+	case "":
+	case "all":
+		filter.UserID = 0
+	default:
+		if filter.UserID, err = strconv.ParseInt(q.Member, 10, 64); err != nil {
+			return q, filter, fmt.Errorf("invalid member")
+		}
+	}
+	if q.Source != "" {
+		if filter.SourceID, err = strconv.ParseInt(q.Source, 10, 64); err != nil {
+			return q, filter, fmt.Errorf("invalid filter")
+		}
+	}
+	if q.From != "" {
+		if filter.Since, err = time.ParseInLocation(logDateLayout, q.From, loc); err != nil {
+			return q, filter, fmt.Errorf("invalid start date")
+		}
+	}
+	if q.To != "" {
+		if filter.Until, err = time.ParseInLocation(logDateLayout, q.To, loc); err != nil {
+			return q, filter, fmt.Errorf("invalid end date")
+		}
+		filter.Until = filter.Until.AddDate(0, 0, 1)
+	}
+	if b := values.Get("before"); b != "" {
+		if q.Before, err = strconv.ParseInt(b, 10, 64); err != nil {
+			return q, filter, fmt.Errorf("invalid page")
+		}
+		filter.Before = q.Before
+	}
+	return q, filter, nil
+}
+
+// URL links the log at path with the same filters, from the given cursor
+func (q logQuery) URL(path string, before int64) string {
+	values := url.Values{}
+	for key, v := range map[string]string{"member": q.Member, "source": q.Source, "from": q.From, "to": q.To, "status": q.Status} {
+		if v != "" {
+			values.Set(key, v)
+		}
+	}
+	if before != 0 {
+		values.Set("before", strconv.FormatInt(before, 10))
+	}
+	if len(values) == 0 {
+		return path
+	}
+	return path + "?" + values.Encode()
+}
+
+// IsFiltered reports whether any filter other than the page is set
+func (q logQuery) IsFiltered() bool {
+	return q.Member != "" || q.Source != "" || q.From != "" || q.To != "" || q.Status != ""
+}
+
+// withMessage sets a success or error message on a URL that may already
+// carry a query, such as a filtered log page
+func withMessage(target, key, msg string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	values := u.Query()
+	values.Del("success")
+	values.Del("error")
+	values.Set(key, msg)
+	u.RawQuery = values.Encode()
+	return u.String()
+}
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// parsePauseDates reads the starts_at and ends_at fields of a pause form.
+// An empty start means the pause starts right away.
+func parsePauseDates(r *http.Request, loc *time.Location) (time.Time, time.Time, error) {
+	var startsAt, endsAt time.Time
+	start, err := parseDateTimeField(r, "starts_at", loc)
+	if err != nil {
+		return startsAt, endsAt, err
+	}
+	end, err := parseDateTimeField(r, "ends_at", loc)
+	if err != nil {
+		return startsAt, endsAt, err
+	}
+	if start != nil {
+		startsAt = *start
+	}
+	if end != nil {
+		endsAt = *end
+	}
+	return startsAt, endsAt, nil
+}
+
+// handleCreateUserPause starts a personal rest period
+func (s *Server) handleCreateUserPause(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	startsAt, endsAt, err := parsePauseDates(r, s.userLocation(r))
+	if err != nil {
+		http.Redirect(w, r, "/dashboard?error=Invalid date", http.StatusSeeOther)
+		return
+	}
+
+	if _, err := s.service.CreateUserPause(userID, startsAt, endsAt, r.FormValue("reason")); err != nil {
+		http.Redirect(w, r, "/dashboard?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/dashboard?success=Rest mode scheduled", http.StatusSeeOther)
+}
+
+// handleCreateGroupPause puts the whole group to rest (owner only)
+func (s *Server) handleCreateGroupPause(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	groupIDStr := chi.URLParam(r, "groupID")
+	groupID, err := strconv.ParseInt(groupIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	startsAt, endsAt, err := parsePauseDates(r, s.userLocation(r))
+	if err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"?error=Invalid date", http.StatusSeeOther)
+		return
+	}
+
+	if _, err := s.service.CreateGroupPause(userID, groupID, startsAt, endsAt, r.FormValue("reason")); err != nil {
+		http.Redirect(w, r, "/groups/"+groupIDStr+"?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/groups/"+groupIDStr+"?success=Group rest mode scheduled", http.StatusSeeOther)
+}
+
+// handleEndPause ends an active pause now or cancels an upcoming one
+func (s *Server) handleEndPause(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	pauseID, err := strconv.ParseInt(chi.URLParam(r, "pauseID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid pause ID", http.StatusBadRequest)
+		return
+	}
+
+	pause, err := s.service.EndPause(userID, pauseID)
+	redirect := "/dashboard"
+	if pause != nil && pause.GroupID != nil {
+		redirect = "/groups/" + strconv.FormatInt(*pause.GroupID, 10)
+	}
+	if err != nil {
+		http.Redirect(w, r, redirect+"?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, redirect+"?success=Welcome back! Rest mode ended", http.StatusSeeOther)
+}
@@ -0,0 +1,107 @@
+package web
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+// quietDayRow is one weekday of the quiet hours form
+type quietDayRow struct {
+	Key      string // Short weekday name, used for the field name and label
+	Override string // The day's own window, "off", or "" to follow the everyday window
+}
+
+// quietDayRows lists the weekday overrides of the settings, Monday first
+func quietDayRows(settings *core.NotificationSettings) []quietDayRow {
+	var rows []quietDayRow
+	for _, day := range core.QuietWeekdays() {
+		row := quietDayRow{Key: core.WeekdayKey(day)}
+		if hours, ok := settings.QuietDays[day]; ok {
+			row.Override = hours.String()
+			if row.Override == "" {
+				row.Override = "off"
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// handleSetQuietHours saves the quiet hours form of the dashboard
+func (s *Server) handleSetQuietHours(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	var hours core.QuietHours
+	start, end := r.FormValue("quiet_start"), r.FormValue("quiet_end")
+	if start != "" || end != "" {
+		var err error
+		hours, err = core.ParseQuietHours(start + "-" + end)
+		if err != nil {
+			http.Redirect(w, r, "/dashboard?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+			return
+		}
+	}
+
+	days := make(map[time.Weekday]core.QuietHours)
+	for _, day := range core.QuietWeekdays() {
+		spec := strings.TrimSpace(r.FormValue("quiet_" + core.WeekdayKey(day)))
+		if spec == "" {
+			continue
+		}
+		dayHours, err := core.ParseQuietHours(spec)
+		if err != nil {
+			http.Redirect(w, r, "/dashboard?error="+url.QueryEscape(day.String()+": "+err.Error()), http.StatusSeeOther)
+			return
+		}
+		days[day] = dayHours
+	}
+
+	urgent := r.FormValue("urgent_during_quiet") == "on"
+	if err := s.service.UpdateQuietHours(userID, hours, days, urgent); err != nil {
+		http.Redirect(w, r, "/dashboard?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/dashboard?success=Quiet hours saved", http.StatusSeeOther)
+}
+
+// handleSetDigests saves the daily digest times and the report schedule of the dashboard
+func (s *Server) handleSetDigests(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	morning, err := core.ParseDigestTime(r.FormValue("morning_digest"))
+	if err != nil {
+		http.Redirect(w, r, "/dashboard?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	evening, err := core.ParseDigestTime(r.FormValue("evening_digest"))
+	if err != nil {
+		http.Redirect(w, r, "/dashboard?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+
+	if err := s.service.UpdateDigestTimes(userID, morning, evening); err != nil {
+		http.Redirect(w, r, "/dashboard?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	if err := s.service.UpdateReportSchedule(userID, r.FormValue("report_period")); err != nil {
+		http.Redirect(w, r, "/dashboard?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/dashboard?success=Digests saved", http.StatusSeeOther)
+}
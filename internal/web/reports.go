@@ -0,0 +1,135 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type reportData struct {
+	basePageData
+	Report  *core.Report
+	Members []*core.User
+	// MemberParam is the member filter of the page: "all" or a user ID
+	MemberParam string
+	Balance     int
+}
+
+// URL links to the report of another period, member or format
+func (d reportData) URL(period string, back int, member, format string) string {
+	query := url.Values{"period": {period}, "member": {member}}
+	if back > 0 {
+		query.Set("back", strconv.Itoa(back))
+	}
+	if format != "" {
+		query.Set("format", format)
+	}
+	return fmt.Sprintf("/groups/%d/report?%s", d.Report.Group.ID, query.Encode())
+}
+
+// PrevBack is the Back value of the period before the report's
+func (d reportData) PrevBack() int {
+	return d.Report.Back + 1
+}
+
+// NextBack is the Back value of the period after the report's
+func (d reportData) NextBack() int {
+	return d.Report.Back - 1
+}
+
+// handleGroupReport shows the weekly or monthly report of a group, for one
+// member (yourself by default) or everyone with ?member=all. With
+// ?format=md or ?format=html it is downloaded as Markdown or printable HTML.
+func (s *Server) handleGroupReport(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+	locale := s.detectLocale(r)
+
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "groupID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	period := core.ReportWeek
+	if p := r.URL.Query().Get("period"); p != "" {
+		var ok bool
+		if period, ok = core.ParseReportPeriod(p); !ok {
+			http.Error(w, "Invalid report period", http.StatusBadRequest)
+			return
+		}
+	}
+	back := 0
+	if b := r.URL.Query().Get("back"); b != "" {
+		back, err = strconv.Atoi(b)
+		if err != nil || back < 0 {
+			http.Error(w, "Invalid period", http.StatusBadRequest)
+			return
+		}
+	}
+	memberParam := r.URL.Query().Get("member")
+	memberID := userID
+	switch memberParam {
+	case "":
+		memberParam = strconv.FormatInt(userID, 10)
+	case "all":
+		memberID = 0
+	default:
+		memberID, err = strconv.ParseInt(memberParam, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid member", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := s.service.BuildReport(userID, groupID, memberID, period, back, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	filename := fmt.Sprintf("report-%s-%s", report.Period, report.Start.Format("2006-01-02"))
+	switch r.URL.Query().Get("format") {
+	case "md":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+".md\"")
+		w.Write([]byte(report.Markdown()))
+		return
+	case "html":
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+".html\"")
+		s.renderStandaloneTemplate(w, "report_print.html", report)
+		return
+	}
+
+	user, err := s.service.GetUserByID(userID)
+	if err != nil {
+		http.Error(w, "Failed to load user", http.StatusInternalServerError)
+		return
+	}
+	members, err := s.service.GetUsersByGroupID(groupID)
+	if err != nil {
+		http.Error(w, "Failed to load members", http.StatusInternalServerError)
+		return
+	}
+	balance, err := s.service.GetBalance(userID, groupID)
+	if err != nil {
+		http.Error(w, "Failed to load balance", http.StatusInternalServerError)
+		return
+	}
+
+	data := reportData{
+		basePageData: s.buildBasePageData(user, locale),
+		Report:       report,
+		Members:      members,
+		MemberParam:  memberParam,
+		Balance:      balance,
+	}
+	data.basePageData.Group = report.Group
+
+	s.renderTemplate(w, "report.html", data)
+}
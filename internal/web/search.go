@@ -0,0 +1,81 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"small-rpg-adhd-monolith/internal/core"
+)
+
+type searchData struct {
+	basePageData
+	Query    string
+	Searched bool
+	Results  []*core.SearchResult
+	Error    string
+}
+
+// byKind returns the results of one kind, in ranking order
+func (d searchData) byKind(kind string) []*core.SearchResult {
+	var results []*core.SearchResult
+	for _, r := range d.Results {
+		if r.Kind == kind {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// Tasks returns the matching tasks
+func (d searchData) Tasks() []*core.SearchResult { return d.byKind(core.SearchTask) }
+
+// Items returns the matching shop items
+func (d searchData) Items() []*core.SearchResult { return d.byKind(core.SearchItem) }
+
+// History returns the matching history entries
+func (d searchData) History() []*core.SearchResult { return d.byKind(core.SearchTransaction) }
+
+// handleSearch searches the current group (?group=ID) or all of the user's groups
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+	locale := s.detectLocale(r)
+
+	user, err := s.service.GetUserByID(userID)
+	if err != nil {
+		http.Error(w, "Failed to load user", http.StatusInternalServerError)
+		return
+	}
+
+	var groupID int64
+	if g := r.URL.Query().Get("group"); g != "" {
+		groupID, err = strconv.ParseInt(g, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid group ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	data := searchData{
+		basePageData: s.buildBasePageData(user, locale),
+		Query:        strings.TrimSpace(r.URL.Query().Get("q")),
+	}
+	if groupID != 0 {
+		group, err := s.service.GetGroupByID(groupID)
+		if err != nil {
+			http.Error(w, "Group not found", http.StatusNotFound)
+			return
+		}
+		data.basePageData.Group = group
+	}
+
+	if data.Query != "" {
+		data.Searched = true
+		data.Results, err = s.service.Search(userID, groupID, data.Query)
+		if err != nil {
+			data.Error = err.Error()
+		}
+	}
+
+	s.renderTemplate(w, "search.html", data)
+}
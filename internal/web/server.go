@@ -36,6 +36,8 @@ type Server struct {
 	templates     *template.Template
 	sessionSecret string
 	translator    *i18n.Translator
+	admins        map[int64]bool // Telegram IDs from ADMIN_TELEGRAM_IDS
+	packs         []*core.StarterPack
 }
 
 // NewServer creates a new Server instance
@@ -73,12 +75,19 @@ func NewServer(service *core.Service, sessionSecret string) (*Server, error) {
 		translator = i18n.NewFallback("en")
 	}
 
+	packs, err := core.LoadStarterPacks("packs")
+	if err != nil {
+		log.Printf("⚠️ Failed to load starter packs: %v", err)
+	}
+
 	return &Server{
 		service:       service,
 		sessionStore:  store,
 		templates:     nil, // Will be nil, parse on-demand instead
 		sessionSecret: sessionSecret,
 		translator:    translator,
+		admins:        parseAdminTelegramIDs(getEnv("ADMIN_TELEGRAM_IDS", "")),
+		packs:         packs,
 	}, nil
 }
 
@@ -105,17 +114,41 @@ func (s *Server) Router() http.Handler {
 	r.Get("/login", s.handleLoginPage)
 	r.Get("/auth", s.handleHashLogin) // Hash-based login from Telegram
 	r.Get("/locale", s.handleSetLocale)
+	r.Get("/sw.js", s.handleServiceWorker)
 
 	// Protected routes
 	r.Group(func(r chi.Router) {
 		r.Use(s.requireAuth)
 		r.Get("/dashboard", s.handleDashboard)
 		r.Get("/logout", s.handleLogout)
+		r.Post("/timezone", s.handleSetTimezone)
+		r.Post("/quiet-hours", s.handleSetQuietHours)
+		r.Post("/digests", s.handleSetDigests)
+		r.Post("/notification-channels", s.handleSetChannels)
+		r.Post("/push/subscriptions", s.handlePushSubscribe)
+		r.Post("/push/subscriptions/delete", s.handlePushUnsubscribe)
+		r.Get("/dates/preview", s.handleDatePreview)
+
+		// Admin routes
+		r.Group(func(r chi.Router) {
+			r.Use(s.requireAdmin)
+			r.Get("/admin/notifications", s.handleFailedNotifications)
+			r.Post("/admin/notifications/{notificationID}/retry", s.handleRetryNotification)
+			r.Post("/admin/notifications/{notificationID}/dismiss", s.handleDismissNotification)
+		})
 
 		// Group routes
 		r.Post("/groups/create", s.handleCreateGroup)
 		r.Post("/groups/join", s.handleJoinGroup)
 		r.Get("/groups/{groupID}", s.handleGroupView)
+		r.Post("/groups/{groupID}/archive", s.handleArchiveGroup)
+		r.Post("/groups/{groupID}/unarchive", s.handleUnarchiveGroup)
+		r.Get("/groups/{groupID}/delete", s.handleGroupDeletePage)
+		r.Post("/groups/{groupID}/delete", s.handleDeleteGroup)
+		r.Post("/groups/{groupID}/delete/cancel", s.handleCancelGroupDeletion)
+		r.Get("/ledgers/{exportID}", s.handleDownloadLedgerExport)
+		r.Get("/groups/{groupID}/definition", s.handleGroupDefinition)
+		r.Post("/groups/{groupID}/definition/import", s.handleImportGroupDefinition)
 
 		// Task routes
 		r.Post("/groups/{groupID}/tasks/create", s.handleCreateTask)
@@ -123,6 +156,10 @@ func (s *Server) Router() http.Handler {
 		r.Post("/tasks/{taskID}/update", s.handleUpdateTask)
 		r.Post("/tasks/{taskID}/delete", s.handleDeleteTask)
 		r.Post("/tasks/{taskID}/undo", s.handleUndoDeleteTask)
+		r.Post("/tasks/{taskID}/snooze", s.handleSnoozeTask)
+		r.Post("/tasks/{taskID}/show", s.handleShowTaskNow)
+		r.Post("/tasks/{taskID}/nag/dismiss", s.handleDismissNag)
+		r.Post("/tasks/{taskID}/reminders", s.handleTaskReminders)
 
 		// Shop routes
 		r.Post("/groups/{groupID}/shop/create", s.handleCreateShopItem)
@@ -130,14 +167,55 @@ func (s *Server) Router() http.Handler {
 		r.Post("/shop/{itemID}/update", s.handleUpdateShopItem)
 		r.Post("/shop/{itemID}/delete", s.handleDeleteShopItem)
 		r.Post("/shop/{itemID}/undo", s.handleUndoDeleteShopItem)
+		r.Post("/shop/{itemID}/wishlist", s.handleAddToWishlist)
+
+		// Wishlist routes
+		r.Post("/wishlist/{entryID}/remove", s.handleRemoveFromWishlist)
+		r.Post("/wishlist/{entryID}/reserve", s.handleReserveForWishlist)
+
+		// Currency routes
+		r.Post("/groups/{groupID}/currencies/create", s.handleCreateCurrency)
+		r.Post("/currencies/{currencyID}/update", s.handleUpdateCurrency)
+		r.Post("/groups/{groupID}/exchange/create", s.handleCreateExchangeRule)
+		r.Post("/exchange/{ruleID}/delete", s.handleDeleteExchangeRule)
+		r.Post("/exchange/{ruleID}", s.handleExchangeCurrency)
+
+		// Scheduled grant routes
+		r.Post("/groups/{groupID}/grants/create", s.handleCreateScheduledGrant)
+		r.Post("/grants/{grantID}/pause", s.handlePauseScheduledGrant)
+		r.Post("/grants/{grantID}/resume", s.handleResumeScheduledGrant)
+		r.Post("/grants/{grantID}/delete", s.handleDeleteScheduledGrant)
+
+		// Season routes
+		r.Post("/groups/{groupID}/seasons/create", s.handleCreateSeason)
+		r.Post("/seasons/{seasonID}/end", s.handleEndSeason)
+		r.Post("/seasons/{seasonID}/delete", s.handleDeleteSeason)
+
+		// Rest mode routes
+		r.Post("/pauses/create", s.handleCreateUserPause)
+		r.Post("/groups/{groupID}/pauses/create", s.handleCreateGroupPause)
+		r.Post("/pauses/{pauseID}/end", s.handleEndPause)
 
 		// History routes
 		r.Get("/groups/{groupID}/tasks/log", s.handleTaskLog)
 		r.Get("/groups/{groupID}/purchases/log", s.handlePurchaseLog)
+		r.Get("/groups/{groupID}/report", s.handleGroupReport)
+		r.Get("/groups/{groupID}/stats", s.handleGroupStats)
+		r.Get("/groups/{groupID}/stats.json", s.handleGroupStatsJSON)
+		r.Get("/groups/{groupID}/leaderboard", s.handleGroupLeaderboard)
+		r.Get("/groups/{groupID}/economy", s.handleGroupEconomy)
+		r.Post("/groups/{groupID}/economy/targets", s.handleSetEconomyTargets)
 		r.Post("/purchases/{purchaseID}/fulfill", s.handleMarkPurchaseFulfilled)
 
 		// Transaction undo route
 		r.Post("/transactions/{transactionID}/undo", s.handleUndoTransaction)
+
+		// Search route
+		r.Get("/search", s.handleSearch)
+
+		// Export routes
+		r.Get("/groups/{groupID}/export/{dataset}", s.handleExport)
+		r.Get("/export/{dataset}", s.handleExport)
 	})
 
 	return r
@@ -247,16 +325,7 @@ func (s *Server) renderTemplate(w http.ResponseWriter, name string, data interfa
 	layoutPath := filepath.Join("templates", "layout.html")
 	pagePath := filepath.Join("templates", name)
 
-	funcMap := template.FuncMap{
-		"t": func(locale, key string) string {
-			if s.translator == nil {
-				return key
-			}
-			return s.translator.T(locale, key)
-		},
-	}
-
-	tmpl, err := template.New(filepath.Base(layoutPath)).Funcs(funcMap).ParseFiles(layoutPath, pagePath)
+	tmpl, err := template.New(filepath.Base(layoutPath)).Funcs(s.templateFuncs()).ParseFiles(layoutPath, pagePath)
 	if err != nil {
 		log.Printf("ERROR parsing templates for %s: %v", name, err)
 		http.Error(w, fmt.Sprintf("Template parsing error: %v", err), http.StatusInternalServerError)
@@ -275,6 +344,38 @@ func (s *Server) renderTemplate(w http.ResponseWriter, name string, data interfa
 	log.Printf("Successfully rendered template: %s using layout.html", name)
 }
 
+// templateFuncs returns the functions available to all templates
+func (s *Server) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"t": func(locale, key string) string {
+			if s.translator == nil {
+				return key
+			}
+			return s.translator.T(locale, key)
+		},
+		"reminderOffsets": core.FormatReminderOffsets,
+		"snoozeHours": func() []int {
+			return core.SnoozePresetHours
+		},
+	}
+}
+
+// renderStandaloneTemplate renders a complete page without layout.html, such
+// as a printable report
+func (s *Server) renderStandaloneTemplate(w http.ResponseWriter, name string, data interface{}) {
+	pagePath := filepath.Join("templates", name)
+	tmpl, err := template.New(name).Funcs(s.templateFuncs()).ParseFiles(pagePath)
+	if err != nil {
+		log.Printf("ERROR parsing template %s: %v", name, err)
+		http.Error(w, fmt.Sprintf("Template parsing error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := tmpl.ExecuteTemplate(w, name, data); err != nil {
+		log.Printf("ERROR rendering template %s: %v", name, err)
+		http.Error(w, fmt.Sprintf("Template error: %v", err), http.StatusInternalServerError)
+	}
+}
+
 // handleHome redirects to dashboard if logged in, otherwise to login
 func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 	log.Printf("🏠 handleHome called: %s %s", r.Method, r.URL.Path)
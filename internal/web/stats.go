@@ -0,0 +1,138 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"small-rpg-adhd-monolith/internal/core"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type statsData struct {
+	basePageData
+	Stats   *core.GroupStats
+	Balance int
+}
+
+// statsSeries is one line of a chart: a task's units or a member's cheese
+type statsSeries struct {
+	Name   string `json:"name"`
+	Total  int    `json:"total"`
+	Points []int  `json:"points"`
+}
+
+// statsTask is a task's completions over the whole range
+type statsTask struct {
+	Title       string `json:"title"`
+	Completions int    `json:"completions"`
+	Quantity    int    `json:"quantity"`
+	Earned      int    `json:"earned"`
+}
+
+// statsResponse is what the stats charts read from /groups/{id}/stats.json.
+// Every series is aligned with Labels, one point per day or week.
+type statsResponse struct {
+	Interval    string        `json:"interval"`
+	Labels      []string      `json:"labels"`
+	Earned      []int         `json:"earned"`
+	Spent       []int         `json:"spent"`
+	Completions []int         `json:"completions"`
+	Tasks       []statsTask   `json:"tasks"`
+	Quantities  []statsSeries `json:"quantities"`
+	Members     []statsSeries `json:"members"`
+}
+
+// loadGroupStats builds the stats of the group in the URL for the signed in
+// user, writing the error response itself when that fails
+func (s *Server) loadGroupStats(w http.ResponseWriter, r *http.Request) (*core.GroupStats, bool) {
+	userID, _ := s.getUserID(r)
+
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "groupID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return nil, false
+	}
+	interval, ok := core.ParseStatsInterval(r.URL.Query().Get("interval"))
+	if !ok {
+		http.Error(w, "Invalid stats interval", http.StatusBadRequest)
+		return nil, false
+	}
+
+	stats, err := s.service.BuildGroupStats(userID, groupID, interval, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return nil, false
+	}
+	return stats, true
+}
+
+// handleGroupStats shows the charts of a group's history by day or week
+func (s *Server) handleGroupStats(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+	locale := s.detectLocale(r)
+
+	stats, ok := s.loadGroupStats(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := s.service.GetUserByID(userID)
+	if err != nil {
+		http.Error(w, "Failed to load user", http.StatusInternalServerError)
+		return
+	}
+	balance, err := s.service.GetBalance(userID, stats.Group.ID)
+	if err != nil {
+		http.Error(w, "Failed to load balance", http.StatusInternalServerError)
+		return
+	}
+
+	data := statsData{
+		basePageData: s.buildBasePageData(user, locale),
+		Stats:        stats,
+		Balance:      balance,
+	}
+	data.basePageData.Group = stats.Group
+
+	s.renderTemplate(w, "stats.html", data)
+}
+
+// handleGroupStatsJSON serves the chart data of the stats page
+func (s *Server) handleGroupStatsJSON(w http.ResponseWriter, r *http.Request) {
+	stats, ok := s.loadGroupStats(w, r)
+	if !ok {
+		return
+	}
+
+	resp := statsResponse{
+		Interval:    stats.Interval,
+		Labels:      []string{},
+		Earned:      []int{},
+		Spent:       []int{},
+		Completions: []int{},
+		Tasks:       []statsTask{},
+		Quantities:  []statsSeries{},
+		Members:     []statsSeries{},
+	}
+	for _, b := range stats.Buckets {
+		resp.Labels = append(resp.Labels, b.Start.Format("Jan 2"))
+		resp.Earned = append(resp.Earned, b.Earned)
+		resp.Spent = append(resp.Spent, b.Spent)
+		resp.Completions = append(resp.Completions, b.Completions)
+	}
+	for _, t := range stats.Tasks {
+		resp.Tasks = append(resp.Tasks, statsTask{Title: t.Title, Completions: t.Completions, Quantity: t.Quantity, Earned: t.Earned})
+	}
+	for _, q := range stats.Quantities {
+		resp.Quantities = append(resp.Quantities, statsSeries{Name: q.Title, Total: q.Total(), Points: q.Points})
+	}
+	for _, m := range stats.Members {
+		resp.Members = append(resp.Members, statsSeries{Name: m.Username, Total: m.Earned, Points: m.Points})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
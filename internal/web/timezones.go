@@ -0,0 +1,42 @@
+package web
+
+import (
+	"net/http"
+	"time"
+)
+
+// userLocation returns the timezone of the signed-in user, falling back to
+// server time
+func (s *Server) userLocation(r *http.Request) *time.Location {
+	userID, ok := s.getUserID(r)
+	if !ok {
+		return time.Local
+	}
+	user, err := s.service.GetUserByID(userID)
+	if err != nil {
+		return time.Local
+	}
+	return user.Location()
+}
+
+// handleSetTimezone saves the timezone picked on the dashboard
+func (s *Server) handleSetTimezone(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	timezone, err := s.service.SetUserTimezone(userID, r.FormValue("timezone"))
+	if err != nil {
+		http.Redirect(w, r, "/dashboard?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+	if timezone == "" {
+		http.Redirect(w, r, "/dashboard?success=Timezone cleared, using server time", http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/dashboard?success=Timezone set to "+timezone, http.StatusSeeOther)
+}
@@ -0,0 +1,98 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// wishlistRedirect sends the user back to the group page or, when requested, the dashboard
+func wishlistRedirect(w http.ResponseWriter, r *http.Request, groupID int64, query string) {
+	target := "/groups/" + strconv.FormatInt(groupID, 10)
+	if r.FormValue("return") == "dashboard" {
+		target = "/dashboard"
+	}
+	http.Redirect(w, r, target+"?"+query, http.StatusSeeOther)
+}
+
+// handleAddToWishlist pins a shop item to the user's wishlist
+func (s *Server) handleAddToWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	itemIDStr := chi.URLParam(r, "itemID")
+	itemID, err := strconv.ParseInt(itemIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	item, err := s.service.GetShopItemByID(itemID)
+	if err != nil {
+		http.Error(w, "Item not found", http.StatusNotFound)
+		return
+	}
+
+	if _, err := s.service.AddToWishlist(userID, itemID); err != nil {
+		wishlistRedirect(w, r, item.GroupID, "error="+err.Error())
+		return
+	}
+
+	wishlistRedirect(w, r, item.GroupID, "success=Added to wishlist")
+}
+
+// handleRemoveFromWishlist unpins a wishlist entry
+func (s *Server) handleRemoveFromWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	entryIDStr := chi.URLParam(r, "entryID")
+	entryID, err := strconv.ParseInt(entryIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid wishlist ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := s.service.RemoveFromWishlist(userID, entryID)
+	if err != nil {
+		http.Redirect(w, r, "/dashboard?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	wishlistRedirect(w, r, entry.GroupID, "success=Removed from wishlist")
+}
+
+// handleReserveForWishlist locks cheese toward a wishlist entry
+func (s *Server) handleReserveForWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, _ := s.getUserID(r)
+
+	entryIDStr := chi.URLParam(r, "entryID")
+	entryID, err := strconv.ParseInt(entryIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid wishlist ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	amount, err := strconv.Atoi(r.FormValue("amount"))
+	if err != nil {
+		http.Redirect(w, r, "/dashboard?error=Invalid amount", http.StatusSeeOther)
+		return
+	}
+
+	entry, err := s.service.ReserveForWishlist(userID, entryID, amount)
+	if err != nil {
+		http.Redirect(w, r, "/dashboard?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+
+	wishlistRedirect(w, r, entry.GroupID, "success=Reservation updated")
+}
@@ -0,0 +1,29 @@
+// Service worker for Web Push reminders
+self.addEventListener('push', (event) => {
+    let data = {};
+    try {
+        data = event.data ? event.data.json() : {};
+    } catch (err) {
+        data = {title: 'Small RPG', body: event.data.text()};
+    }
+    event.waitUntil(self.registration.showNotification(data.title || 'Small RPG', {
+        body: data.body || '',
+        tag: data.tag,
+        renotify: !!data.tag,
+        icon: '/static/favicon_io/android-chrome-192x192.png',
+        badge: '/static/favicon_io/favicon-32x32.png',
+        data: {url: data.url || '/dashboard'},
+    }));
+});
+
+// Open the page the reminder is about, reusing an open tab when there is one
+self.addEventListener('notificationclick', (event) => {
+    event.notification.close();
+    const url = new URL(event.notification.data.url, self.location.origin).href;
+    event.waitUntil(self.clients.matchAll({type: 'window', includeUncontrolled: true}).then((clients) => {
+        for (const client of clients) {
+            if (client.url === url && 'focus' in client) return client.focus();
+        }
+        return self.clients.openWindow(url);
+    }));
+});